@@ -0,0 +1,83 @@
+package calldata
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/zeuslawyer/hextool/encdec"
+	"github.com/zeuslawyer/hextool/selector"
+)
+
+// Arg is a single named argument decoded from calldata.
+type Arg struct {
+	Name  string
+	Type  abi.Type
+	Value any
+}
+
+// Call is a function call decoded from calldata: the method it invokes and its arguments.
+type Call struct {
+	Method *abi.Method
+	Args   []Arg
+}
+
+// Formats the call as its signature followed by one `name: value` line per argument, eg:
+//
+//	transfer(address,uint256)
+//	  to: 0x208AA722Aca42399eaC5192EE778e4D42f4E5De3
+//	  amount: 1000
+func (c *Call) String() string {
+	var sb strings.Builder
+	sb.WriteString(c.Method.Sig)
+	for _, arg := range c.Args {
+		fmt.Fprintf(&sb, "\n  %s: %s", arg.Name, encdec.FormatValue(arg.Type, arg.Value))
+	}
+	return sb.String()
+}
+
+// Decodes the hex string `data` into a Call. The first 4 bytes of `data` are the
+// function selector, which is looked up in the ABI from the provided file path or URL,
+// and the remaining bytes are unpacked as the method's inputs.
+// If both a path and URL are provided it will default to using the file path.
+func Decode(data string, abiPath string, abiUrl string) (*Call, error) {
+	parsedAbi, err := selector.LoadABI(selector.NewAbiSource(abiPath, abiUrl))
+	if err != nil {
+		return nil, err
+	}
+
+	dataBytes, err := hexutil.Decode(data)
+	if err != nil {
+		return nil, fmt.Errorf("%w: calldata %q: %v", encdec.ErrInvalidHex, data, err)
+	}
+
+	return DecodeWithAbi(dataBytes, parsedAbi)
+}
+
+// Decodes calldata bytes into a Call using an already parsed ABI.
+func DecodeWithAbi(data []byte, parsedAbi *abi.ABI) (*Call, error) {
+	if len(data) < 4 {
+		return nil, fmt.Errorf("%w: calldata must be at least 4 bytes, got %d", encdec.ErrInvalidHex, len(data))
+	}
+
+	method, err := parsedAbi.MethodById(data[:4])
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", selector.ErrSelectorNotFound, err)
+	}
+
+	values, err := method.Inputs.Unpack(data[4:])
+	if err != nil {
+		return nil, fmt.Errorf("%w: unpacking arguments of %s: %v", encdec.ErrTypeMismatch, method.Sig, err)
+	}
+
+	call := &Call{Method: method, Args: make([]Arg, len(values))}
+	for idx, input := range method.Inputs {
+		name := input.Name
+		if name == "" {
+			name = fmt.Sprintf("arg%d", idx)
+		}
+		call.Args[idx] = Arg{Name: name, Type: input.Type, Value: values[idx]}
+	}
+	return call, nil
+}
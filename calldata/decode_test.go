@@ -0,0 +1,87 @@
+package calldata
+
+import (
+	"errors"
+	"path"
+	"testing"
+
+	"github.com/zeuslawyer/hextool/encdec"
+	"github.com/zeuslawyer/hextool/selector"
+)
+
+func TestDecode(t *testing.T) {
+	routerAbi := path.Join("testdata", "router.abi.json")
+
+	tests := []struct {
+		name    string
+		data    string
+		path    string
+		want    string
+		wantErr error
+	}{
+		{
+			name: "transfer",
+			data: "0xa9059cbb000000000000000000000000208aa722aca42399eac5192ee778e4d42f4e5de300000000000000000000000000000000000000000000000000000000000003e8",
+			path: routerAbi,
+			want: "transfer(address,uint256)\n  to: 0x208AA722Aca42399eaC5192EE778e4D42f4E5De3\n  amount: 1000",
+		},
+		{
+			name: "struct argument",
+			data: "0xc04b8d59000000000000000000000000000000000000000000000000000000000000002000000000000000000000000000000000000000000000000000000000000000a0000000000000000000000000208aa722aca42399eac5192ee778e4d42f4e5de3000000000000000000000000000000000000000000000000000000006553f10000000000000000000000000000000000000000000000000000000000000003e800000000000000000000000000000000000000000000000000000000000003de00000000000000000000000000000000000000000000000000000000000000021234000000000000000000000000000000000000000000000000000000000000",
+			path: routerAbi,
+			want: "exactInput((bytes,address,uint256,uint256,uint256))\n  params: (path: 0x1234, recipient: 0x208AA722Aca42399eaC5192EE778e4D42f4E5De3, deadline: 1700000000, amountIn: 1000, amountOutMinimum: 990)",
+		},
+		{
+			name: "no arguments",
+			data: "0x8456cb59",
+			path: routerAbi,
+			want: "pause()",
+		},
+		{
+			name: "unnamed arguments",
+			data: "0x558a7297000000000000000000000000208aa722aca42399eac5192ee778e4d42f4e5de30000000000000000000000000000000000000000000000000000000000000001",
+			path: routerAbi,
+			want: "setOperator(address,bool)\n  arg0: 0x208AA722Aca42399eaC5192EE778e4D42f4E5De3\n  arg1: true",
+		},
+		{
+			name:    "unknown selector",
+			data:    "0xdeadbeef",
+			path:    routerAbi,
+			wantErr: selector.ErrSelectorNotFound,
+		},
+		{
+			name:    "shorter than a selector",
+			data:    "0xa905",
+			path:    routerAbi,
+			wantErr: encdec.ErrInvalidHex,
+		},
+		{
+			name:    "truncated arguments",
+			data:    "0xa9059cbb000000000000000000000000208aa722aca42399eac5192ee778e4d42f4e5de3",
+			path:    routerAbi,
+			wantErr: encdec.ErrTypeMismatch,
+		},
+		{
+			name:    "no abi",
+			data:    "0xa9059cbb",
+			wantErr: selector.ErrNoAbiSource,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := Decode(tc.data, tc.path, "")
+			if tc.wantErr != nil {
+				if !errors.Is(err, tc.wantErr) {
+					t.Errorf("Decode() error = %v, want %v", err, tc.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Decode() unexpected error: %v", err)
+			}
+			if got.String() != tc.want {
+				t.Errorf("Decode() = %q, want %q", got.String(), tc.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,128 @@
+package calldata
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/zeuslawyer/hextool/encdec"
+	"github.com/zeuslawyer/hextool/selector"
+)
+
+// ErrAmbiguousMethod is returned when a method name matches several overloaded
+// functions in an ABI, and the input values fit more than one of them.
+var ErrAmbiguousMethod = errors.New("ambiguous method")
+
+// Encodes calldata for the function signature `funcSig`, eg: "transfer(address,uint256)",
+// or its Solidity declaration, eg: "function transfer(address to, uint amount) external",
+// and the comma-separated input values, eg: "0x208AA722Aca42399eaC5192EE778e4D42f4E5De3, 1000".
+// The values follow the same format as encdec.Encode. The result is the function
+// selector followed by the abi-encoded values, as a 0x-prefixed hex string.
+func Encode(funcSig string, values string) (string, error) {
+	decl, err := selector.ParseSig(funcSig)
+	if err != nil {
+		return "", err
+	}
+	if kind, _ := decl.Kind(); kind != selector.KindFunction {
+		return "", fmt.Errorf("%w: %q is not a function", selector.ErrInvalidSignature, funcSig)
+	}
+
+	args := make(abi.Arguments, len(decl.Inputs))
+	for idx, input := range decl.Inputs {
+		abiType, err := abi.NewType(input.Type, "", input.Components)
+		if err != nil {
+			return "", fmt.Errorf("%w: Unsupported type %q: %v", encdec.ErrTypeMismatch, input.Type, err)
+		}
+		name := input.Name
+		if name == "" {
+			name = fmt.Sprintf("arg%d", idx)
+		}
+		args[idx] = abi.Argument{Name: name, Type: abiType}
+	}
+
+	// The selector is computed from the canonical signature, so that eg: "uint" hashes as "uint256".
+	funcSelector, err := selector.FromSig(decl.Signature())
+	if err != nil {
+		return "", err
+	}
+
+	encoded, err := encdec.EncodeArguments(values, args)
+	if err != nil {
+		return "", err
+	}
+	return funcSelector + hexutil.Encode(encoded)[2:], nil
+}
+
+// Encodes calldata for the method `method` in the ABI from the provided file path or URL.
+// `method` is either a function name, eg: "transfer", or a full signature, eg:
+// "safeTransferFrom(address,address,uint256)", which picks one of several overloaded functions.
+// If a name matches overloaded functions, the one that the values can be encoded for is used.
+func EncodeFromAbi(method string, values string, abiPath string, abiUrl string) (string, error) {
+	parsedAbi, err := selector.LoadABI(selector.NewAbiSource(abiPath, abiUrl))
+	if err != nil {
+		return "", err
+	}
+
+	m, encoded, err := encodeMethod(parsedAbi, method, values)
+	if err != nil {
+		return "", err
+	}
+	return hexutil.Encode(append(m.ID, encoded...)), nil
+}
+
+// Finds the method in `parsedAbi` and encodes `values` as its inputs.
+func encodeMethod(parsedAbi *abi.ABI, method string, values string) (*abi.Method, []byte, error) {
+	method = strings.ReplaceAll(method, " ", "")
+	if method == "" {
+		return nil, nil, fmt.Errorf("%w: a method name or signature is required", selector.ErrInvalidSignature)
+	}
+
+	candidates := []abi.Method{}
+	for _, m := range parsedAbi.Methods {
+		if m.RawName == method || m.Sig == method {
+			candidates = append(candidates, m)
+		}
+	}
+	if len(candidates) == 0 {
+		return nil, nil, fmt.Errorf("%w: no method %q in the ABI", selector.ErrSelectorNotFound, method)
+	}
+	if len(candidates) == 1 {
+		encoded, err := encdec.EncodeArguments(values, candidates[0].Inputs)
+		if err != nil {
+			return nil, nil, err
+		}
+		return &candidates[0], encoded, nil
+	}
+
+	// Overloaded functions: keep the ones the values can be encoded for.
+	sort.Slice(candidates, func(i, j int) bool { return candidates[i].Sig < candidates[j].Sig })
+	var (
+		matched  []string
+		found    *abi.Method
+		encoded  []byte
+		firstErr error
+	)
+	for idx := range candidates {
+		b, err := encdec.EncodeArguments(values, candidates[idx].Inputs)
+		if err != nil {
+			if firstErr == nil {
+				firstErr = err
+			}
+			continue
+		}
+		matched = append(matched, candidates[idx].Sig)
+		found, encoded = &candidates[idx], b
+	}
+
+	switch len(matched) {
+	case 0:
+		return nil, nil, fmt.Errorf("values do not fit any overload of %q: %w", method, firstErr)
+	case 1:
+		return found, encoded, nil
+	default:
+		return nil, nil, fmt.Errorf("%w: %q matches %s. Pass the full signature instead", ErrAmbiguousMethod, method, strings.Join(matched, ", "))
+	}
+}
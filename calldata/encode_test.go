@@ -0,0 +1,176 @@
+package calldata
+
+import (
+	"errors"
+	"path"
+	"testing"
+
+	"github.com/zeuslawyer/hextool/encdec"
+	"github.com/zeuslawyer/hextool/selector"
+)
+
+const (
+	addr       = "0x208AA722Aca42399eaC5192EE778e4D42f4E5De3"
+	addrWord   = "000000000000000000000000208aa722aca42399eac5192ee778e4d42f4e5de3"
+	amountWord = "00000000000000000000000000000000000000000000000000000000000003e8"
+)
+
+func TestEncode(t *testing.T) {
+	tests := []struct {
+		name    string
+		sig     string
+		values  string
+		want    string
+		wantErr error
+	}{
+		{
+			name:   "transfer",
+			sig:    "transfer(address,uint256)",
+			values: addr + ", 1000",
+			want:   "0xa9059cbb" + addrWord + amountWord,
+		},
+		{
+			name:   "non-canonical types and spaces",
+			sig:    "transfer(address to, uint amount)",
+			values: addr + ", 1000",
+			want:   "0xa9059cbb" + addrWord + amountWord,
+		},
+		{
+			name:   "solidity declaration",
+			sig:    "function transfer(address _to, uint _amount) external returns (bool)",
+			values: addr + ", 1000",
+			want:   "0xa9059cbb" + addrWord + amountWord,
+		},
+		{
+			name:    "event declaration",
+			sig:     "event Transfer(address indexed from, address indexed to, uint amount)",
+			values:  addr + ", " + addr + ", 1000",
+			wantErr: selector.ErrInvalidSignature,
+		},
+		{
+			name:   "no arguments",
+			sig:    "pause()",
+			values: "",
+			want:   "0x8456cb59",
+		},
+		{
+			name:   "struct argument",
+			sig:    "exactInput((bytes,address,uint256,uint256,uint256))",
+			values: "(0x1234, " + addr + ", 1700000000, 1000, 990)",
+			want:   "0xc04b8d59000000000000000000000000000000000000000000000000000000000000002000000000000000000000000000000000000000000000000000000000000000a0000000000000000000000000208aa722aca42399eac5192ee778e4d42f4e5de3000000000000000000000000000000000000000000000000000000006553f10000000000000000000000000000000000000000000000000000000000000003e800000000000000000000000000000000000000000000000000000000000003de00000000000000000000000000000000000000000000000000000000000000021234000000000000000000000000000000000000000000000000000000000000",
+		},
+		{
+			name:    "not a signature",
+			sig:     "transfer",
+			values:  addr,
+			wantErr: selector.ErrInvalidSignature,
+		},
+		{
+			name:    "values do not match types",
+			sig:     "transfer(address,uint256)",
+			values:  addr,
+			wantErr: encdec.ErrTypeMismatch,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := Encode(tc.sig, tc.values)
+			if tc.wantErr != nil {
+				if !errors.Is(err, tc.wantErr) {
+					t.Errorf("Encode() error = %v, want %v", err, tc.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Encode() unexpected error: %v", err)
+			}
+			if got != tc.want {
+				t.Errorf("Encode() = %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestEncodeFromAbi(t *testing.T) {
+	routerAbi := path.Join("testdata", "router.abi.json")
+
+	tests := []struct {
+		name    string
+		method  string
+		values  string
+		want    string
+		wantErr error
+	}{
+		{
+			name:   "by name",
+			method: "transfer",
+			values: addr + ", 1000",
+			want:   "0xa9059cbb" + addrWord + amountWord,
+		},
+		{
+			name:   "by signature",
+			method: "transfer(address,uint256)",
+			values: addr + ", 1000",
+			want:   "0xa9059cbb" + addrWord + amountWord,
+		},
+		{
+			name:   "overload picked by signature",
+			method: "safeTransferFrom(address,address,uint256)",
+			values: addr + ", " + addr + ", 1000",
+			want:   "0x42842e0e" + addrWord + addrWord + amountWord,
+		},
+		{
+			name:   "overload picked by number of values",
+			method: "safeTransferFrom",
+			values: addr + ", " + addr + ", 1000",
+			want:   "0x42842e0e" + addrWord + addrWord + amountWord,
+		},
+		{
+			name:   "overload picked by value types - address",
+			method: "mint",
+			values: addr,
+			want:   "0x6a627842" + addrWord,
+		},
+		{
+			name:   "overload picked by value types - uint8",
+			method: "mint",
+			values: "5",
+			want:   "0x6ecd23060000000000000000000000000000000000000000000000000000000000000005",
+		},
+		{
+			name:    "unknown method",
+			method:  "withdraw",
+			values:  "5",
+			wantErr: selector.ErrSelectorNotFound,
+		},
+		{
+			name:    "values fit several overloads",
+			method:  "burn",
+			values:  "5",
+			wantErr: ErrAmbiguousMethod,
+		},
+		{
+			name:    "values fit no overload",
+			method:  "safeTransferFrom",
+			values:  addr,
+			wantErr: encdec.ErrTypeMismatch,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := EncodeFromAbi(tc.method, tc.values, routerAbi, "")
+			if tc.wantErr != nil {
+				if !errors.Is(err, tc.wantErr) {
+					t.Errorf("EncodeFromAbi() error = %v, want %v", err, tc.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("EncodeFromAbi() unexpected error: %v", err)
+			}
+			if got != tc.want {
+				t.Errorf("EncodeFromAbi() = %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,12 @@
+package eip712
+
+import "errors"
+
+// Sentinel errors returned by this package. Callers can match them with errors.Is.
+// Values that cannot be encoded as their atomic type wrap encdec.ErrTypeMismatch or
+// encdec.ErrInvalidHex.
+var (
+	// ErrInvalidTypedData is returned when the typed data is malformed, eg: a struct type
+	// is undefined or the message is missing a field of its type.
+	ErrInvalidTypedData = errors.New("invalid typed data")
+)
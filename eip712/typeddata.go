@@ -0,0 +1,326 @@
+// Package eip712 hashes typed structured data as EIP-712 defines it, the way
+// eth_signTypedData_v4 does, eg: for permits, Safe transactions and orders.
+package eip712
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/zeuslawyer/hextool/encdec"
+	"github.com/zeuslawyer/hextool/internal/typescan"
+)
+
+// The name of the struct type of the domain.
+const domainType = "EIP712Domain"
+
+// The fields EIP712Domain may have, in the order the type defines them when the typed data
+// does not. See https://eips.ethereum.org/EIPS/eip-712#definition-of-domainseparator
+var domainFields = []Field{
+	{Name: "name", Type: "string"},
+	{Name: "version", Type: "string"},
+	{Name: "chainId", Type: "uint256"},
+	{Name: "verifyingContract", Type: "address"},
+	{Name: "salt", Type: "bytes32"},
+}
+
+// A member of a struct type, eg: {"name": "wallet", "type": "address"}.
+type Field struct {
+	Name string `json:"name"`
+	Type string `json:"type"`
+}
+
+// The struct types of typed data, keyed by name.
+type Types map[string][]Field
+
+// Typed data, as the JSON that eth_signTypedData_v4 takes.
+type TypedData struct {
+	Types       Types          `json:"types"`
+	PrimaryType string         `json:"primaryType"`
+	Domain      map[string]any `json:"domain"`
+	Message     map[string]any `json:"message"`
+}
+
+// The hashes that eth_signTypedData_v4 computes to sign typed data.
+type Hashes struct {
+	DomainSeparator common.Hash // hashStruct of the domain.
+	EncodeType      string      // encodeType of the primary type.
+	TypeHash        common.Hash // the keccak256 of EncodeType.
+	StructHash      common.Hash // hashStruct of the message.
+	// keccak256("\x19\x01" ‖ DomainSeparator ‖ StructHash), the hash that is signed.
+	Digest common.Hash
+}
+
+// Formats the hashes one per line, in the order they are computed.
+func (h *Hashes) String() string {
+	return fmt.Sprintf(
+		"domain separator: %s\nencodeType:       %s\ntypeHash:         %s\nhashStruct:       %s\ndigest:           %s",
+		h.DomainSeparator.Hex(), h.EncodeType, h.TypeHash.Hex(), h.StructHash.Hex(), h.Digest.Hex(),
+	)
+}
+
+// Reads the typed data in the JSON file at `path`.
+func LoadTypedData(path string) (*TypedData, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return ParseTypedData(b)
+}
+
+// Parses typed data from its JSON form. Numbers are kept as written, so that values larger
+// than a float64 can hold are not rounded.
+func ParseTypedData(data []byte) (*TypedData, error) {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	var typedData TypedData
+	if err := decoder.Decode(&typedData); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidTypedData, err)
+	}
+	if typedData.PrimaryType == "" {
+		return nil, fmt.Errorf("%w: primaryType cannot be empty", ErrInvalidTypedData)
+	}
+	if _, ok := typedData.Types[typedData.PrimaryType]; !ok && typedData.PrimaryType != domainType {
+		return nil, fmt.Errorf("%w: primaryType %s is not defined in types", ErrInvalidTypedData, typedData.PrimaryType)
+	}
+	return &typedData, nil
+}
+
+// Computes the domain separator, the hashStruct of the message and the digest to sign. When
+// the types do not define EIP712Domain, its fields are the ones the domain has. When the
+// primary type is EIP712Domain, the digest only covers the domain, as in eth_signTypedData_v4.
+func (td *TypedData) Hash() (*Hashes, error) {
+	types := td.Types
+	if _, ok := types[domainType]; !ok {
+		types = Types{}
+		for name, fields := range td.Types {
+			types[name] = fields
+		}
+		types[domainType] = []Field{}
+		for _, field := range domainFields {
+			if _, ok := td.Domain[field.Name]; ok {
+				types[domainType] = append(types[domainType], field)
+			}
+		}
+	}
+
+	var hashes Hashes
+	var err error
+	if hashes.DomainSeparator, err = types.HashStruct(domainType, td.Domain); err != nil {
+		return nil, err
+	}
+	if hashes.EncodeType, err = types.EncodeType(td.PrimaryType); err != nil {
+		return nil, err
+	}
+	hashes.TypeHash = crypto.Keccak256Hash([]byte(hashes.EncodeType))
+
+	toSign := []byte{0x19, 0x01}
+	toSign = append(toSign, hashes.DomainSeparator[:]...)
+	if td.PrimaryType != domainType {
+		if hashes.StructHash, err = types.HashStruct(td.PrimaryType, td.Message); err != nil {
+			return nil, err
+		}
+		toSign = append(toSign, hashes.StructHash[:]...)
+	}
+	hashes.Digest = crypto.Keccak256Hash(toSign)
+	return &hashes, nil
+}
+
+// Returns encodeType of the struct type `name`: its definition, followed by the definitions of
+// the struct types it references, directly or not, sorted by name, eg:
+// "Mail(Person from,Person to,string contents)Person(string name,address wallet)".
+func (t Types) EncodeType(name string) (string, error) {
+	deps := map[string]bool{}
+	if err := t.collectDeps(name, deps); err != nil {
+		return "", err
+	}
+	delete(deps, name)
+	sorted := []string{name}
+	for dep := range deps {
+		sorted = append(sorted, dep)
+	}
+	sort.Strings(sorted[1:])
+
+	var sb strings.Builder
+	for _, structName := range sorted {
+		params := make([]string, len(t[structName]))
+		for idx, field := range t[structName] {
+			params[idx] = field.Type + " " + field.Name
+		}
+		fmt.Fprintf(&sb, "%s(%s)", structName, strings.Join(params, ","))
+	}
+	return sb.String(), nil
+}
+
+// Adds `name` and the struct types its fields reference to `deps`, and checks that every other
+// field type is an atomic or dynamic type encdec can parse.
+func (t Types) collectDeps(name string, deps map[string]bool) error {
+	if deps[name] {
+		return nil
+	}
+	fields, ok := t[name]
+	if !ok {
+		return fmt.Errorf("%w: struct type %s is not defined in types", ErrInvalidTypedData, name)
+	}
+	deps[name] = true
+
+	for _, field := range fields {
+		baseType := field.Type
+		for elem, _, ok := typescan.SplitArrayType(baseType); ok; elem, _, ok = typescan.SplitArrayType(baseType) {
+			baseType = elem
+		}
+		if _, ok := t[baseType]; ok {
+			if err := t.collectDeps(baseType, deps); err != nil {
+				return err
+			}
+		} else if _, err := atomicType(baseType); err != nil {
+			return fmt.Errorf("%s.%s: %w", name, field.Name, err)
+		}
+	}
+	return nil
+}
+
+// Returns hashStruct of `data`, a value of the struct type `name`: the keccak256 of its typeHash
+// followed by the encoding of each of its fields.
+func (t Types) HashStruct(name string, data map[string]any) (common.Hash, error) {
+	return t.hashStruct(name, data, name)
+}
+
+// `path` names the value being hashed, eg: "Mail.to", for use in error messages.
+func (t Types) hashStruct(name string, data map[string]any, path string) (common.Hash, error) {
+	encodeType, err := t.EncodeType(name)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	encoded, err := t.encodeData(name, data, path)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	return crypto.Keccak256Hash(crypto.Keccak256([]byte(encodeType)), encoded), nil
+}
+
+// Returns encodeData of `data`: one 32-byte word per field of the struct type `name`.
+func (t Types) encodeData(name string, data map[string]any, path string) ([]byte, error) {
+	fields := t[name]
+	if len(data) > len(fields) {
+		for key := range data {
+			if !hasField(fields, key) {
+				return nil, fmt.Errorf("%w: %s has field %q, which is not in type %s", ErrInvalidTypedData, path, key, name)
+			}
+		}
+	}
+
+	var encoded []byte
+	for _, field := range fields {
+		value, ok := data[field.Name]
+		if !ok {
+			return nil, fmt.Errorf("%w: %s is missing field %q of type %s", ErrInvalidTypedData, path, field.Name, name)
+		}
+		word, err := t.encodeValue(field.Type, value, path+"."+field.Name)
+		if err != nil {
+			return nil, err
+		}
+		encoded = append(encoded, word...)
+	}
+	return encoded, nil
+}
+
+// Encodes a value of any type as a 32-byte word: structs as their hashStruct, arrays as the
+// keccak256 of their encoded elements, string and bytes as the keccak256 of their contents,
+// and atomic types as abi.encode does.
+func (t Types) encodeValue(typ string, value any, path string) ([]byte, error) {
+	if elem, size, isArray := typescan.SplitArrayType(typ); isArray {
+		items, ok := value.([]any)
+		if !ok {
+			return nil, fmt.Errorf("%w: %s: %v is not an array of %s", ErrInvalidTypedData, path, value, elem)
+		}
+		if size >= 0 && size != len(items) {
+			return nil, fmt.Errorf("%w: %s: %d values given for fixed array %s", ErrInvalidTypedData, path, len(items), typ)
+		}
+		var encoded []byte
+		for idx, item := range items {
+			word, err := t.encodeValue(elem, item, fmt.Sprintf("%s[%d]", path, idx))
+			if err != nil {
+				return nil, err
+			}
+			encoded = append(encoded, word...)
+		}
+		return crypto.Keccak256(encoded), nil
+	}
+
+	if _, ok := t[typ]; ok {
+		data, ok := value.(map[string]any)
+		if !ok {
+			return nil, fmt.Errorf("%w: %s: %v is not a struct %s", ErrInvalidTypedData, path, value, typ)
+		}
+		hash, err := t.hashStruct(typ, data, path)
+		if err != nil {
+			return nil, err
+		}
+		return hash[:], nil
+	}
+
+	text, ok := scalarText(value)
+	if !ok {
+		return nil, fmt.Errorf("%w: %s: %v is not a single %s value", ErrInvalidTypedData, path, value, typ)
+	}
+	switch typ {
+	case "string":
+		return crypto.Keccak256([]byte(text)), nil
+	case "bytes":
+		b, err := encdec.HexToBytes(text)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+		return crypto.Keccak256(b), nil
+	}
+
+	abiType, err := atomicType(typ)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	word, err := encdec.EncodeArguments(text, abi.Arguments{{Name: path, Type: abiType}})
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return word, nil
+}
+
+// Parses an atomic or dynamic type with encdec, eg: "uint256", "bytes32" or "string". Tuples
+// are not types in EIP-712, structs are, and field types carry no parameter name.
+func atomicType(typ string) (abi.Type, error) {
+	args, err := encdec.ParseTypes(typ)
+	if _, _, isArray := typescan.SplitArrayType(typ); err != nil || len(args) != 1 || args[0].Type.T == abi.TupleTy || isArray || len(strings.Fields(typ)) != 1 {
+		return abi.Type{}, fmt.Errorf("%w: %q is not an atomic type, or a struct defined in types", ErrInvalidTypedData, typ)
+	}
+	return args[0].Type, nil
+}
+
+// Returns the text of a JSON string, number or bool, in the form encdec parses values.
+func scalarText(value any) (string, bool) {
+	switch v := value.(type) {
+	case string:
+		return v, true
+	case json.Number:
+		return v.String(), true
+	case bool:
+		return strconv.FormatBool(v), true
+	}
+	return "", false
+}
+
+func hasField(fields []Field, name string) bool {
+	for _, field := range fields {
+		if field.Name == name {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,190 @@
+package eip712
+
+import (
+	"errors"
+	"fmt"
+	"path"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/zeuslawyer/hextool/encdec"
+)
+
+// The expected hashes were checked against go-ethereum's signer/core/apitypes.
+func TestHash(t *testing.T) {
+	tests := []struct {
+		file string
+		want Hashes
+	}{
+		{
+			// the example of EIP-712.
+			file: "mail.json",
+			want: Hashes{
+				DomainSeparator: common.HexToHash("0xf2cee375fa42b42143804025fc449deafd50cc031ca257e0b194a650a912090f"),
+				EncodeType:      "Mail(Person from,Person to,string contents)Person(string name,address wallet)",
+				TypeHash:        common.HexToHash("0xa0cedeb2dc280ba39b857546d74f5549c3a1d7bdc2dd96bf881f76108e23dac2"),
+				StructHash:      common.HexToHash("0xc52c0ee5d84264471806290a3f2c4cecfc5490626bf912d01f240d7a274b371e"),
+				Digest:          common.HexToHash("0xbe609aee343fb3c4b28e1df9e632fca64fcfaede20f02e86244efddf30957bd2"),
+			},
+		},
+		{
+			// an ERC-2612 permit, without EIP712Domain in its types.
+			file: "permit.json",
+			want: Hashes{
+				DomainSeparator: common.HexToHash("0x06c37168a7db5138defc7866392bb87a741f9b3d104deb5094588ce041cae335"),
+				EncodeType:      "Permit(address owner,address spender,uint256 value,uint256 nonce,uint256 deadline)",
+				TypeHash:        common.HexToHash("0x6e71edae12b1b97f4d1f60370fef10105fa2faae0126114a169c64845d6126c9"),
+				StructHash:      common.HexToHash("0x12bb930b7a943b7a0741846a6fa439858f2cf15010220e69097d9817f4638dd4"),
+				Digest:          common.HexToHash("0x0b26ec8c7cbc5cb79d4c889b970df4218f5533a3d11371cc2246992e5460cf28"),
+			},
+		},
+		{
+			// nested structs, arrays of structs and strings, and bytes.
+			file: "order.json",
+			want: Hashes{
+				DomainSeparator: common.HexToHash("0x4ad9610c4893bc77f919e0eb8c354b4006c259398104a551d4ec2dc308bc81f6"),
+				EncodeType:      "Order(address maker,Leg[] legs,string[] tags,bytes data,uint64 expires,bool partial)Leg(Token token,uint128 amount)Token(address addr,uint8 kind)",
+				TypeHash:        common.HexToHash("0xc76bca6170db4190fc6e8e67160908ff93cff7cf7cbbbdf4c1fb6a42042eeaa4"),
+				StructHash:      common.HexToHash("0x5a542008af64694206ea50f872432ce93b0222005ff270712a75bc646e703fa1"),
+				Digest:          common.HexToHash("0x2d2de86db7be84ac81b5ce04f2f5bccf5dae559636f0279029d310136dc1c2a1"),
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.file, func(t *testing.T) {
+			typedData, err := LoadTypedData(path.Join("testdata", tc.file))
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, err := typedData.Hash()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if *got != tc.want {
+				t.Errorf("Hash() =\n%s\nwant\n%s", got, &tc.want)
+			}
+		})
+	}
+}
+
+func TestHashStructArrays(t *testing.T) {
+	types := Types{"Grid": {{Name: "cells", Type: "int16[2][]"}}}
+	data, err := ParseTypedData([]byte(`{"primaryType": "Grid", "types": {"Grid": [{"name": "cells", "type": "int16[2][]"}]}, "message": {"cells": [[1, -2], [-3, 4]]}}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// each inner array is hashed, then the outer array is the hash of the inner hashes.
+	word := func(values string) []byte {
+		encoded, err := encdec.Encode(values, "int16, int16")
+		if err != nil {
+			t.Fatal(err)
+		}
+		return crypto.Keccak256(common.FromHex(encoded))
+	}
+	cells := crypto.Keccak256(word("1, -2"), word("-3, 4"))
+	want := crypto.Keccak256Hash(crypto.Keccak256([]byte("Grid(int16[2][] cells)")), cells)
+
+	if got, err := types.HashStruct("Grid", data.Message); err != nil || got != want {
+		t.Errorf("HashStruct() = %s, %v, want %s", got, err, want)
+	}
+}
+
+// Array types are read by encdec, as abi.encode reads them: a field type is accepted exactly
+// when abi.encode accepts it, whether its elements are atomic types or structs.
+func TestArrayTypesMatchAbiEncode(t *testing.T) {
+	for _, typ := range []string{"uint256[2]", "uint256[02]", "uint256[ 2]", "uint256[2 ]", "uint256 [2]", "uint256[]", "uint256[3]", "uint7[2]", "uint256[2", "uint256[-2]"} {
+		t.Run(typ, func(t *testing.T) {
+			_, abiErr := encdec.Encode("[1,2]", typ)
+
+			atomic := fmt.Sprintf(`{"primaryType": "Pair", "types": {"Pair": [{"name": "values", "type": %q}]}, "message": {"values": [1, 2]}}`, typ)
+			elem := strings.Replace(typ, "uint256", "Point", 1)
+			structs := fmt.Sprintf(`{"primaryType": "Pair", "types": {"Pair": [{"name": "values", "type": %q}], "Point": [{"name": "x", "type": "uint256"}]}, "message": {"values": [{"x": 1}, {"x": 2}]}}`, elem)
+			for _, json := range []string{atomic, structs} {
+				if elem == typ && json == structs {
+					continue
+				}
+				data, err := ParseTypedData([]byte(json))
+				if err != nil {
+					t.Fatal(err)
+				}
+				if _, err := data.Hash(); (err == nil) != (abiErr == nil) {
+					t.Errorf("Hash() error = %v, abi.encode error = %v, want both to fail or succeed:\n%s", err, abiErr, json)
+				}
+			}
+		})
+	}
+}
+
+func TestHashErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		json    string
+		wantErr error
+		wantMsg string
+	}{
+		{
+			name:    "undefined primary type",
+			json:    `{"primaryType": "Mail", "types": {}}`,
+			wantErr: ErrInvalidTypedData,
+			wantMsg: "primaryType Mail is not defined",
+		},
+		{
+			name:    "undefined struct type",
+			json:    `{"primaryType": "Mail", "types": {"Mail": [{"name": "from", "type": "Person"}]}, "message": {"from": {}}}`,
+			wantErr: ErrInvalidTypedData,
+			wantMsg: `"Person" is not an atomic type, or a struct defined in types`,
+		},
+		{
+			name:    "type with a parameter name",
+			json:    `{"primaryType": "Vote", "types": {"Vote": [{"name": "weight", "type": "uint8 weight"}]}, "message": {"weight": 1}}`,
+			wantErr: ErrInvalidTypedData,
+			wantMsg: `"uint8 weight" is not an atomic type`,
+		},
+		{
+			name:    "missing field",
+			json:    `{"primaryType": "Mail", "types": {"Mail": [{"name": "contents", "type": "string"}]}, "message": {}}`,
+			wantErr: ErrInvalidTypedData,
+			wantMsg: `Mail is missing field "contents"`,
+		},
+		{
+			name:    "extra field",
+			json:    `{"primaryType": "Mail", "types": {"Mail": [{"name": "contents", "type": "string"}]}, "message": {"contents": "hi", "to": "Bob"}}`,
+			wantErr: ErrInvalidTypedData,
+			wantMsg: `Mail has field "to", which is not in type Mail`,
+		},
+		{
+			name:    "value that overflows its type",
+			json:    `{"primaryType": "Vote", "types": {"Vote": [{"name": "weight", "type": "uint8"}]}, "message": {"weight": 256}}`,
+			wantErr: encdec.ErrTypeMismatch,
+			wantMsg: "Vote.weight",
+		},
+		{
+			name:    "malformed bytes",
+			json:    `{"primaryType": "Call", "types": {"Call": [{"name": "data", "type": "bytes"}]}, "message": {"data": "0xzz"}}`,
+			wantErr: encdec.ErrInvalidHex,
+			wantMsg: "Call.data",
+		},
+		{
+			name:    "wrong length of fixed array",
+			json:    `{"primaryType": "Pair", "types": {"Pair": [{"name": "ids", "type": "uint256[2]"}]}, "message": {"ids": [1]}}`,
+			wantErr: ErrInvalidTypedData,
+			wantMsg: "1 values given for fixed array uint256[2]",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			typedData, err := ParseTypedData([]byte(tc.json))
+			if err == nil {
+				_, err = typedData.Hash()
+			}
+			if !errors.Is(err, tc.wantErr) || !strings.Contains(err.Error(), tc.wantMsg) {
+				t.Errorf("error = %v, want %v containing %q", err, tc.wantErr, tc.wantMsg)
+			}
+		})
+	}
+}
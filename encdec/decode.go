@@ -1,7 +1,6 @@
 package encdec
 
 import (
-	"encoding/hex"
 	"fmt"
 	"math/big"
 	"strings"
@@ -14,9 +13,38 @@ import (
  * Decodes `hex` to a string. `hex` must be prexifed with 0x.
  */
 func DecodeHexToString(hex string) string {
-	decodedBytes := hexutil.MustDecode(hex)
+	decoded, err := HexToString(hex)
+	if err != nil {
+		panic(err.Error())
+	}
+
+	return decoded + "\n" // concat newlines so that returned output in terminal pushes terminal prompt "%" to new line.
+}
+
+/*
+ * Decodes `hex` to a string, returning an error wrapping ErrInvalidHex
+ * if `hex` is not a valid 0x-prefixed hex string.
+ */
+func HexToString(hex string) (string, error) {
+	decodedBytes, err := HexToBytes(hex)
+	if err != nil {
+		return "", err
+	}
+
+	return string(decodedBytes), nil
+}
+
+/*
+ * Decodes `hex` to bytes, returning an error wrapping ErrInvalidHex
+ * if `hex` is not a valid 0x-prefixed hex string.
+ */
+func HexToBytes(hex string) ([]byte, error) {
+	decodedBytes, err := hexutil.Decode(hex)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %q: %v", ErrInvalidHex, hex, err)
+	}
 
-	return string(decodedBytes) + "\n" // concat newlines so that returned output in terminal pushes terminal prompt "%" to new line.
+	return decodedBytes, nil
 }
 
 /*
@@ -27,15 +55,31 @@ func DecodeHexToBigInt(hex string) *big.Int {
 		panic(fmt.Sprintf("%q provided as --hex input", hex))
 	}
 
-	if (len(hex) == 0) || (hex == "0x00") {
-		return new(big.Int).SetInt64(0)
+	bi, err := HexToBigInt(hex)
+	if err != nil {
+		panic(err.Error())
 	}
-
-	hexWithoutPrefix := hex[2:]
-	bi, _ := new(big.Int).SetString(hexWithoutPrefix, 16)
 	return bi
 }
 
+/*
+ * Decodes `hex` to a Big Int, returning an error wrapping ErrInvalidHex
+ * if `hex` is empty or contains non-hex characters. A leading "-" after
+ * the 0x prefix decodes to a negative number, eg: "0x-7bd" is -1981.
+ */
+func HexToBigInt(hex string) (*big.Int, error) {
+	hexWithoutPrefix := strings.TrimPrefix(hex, "0x")
+	if hexWithoutPrefix == "" {
+		return nil, fmt.Errorf("%w: %q has no digits to decode", ErrInvalidHex, hex)
+	}
+
+	bi, ok := new(big.Int).SetString(hexWithoutPrefix, 16)
+	if !ok {
+		return nil, fmt.Errorf("%w: %q is not a hex number", ErrInvalidHex, hex)
+	}
+	return bi, nil
+}
+
 /*
  * Given a hex string and a tuple of types, decode the hex string to the corresponding types.
  * `dataTypes` is a comma-separated string of types. Eg: "string, uint, bool, uint".
@@ -43,30 +87,49 @@ func DecodeHexToBigInt(hex string) *big.Int {
  */
 
 func AbiDecode(hexInput string, dataTypes string) []any {
-	if strings.HasPrefix(hexInput, "0x") {
-		// Strip out the "0x" prefix
-		hexInput = hexInput[2:]
+	values, err := Decode(hexInput, dataTypes)
+	if err != nil {
+		fmt.Printf("Error decoding hex input: %v", err)
+
+		return nil
+	}
+	for _, val := range values {
+		// print type of the value
+		fmt.Printf("Decoded value '%v' of type %T\n", val, val)
 	}
+	return values
+}
 
-	b, err := hex.DecodeString(hexInput)
+/*
+ * Same as AbiDecode, but returns an error instead of printing it.
+ * Errors wrap ErrInvalidHex when `hexInput` cannot be decoded into bytes,
+ * and ErrTypeMismatch when the bytes cannot be unpacked into `dataTypes`.
+ * `dataTypes` may contain tuples, which are decoded into structs. See ParseTypes.
+ */
+func Decode(hexInput string, dataTypes string) ([]any, error) {
+	args, err := ParseTypes(dataTypes)
 	if err != nil {
-		fmt.Printf("Error decoding hex input: %v", err)
+		return nil, err
+	}
+	return DecodeArguments(hexInput, args)
+}
+
+/*
+ * Decodes `hexInput` as per `args`, eg: the inputs of an abi.Method or the arguments returned by ParseTypes.
+ */
+func DecodeArguments(hexInput string, args abi.Arguments) ([]any, error) {
+	b, err := hexToBytes(hexInput)
+	if err != nil {
+		return nil, err
 	}
 
 	if len(b) == 0 {
-		return []any{} // Empty.
+		return []any{}, nil // Empty.
 	}
 
-	var args abi.Arguments = dataTypesToAbiArgs(dataTypes)
 	values, err := args.Unpack(b)
 	if err != nil {
-		fmt.Printf("Error Unpacking hex input: %v", err)
-
-		return nil
-	}
-	for _, val := range values {
-		// print type of the value
-		fmt.Printf("Decoded value '%v' of type %T\n", val, val)
+		return nil, fmt.Errorf("%w: unpacking hex input: %v", ErrTypeMismatch, err)
 	}
-	return values
+	return values, nil
 }
@@ -1,6 +1,7 @@
 package encdec
 
 import (
+	"errors"
 	"math/big"
 	"reflect"
 	"strings"
@@ -176,3 +177,144 @@ func TestAbiDecode(t *testing.T) {
 		})
 	}
 }
+
+func TestHexToBigInt(t *testing.T) {
+	tests := []struct {
+		name     string
+		inputHex string
+		want     *big.Int
+		wantErr  error
+	}{
+		{
+			name:     "HappyPath",
+			inputHex: "0xd431",
+			want:     new(big.Int).SetInt64(54321),
+		},
+		{
+			name:     "0x only",
+			inputHex: "0x",
+			wantErr:  ErrInvalidHex,
+		},
+		{
+			name:     "not hex",
+			inputHex: "0xhextool",
+			wantErr:  ErrInvalidHex,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := HexToBigInt(tc.inputHex)
+			if tc.wantErr != nil {
+				if !errors.Is(err, tc.wantErr) {
+					t.Errorf("HexToBigInt() error = %v, want %v", err, tc.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("HexToBigInt() unexpected error: %v", err)
+			}
+			if got.Cmp(tc.want) != 0 {
+				t.Errorf("HexToBigInt() = %q, want %q", got.String(), tc.want.String())
+			}
+		})
+	}
+}
+
+func TestDecode(t *testing.T) {
+	tests := []struct {
+		name      string
+		inputHex  string
+		dataTypes string
+		want      []any
+		wantErr   error
+	}{
+		{
+			name:      "HappyPath",
+			inputHex:  "0x000000000000000000000000000000000000000000000000000000000000002b",
+			dataTypes: "uint16",
+			want:      []any{uint16(43)},
+		},
+		{
+			name:      "invalid hex",
+			inputHex:  "0x2bzz",
+			dataTypes: "uint16",
+			wantErr:   ErrInvalidHex,
+		},
+		{
+			name:      "too few bytes for types",
+			inputHex:  "0x000000000000000000000000000000000000000000000000000000000000002b",
+			dataTypes: "uint16, uint16",
+			wantErr:   ErrTypeMismatch,
+		},
+		{
+			name:      "unknown type",
+			inputHex:  "0x000000000000000000000000000000000000000000000000000000000000002b",
+			dataTypes: "hextool",
+			wantErr:   ErrTypeMismatch,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := Decode(tc.inputHex, tc.dataTypes)
+			if tc.wantErr != nil {
+				if !errors.Is(err, tc.wantErr) {
+					t.Errorf("Decode() error = %v, want %v", err, tc.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Decode() unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("Decode() = %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestDecodeTuplesRoundTrip(t *testing.T) {
+	tests := []struct {
+		name      string
+		input     string
+		dataTypes string
+		want      string // formatted decoded values
+	}{
+		{
+			name:      "named fields",
+			input:     "(0x208AA722Aca42399eaC5192EE778e4D42f4E5De3, 100)",
+			dataTypes: "(address to, uint256 amount)",
+			want:      "[(to: 0x208AA722Aca42399eaC5192EE778e4D42f4E5De3, amount: 100)]",
+		},
+		{
+			name:      "unnamed fields",
+			input:     "7, (hextool, 1)",
+			dataTypes: "uint8, tuple(string,uint8)",
+			want:      `[7 ("hextool", 1)]`,
+		},
+		{
+			name:      "nested tuples",
+			input:     "(3, (0x208AA722Aca42399eaC5192EE778e4D42f4E5De3, 100), rad)",
+			dataTypes: "(uint16 id, (address to, uint amount) transfer, string memo)",
+			want:      `[(id: 3, transfer: (to: 0x208AA722Aca42399eaC5192EE778e4D42f4E5De3, amount: 100), memo: "rad")]`,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			encoded, err := Encode(tc.input, tc.dataTypes)
+			if err != nil {
+				t.Fatalf("Encode() unexpected error: %v", err)
+			}
+			args, err := ParseTypes(tc.dataTypes)
+			if err != nil {
+				t.Fatalf("ParseTypes() unexpected error: %v", err)
+			}
+			values, err := DecodeArguments(encoded, args)
+			if err != nil {
+				t.Fatalf("DecodeArguments() unexpected error: %v", err)
+			}
+			if got := FormatValues(args, values); got != tc.want {
+				t.Errorf("FormatValues() = %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
@@ -3,6 +3,7 @@ package encdec
 import (
 	"fmt"
 	"math/big"
+	"reflect"
 	"strconv"
 	"strings"
 
@@ -17,108 +18,243 @@ import (
   - `input` is a comma-separated string of values. Eg: "hello, 123, true, 456".
     `dataTypes` is a comma-separated string of types. Eg: "string, uint, bool, uint".
   - The sequence of types in `dataTypes` must match the sequence of values in `input`.
+  - Tuple values are wrapped in parentheses, eg: "(0x208AA722Aca42399eaC5192EE778e4D42f4E5De3, 100)"
+    for the type "(address to, uint256 amount)". Array values are wrapped in square brackets,
+    eg: "[1,2,3]" for "uint8[]", and strings containing commas are wrapped in double quotes.
 */
 func AbiEncode(inputValuesStr string, dataTypesStr string) (res string) {
+	res, err := Encode(inputValuesStr, dataTypesStr)
+	if err != nil {
+		panic(err.Error())
+	}
+	return res
+}
+
+/*
+  - Same as AbiEncode, but returns an error instead of panicking.
+  - Errors wrap ErrTypeMismatch when a type is unsupported, when the number of values
+    and types differ, or when a value cannot be converted to its type.
+*/
+func Encode(inputValuesStr string, dataTypesStr string) (string, error) {
 	if len(inputValuesStr) == 0 {
-		res = "0x"
-		return res
-	}
-
-	// Split  each input into a slice of string values
-	inputValuesSlice := strings.Split(inputValuesStr, ",")
-	dataTypesSlice := strings.Split(dataTypesStr, ",")
-	if len(inputValuesSlice) != len(dataTypesSlice) {
-		panic(fmt.Sprintf("Number of input values does not match number of types - %d inputs to  %d types", len(inputValuesSlice), len(dataTypesSlice)))
-
-	}
-
-	// convert strings to the appropriate types
-	// TODO zubin resume here. Add other types. Complete the tests.
-	typedInputValuesSlice := make([]any, len(dataTypesSlice))
-
-	for idx, ty := range dataTypesSlice {
-		_ty := strings.TrimSpace(ty)
-		inpValue := inputValuesSlice[idx]
-
-		switch _ty {
-		case "string":
-			typedInputValuesSlice[idx] = inputValuesSlice[idx]
-		case "address":
-			typedInputValuesSlice[idx] = common.HexToAddress(inputValuesSlice[idx])
-
-		// ints and  uints greater than 64 bits need special treatment using BigInt.
-		case "uint", "uint128", "uint256", "int", "int128", "int256":
-			typedValue, ok := new(big.Int).SetString(inpValue, 10)
-			if !ok {
-				panic(fmt.Sprintf("Error converting %q  of type %s to big.Int", inpValue, _ty))
-			}
-			typedInputValuesSlice[idx] = typedValue
-
-		case "uint8", "uint16", "uint32", "uint64":
-			var bitsize int
-
-			// convert the last two characters into int and assign it to bitsize
-			bsize, err := strconv.Atoi(_ty[4:])
-			if err != nil {
-				panic(fmt.Sprintf("Unsupported bitsize %q in %s", bsize, _ty))
-			}
-			bitsize = bsize
-
-			typedValue, err := strconv.ParseUint(inpValue, 10, bitsize)
-			if err != nil {
-				panic(fmt.Sprintf("Error converting %q  of type %s to uint%d:  %s", inpValue, _ty, bitsize, err))
-			}
-			if bitsize == 8 {
-				typedInputValuesSlice[idx] = uint8(typedValue)
-			}
-			if bitsize == 16 {
-				typedInputValuesSlice[idx] = uint16(typedValue)
-			}
-			if bitsize == 32 {
-				typedInputValuesSlice[idx] = uint32(typedValue)
-			}
-			if bitsize == 64 {
-				typedInputValuesSlice[idx] = uint64(typedValue)
-			}
-		case "int8", "int16", "int32", "int64":
-			var bitsize int
-
-			// convert the last two characters into int and assign it to bitsize
-			bsize, err := strconv.Atoi(_ty[3:])
-			if err != nil {
-				panic(fmt.Sprintf("Unsupported bitsize %q in %s", bsize, _ty))
-			}
-			bitsize = bsize
-
-			typedValue, err := strconv.ParseInt(inpValue, 10, bitsize)
-			if err != nil {
-				panic(fmt.Sprintf("Error converting %q  of type %s to int%d", inpValue, _ty, bitsize))
-			}
-			if bitsize == 8 {
-				typedInputValuesSlice[idx] = int8(typedValue)
-			}
-			if bitsize == 16 {
-				typedInputValuesSlice[idx] = int16(typedValue)
-			}
-			if bitsize == 32 {
-				typedInputValuesSlice[idx] = int32(typedValue)
-			}
-			if bitsize == 64 {
-				typedInputValuesSlice[idx] = int64(typedValue)
-			}
-
-		default:
-			panic(fmt.Sprintf("Unsupported type %q", _ty))
-		}
+		return "0x", nil
+	}
 
+	args, err := ParseTypes(dataTypesStr)
+	if err != nil {
+		return "", err
+	}
+
+	packed, err := EncodeArguments(inputValuesStr, args)
+	if err != nil {
+		return "", err
+	}
+
+	return hexutil.Encode(packed), nil
+}
+
+/*
+  - ABI-encodes the comma-separated `inputValuesStr` as per `args`, eg: the inputs of an abi.Method
+    or the arguments returned by ParseTypes.
+*/
+func EncodeArguments(inputValuesStr string, args abi.Arguments) ([]byte, error) {
+	// Parse the input into a slice of values, one per argument.
+	inputValuesSlice, err := parseValues(inputValuesStr)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrTypeMismatch, err)
+	}
+	if len(inputValuesSlice) != len(args) {
+		return nil, fmt.Errorf("%w: Number of input values does not match number of types - %d inputs to  %d types", ErrTypeMismatch, len(inputValuesSlice), len(args))
+	}
+
+	// convert values to the appropriate types
+	typedInputValuesSlice := make([]any, len(args))
+	for idx, arg := range args {
+		typedValue, err := convertValue(inputValuesSlice[idx], arg.Type, arg.Name)
+		if err != nil {
+			return nil, err
+		}
+		typedInputValuesSlice[idx] = typedValue.Interface()
 	}
 
-	var args abi.Arguments = dataTypesToAbiArgs(dataTypesStr)
 	values, err := args.PackValues(typedInputValuesSlice)
 	if err != nil {
-		panic(fmt.Sprintf("Error packing input values: %v", err))
+		return nil, fmt.Errorf("%w: packing input values: %v", ErrTypeMismatch, err)
 	}
+	return values, nil
+}
 
-	res = hexutil.Encode(values)
-	return res
+// Converts the parsed `inpValue` into the Go value that go-ethereum expects when packing `abiType`.
+// `label` names the argument (or tuple field, or array element) being converted, for use in error messages.
+func convertValue(inpValue valueNode, abiType abi.Type, label string) (reflect.Value, error) {
+	switch abiType.T {
+	case abi.TupleTy:
+		return convertTuple(inpValue, abiType, label)
+	case abi.SliceTy, abi.ArrayTy:
+		return convertArray(inpValue, abiType, label)
+	}
+
+	if inpValue.isList {
+		return reflect.Value{}, fmt.Errorf("%w: argument %s: got list %s for type %s", ErrTypeMismatch, label, inpValue, abiType.String())
+	}
+	typedValue, err := convertElementary(inpValue.text, abiType)
+	if err != nil {
+		return reflect.Value{}, fmt.Errorf("%w: argument %s: %v", ErrTypeMismatch, label, err)
+	}
+	return reflect.ValueOf(typedValue), nil
+}
+
+// Converts a parenthesised list of values into the struct go-ethereum uses for `abiType`.
+// Square brackets are accepted too, so JSON-style nested arrays can be passed for tuples.
+func convertTuple(inpValue valueNode, abiType abi.Type, label string) (reflect.Value, error) {
+	if !inpValue.isList {
+		return reflect.Value{}, fmt.Errorf("%w: argument %s: tuple value %s must be wrapped in parentheses", ErrTypeMismatch, label, inpValue)
+	}
+	if len(inpValue.items) != len(abiType.TupleElems) {
+		return reflect.Value{}, fmt.Errorf("%w: argument %s: %d values given for tuple %s", ErrTypeMismatch, label, len(inpValue.items), abiType.String())
+	}
+
+	tuple := reflect.New(abiType.TupleType).Elem()
+	for idx, elemType := range abiType.TupleElems {
+		fieldValue, err := convertValue(inpValue.items[idx], *elemType, label+"."+abiType.TupleRawNames[idx])
+		if err != nil {
+			return reflect.Value{}, err
+		}
+		tuple.Field(idx).Set(fieldValue)
+	}
+	return tuple, nil
+}
+
+// Converts a square-bracketed list of values into the slice (for `T[]`) or array (for `T[N]`)
+// go-ethereum uses for `abiType`.
+func convertArray(inpValue valueNode, abiType abi.Type, label string) (reflect.Value, error) {
+	if !inpValue.isList || inpValue.open != '[' {
+		return reflect.Value{}, fmt.Errorf("%w: argument %s: array value %s must be wrapped in square brackets", ErrTypeMismatch, label, inpValue)
+	}
+
+	var array reflect.Value
+	if abiType.T == abi.SliceTy {
+		array = reflect.MakeSlice(abiType.GetType(), len(inpValue.items), len(inpValue.items))
+	} else {
+		if len(inpValue.items) != abiType.Size {
+			return reflect.Value{}, fmt.Errorf("%w: argument %s: %d values given for fixed array %s", ErrTypeMismatch, label, len(inpValue.items), abiType.String())
+		}
+		array = reflect.New(abiType.GetType()).Elem()
+	}
+
+	for idx, item := range inpValue.items {
+		elemValue, err := convertValue(item, *abiType.Elem, fmt.Sprintf("%s[%d]", label, idx))
+		if err != nil {
+			return reflect.Value{}, err
+		}
+		array.Index(idx).Set(elemValue)
+	}
+	return array, nil
+}
+
+// Converts a single value of an elementary (non-tuple, non-array) type.
+func convertElementary(inpValue string, abiType abi.Type) (any, error) {
+	_ty := abiType.String()
+
+	switch abiType.T {
+	case abi.StringTy:
+		return inpValue, nil
+	case abi.AddressTy:
+		if !common.IsHexAddress(inpValue) {
+			return nil, fmt.Errorf("%q is not a valid hex address", inpValue)
+		}
+		return common.HexToAddress(inpValue), nil
+	case abi.BoolTy:
+		typedValue, err := strconv.ParseBool(inpValue)
+		if err != nil {
+			return nil, fmt.Errorf("%q is not a valid bool, use true or false", inpValue)
+		}
+		return typedValue, nil
+	case abi.UintTy, abi.IntTy:
+		return convertInt(inpValue, abiType)
+	case abi.BytesTy:
+		return hexValueToBytes(inpValue, _ty)
+	case abi.FixedBytesTy, abi.FunctionTy:
+		b, err := hexValueToBytes(inpValue, _ty)
+		if err != nil {
+			return nil, err
+		}
+		if len(b) != abiType.Size {
+			return nil, fmt.Errorf("%q is %d bytes long, %s needs exactly %d bytes", inpValue, len(b), _ty, abiType.Size)
+		}
+		// go-ethereum packs fixed size bytes from a [N]byte array.
+		typedValue := reflect.New(abiType.GetType()).Elem()
+		reflect.Copy(typedValue, reflect.ValueOf(b))
+		return typedValue.Interface(), nil
+	}
+
+	return nil, fmt.Errorf("Unsupported type %q", _ty)
+}
+
+// Converts a decimal, or 0x-prefixed hex, number into the Go type that go-ethereum uses for
+// `abiType`: a native int or uint for 8, 16, 32 and 64 bit types, and a *big.Int for all other widths.
+// Numbers that do not fit in the type's bit size are rejected rather than silently wrapped.
+func convertInt(inpValue string, abiType abi.Type) (any, error) {
+	_ty := abiType.String()
+
+	typedValue, ok := new(big.Int), false
+	if digits := strings.TrimPrefix(inpValue, "-"); strings.HasPrefix(digits, "0x") {
+		if typedValue, ok = typedValue.SetString(digits[2:], 16); ok && digits != inpValue {
+			typedValue.Neg(typedValue)
+		}
+	} else {
+		typedValue, ok = typedValue.SetString(inpValue, 10)
+	}
+	if !ok {
+		return nil, fmt.Errorf("Error converting %q  of type %s to big.Int", inpValue, _ty)
+	}
+
+	// the range of values a type can hold is [minValue, maxValue]
+	maxValue := new(big.Int).Lsh(big.NewInt(1), uint(abiType.Size))
+	minValue := new(big.Int)
+	if abiType.T == abi.IntTy {
+		maxValue.Rsh(maxValue, 1)
+		minValue.Neg(maxValue)
+	}
+	maxValue.Sub(maxValue, big.NewInt(1))
+	if typedValue.Cmp(minValue) < 0 || typedValue.Cmp(maxValue) > 0 {
+		return nil, fmt.Errorf("value %s overflows %s, which must be between %s and %s", inpValue, _ty, minValue, maxValue)
+	}
+
+	if abiType.T == abi.UintTy {
+		switch abiType.Size {
+		case 8:
+			return uint8(typedValue.Uint64()), nil
+		case 16:
+			return uint16(typedValue.Uint64()), nil
+		case 32:
+			return uint32(typedValue.Uint64()), nil
+		case 64:
+			return typedValue.Uint64(), nil
+		}
+	} else {
+		switch abiType.Size {
+		case 8:
+			return int8(typedValue.Int64()), nil
+		case 16:
+			return int16(typedValue.Int64()), nil
+		case 32:
+			return int32(typedValue.Int64()), nil
+		case 64:
+			return typedValue.Int64(), nil
+		}
+	}
+	return typedValue, nil
+}
+
+// Decodes a 0x-prefixed hex value of a bytes-like type.
+func hexValueToBytes(inpValue string, _ty string) ([]byte, error) {
+	if !strings.HasPrefix(inpValue, "0x") {
+		return nil, fmt.Errorf("%s value %q must be 0x-prefixed hex", _ty, inpValue)
+	}
+	b, err := hexToBytes(inpValue)
+	if err != nil {
+		return nil, err
+	}
+	return b, nil
 }
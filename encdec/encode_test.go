@@ -1,6 +1,7 @@
 package encdec
 
 import (
+	"errors"
 	"strings"
 	"testing"
 )
@@ -112,3 +113,313 @@ func TestAbiEncode(t *testing.T) {
 		})
 	}
 }
+
+func TestEncode(t *testing.T) {
+	tests := []struct {
+		name      string
+		input     string
+		dataTypes string
+		want      string
+		wantErr   error
+	}{
+		{
+			name:      "HappyPath-multiple scalars including address",
+			input:     "1981,0x208AA722Aca42399eaC5192EE778e4D42f4E5De3",
+			dataTypes: "int64,address",
+			want:      "0x00000000000000000000000000000000000000000000000000000000000007bd000000000000000000000000208aa722aca42399eac5192ee778e4d42f4e5de3",
+		},
+		{
+			name:      "mismatched input length",
+			input:     "hextool is rad",
+			dataTypes: "uint32,address,string",
+			wantErr:   ErrTypeMismatch,
+		},
+		{
+			name:      "unknown type",
+			input:     "8",
+			dataTypes: "uint999",
+			wantErr:   ErrTypeMismatch,
+		},
+		{
+			name:      "value does not fit type",
+			input:     "256",
+			dataTypes: "uint8",
+			wantErr:   ErrTypeMismatch,
+		},
+		{
+			name:      "invalid address",
+			input:     "0x208AA722",
+			dataTypes: "address",
+			wantErr:   ErrTypeMismatch,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := Encode(tc.input, tc.dataTypes)
+			if tc.wantErr != nil {
+				if !errors.Is(err, tc.wantErr) {
+					t.Errorf("Encode() error = %v, want %v", err, tc.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Encode() unexpected error: %v", err)
+			}
+			if got != tc.want {
+				t.Errorf("Encode() = %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestEncodeTuples(t *testing.T) {
+	tests := []struct {
+		name      string
+		input     string
+		dataTypes string
+		want      string
+		wantErr   error
+	}{
+		{
+			name:      "static tuple",
+			input:     "(0x208AA722Aca42399eaC5192EE778e4D42f4E5De3, 100)",
+			dataTypes: "(address to, uint256 amount)",
+			want:      "0x000000000000000000000000208aa722aca42399eac5192ee778e4d42f4e5de30000000000000000000000000000000000000000000000000000000000000064",
+		},
+		{
+			name:      "dynamic tuple",
+			input:     "(hi, 1)",
+			dataTypes: "tuple(string,uint8)",
+			want:      "0x00000000000000000000000000000000000000000000000000000000000000200000000000000000000000000000000000000000000000000000000000000040000000000000000000000000000000000000000000000000000000000000000100000000000000000000000000000000000000000000000000000000000000026869000000000000000000000000000000000000000000000000000000000000",
+		},
+		{
+			name:      "nested tuple after a scalar",
+			input:     "7, (3, (0x208AA722Aca42399eaC5192EE778e4D42f4E5De3, 100))",
+			dataTypes: "uint8, (uint16, (address, uint))",
+			want:      "0x00000000000000000000000000000000000000000000000000000000000000070000000000000000000000000000000000000000000000000000000000000003000000000000000000000000208aa722aca42399eac5192ee778e4d42f4e5de30000000000000000000000000000000000000000000000000000000000000064",
+		},
+		{
+			name:      "tuple value missing parentheses",
+			input:     "0x208AA722Aca42399eaC5192EE778e4D42f4E5De3, 100",
+			dataTypes: "(address to, uint256 amount)",
+			wantErr:   ErrTypeMismatch,
+		},
+		{
+			name:      "tuple value with too few fields",
+			input:     "(0x208AA722Aca42399eaC5192EE778e4D42f4E5De3)",
+			dataTypes: "(address to, uint256 amount)",
+			wantErr:   ErrTypeMismatch,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := Encode(tc.input, tc.dataTypes)
+			if tc.wantErr != nil {
+				if !errors.Is(err, tc.wantErr) {
+					t.Errorf("Encode() error = %v, want %v", err, tc.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Encode() unexpected error: %v", err)
+			}
+			if got != tc.want {
+				t.Errorf("Encode() = %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestEncodeArrays(t *testing.T) {
+	tests := []struct {
+		name      string
+		input     string
+		dataTypes string
+		want      string
+		wantErr   error
+	}{
+		{
+			name:      "dynamic uint array",
+			input:     "[1,2,3]",
+			dataTypes: "uint256[]",
+			want:      "0x00000000000000000000000000000000000000000000000000000000000000200000000000000000000000000000000000000000000000000000000000000003000000000000000000000000000000000000000000000000000000000000000100000000000000000000000000000000000000000000000000000000000000020000000000000000000000000000000000000000000000000000000000000003",
+		},
+		{
+			name:      "fixed address array",
+			input:     "[0x208AA722Aca42399eaC5192EE778e4D42f4E5De3, 0x0000000000000000000000000000000000000001]",
+			dataTypes: "address[2]",
+			want:      "0x000000000000000000000000208aa722aca42399eac5192ee778e4d42f4e5de30000000000000000000000000000000000000000000000000000000000000001",
+		},
+		{
+			name:      "string array with quoted strings",
+			input:     `["a,b", " c"]`,
+			dataTypes: "string[]",
+			want:      "0x00000000000000000000000000000000000000000000000000000000000000200000000000000000000000000000000000000000000000000000000000000002000000000000000000000000000000000000000000000000000000000000004000000000000000000000000000000000000000000000000000000000000000800000000000000000000000000000000000000000000000000000000000000003612c62000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000022063000000000000000000000000000000000000000000000000000000000000",
+		},
+		{
+			name:      "nested dynamic arrays",
+			input:     "[[1,2],[3]]",
+			dataTypes: "uint8[][]",
+			want:      "0x00000000000000000000000000000000000000000000000000000000000000200000000000000000000000000000000000000000000000000000000000000002000000000000000000000000000000000000000000000000000000000000004000000000000000000000000000000000000000000000000000000000000000a000000000000000000000000000000000000000000000000000000000000000020000000000000000000000000000000000000000000000000000000000000001000000000000000000000000000000000000000000000000000000000000000200000000000000000000000000000000000000000000000000000000000000010000000000000000000000000000000000000000000000000000000000000003",
+		},
+		{
+			name:      "array of tuples",
+			input:     "[(0x208AA722Aca42399eaC5192EE778e4D42f4E5De3, 1), (0x208AA722Aca42399eaC5192EE778e4D42f4E5De3, 2)]",
+			dataTypes: "(address to, uint256 amount)[]",
+			want:      "0x00000000000000000000000000000000000000000000000000000000000000200000000000000000000000000000000000000000000000000000000000000002000000000000000000000000208aa722aca42399eac5192ee778e4d42f4e5de30000000000000000000000000000000000000000000000000000000000000001000000000000000000000000208aa722aca42399eac5192ee778e4d42f4e5de30000000000000000000000000000000000000000000000000000000000000002",
+		},
+		{
+			name:      "fixed array followed by a string",
+			input:     "[7, 8], hi",
+			dataTypes: "uint16[2], string",
+			want:      "0x00000000000000000000000000000000000000000000000000000000000000070000000000000000000000000000000000000000000000000000000000000008000000000000000000000000000000000000000000000000000000000000006000000000000000000000000000000000000000000000000000000000000000026869000000000000000000000000000000000000000000000000000000000000",
+		},
+		{
+			name:      "empty dynamic array",
+			input:     "[]",
+			dataTypes: "uint256[]",
+			want:      "0x00000000000000000000000000000000000000000000000000000000000000200000000000000000000000000000000000000000000000000000000000000000",
+		},
+		{
+			name:      "fixed array with wrong length",
+			input:     "[1,2,3]",
+			dataTypes: "uint8[2]",
+			wantErr:   ErrTypeMismatch,
+		},
+		{
+			name:      "array value without brackets",
+			input:     "1",
+			dataTypes: "uint8[]",
+			wantErr:   ErrTypeMismatch,
+		},
+		{
+			name:      "list given for a scalar type",
+			input:     "[1]",
+			dataTypes: "uint8",
+			wantErr:   ErrTypeMismatch,
+		},
+		{
+			name:      "unterminated array",
+			input:     "[1,2",
+			dataTypes: "uint8[]",
+			wantErr:   ErrTypeMismatch,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := Encode(tc.input, tc.dataTypes)
+			if tc.wantErr != nil {
+				if !errors.Is(err, tc.wantErr) {
+					t.Errorf("Encode() error = %v, want %v", err, tc.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Encode() unexpected error: %v", err)
+			}
+			if got != tc.want {
+				t.Errorf("Encode() = %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestEncodeElementaryTypes(t *testing.T) {
+	tests := []struct {
+		name      string
+		input     string
+		dataTypes string
+		want      string
+		wantErr   string // substring of the returned error
+	}{
+		{
+			name:      "bools",
+			input:     "true, false",
+			dataTypes: "bool, bool",
+			want:      "0x00000000000000000000000000000000000000000000000000000000000000010000000000000000000000000000000000000000000000000000000000000000",
+		},
+		{
+			name:      "bytes4",
+			input:     "0xa9059cbb",
+			dataTypes: "bytes4",
+			want:      "0xa9059cbb00000000000000000000000000000000000000000000000000000000",
+		},
+		{
+			name:      "bytes32",
+			input:     "0x1111111111111111111111111111111111111111111111111111111111111111",
+			dataTypes: "bytes32",
+			want:      "0x1111111111111111111111111111111111111111111111111111111111111111",
+		},
+		{
+			name:      "dynamic bytes",
+			input:     "0x1234",
+			dataTypes: "bytes",
+			want:      "0x000000000000000000000000000000000000000000000000000000000000002000000000000000000000000000000000000000000000000000000000000000021234000000000000000000000000000000000000000000000000000000000000",
+		},
+		{
+			name:      "function - address and selector",
+			input:     "0x208aa722aca42399eac5192ee778e4d42f4e5de3a9059cbb",
+			dataTypes: "function",
+			want:      "0x208aa722aca42399eac5192ee778e4d42f4e5de3a9059cbb0000000000000000",
+		},
+		{
+			name:      "intermediate widths, decimal and hex",
+			input:     "16777215, -1, 0xff, -128",
+			dataTypes: "uint24, int40, uint160, int8",
+			want:      "0x0000000000000000000000000000000000000000000000000000000000ffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffff00000000000000000000000000000000000000000000000000000000000000ffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffff80",
+		},
+		{
+			name:      "uint24 overflow names the argument",
+			input:     "1, 16777216",
+			dataTypes: "uint8, uint24 fee",
+			wantErr:   "argument fee: value 16777216 overflows uint24",
+		},
+		{
+			name:      "negative uint",
+			input:     "-1",
+			dataTypes: "uint256",
+			wantErr:   "argument arg0: value -1 overflows uint256",
+		},
+		{
+			name:      "int8 underflow inside a tuple",
+			input:     "(1, -129)",
+			dataTypes: "(int8 a, int8 b)",
+			wantErr:   "argument arg0.b: value -129 overflows int8",
+		},
+		{
+			name:      "bytes32 with wrong length",
+			input:     "0x1234",
+			dataTypes: "bytes32",
+			wantErr:   "needs exactly 32 bytes",
+		},
+		{
+			name:      "bytes without 0x prefix",
+			input:     "1234",
+			dataTypes: "bytes",
+			wantErr:   "must be 0x-prefixed hex",
+		},
+		{
+			name:      "invalid bool",
+			input:     "yes",
+			dataTypes: "bool",
+			wantErr:   "is not a valid bool",
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := Encode(tc.input, tc.dataTypes)
+			if tc.wantErr != "" {
+				if !errors.Is(err, ErrTypeMismatch) || !strings.Contains(err.Error(), tc.wantErr) {
+					t.Errorf("Encode() error = %v, want it to contain %q", err, tc.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Encode() unexpected error: %v", err)
+			}
+			if got != tc.want {
+				t.Errorf("Encode() = %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,14 @@
+package encdec
+
+import "errors"
+
+// Sentinel errors returned by the error-returning API in this package.
+// Callers can match them with errors.Is.
+var (
+	// ErrInvalidHex is returned when an input is not a valid hex string.
+	ErrInvalidHex = errors.New("invalid hex input")
+
+	// ErrTypeMismatch is returned when a data type is not supported, or when input
+	// values cannot be converted to (or do not line up with) the data types given.
+	ErrTypeMismatch = errors.New("type mismatch")
+)
@@ -0,0 +1,75 @@
+package encdec
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+// FormatValues formats values decoded as per `args` into a space-separated list
+// wrapped in square brackets, eg: "[1001 zubin (to: 0x208A..., amount: 5)]".
+func FormatValues(args abi.Arguments, values []any) string {
+	formatted := make([]string, len(values))
+	for idx, val := range values {
+		formatted[idx] = FormatValue(args[idx].Type, val)
+	}
+	return "[" + strings.Join(formatted, " ") + "]"
+}
+
+// FormatValue formats a single value decoded as per `abiType`. Tuples are printed in
+// parentheses with their field names, when the type provided them, and arrays in
+// square brackets, so the output can be passed back to Encode as input values.
+func FormatValue(abiType abi.Type, value any) string {
+	return formatValue(abiType, reflect.ValueOf(value), false)
+}
+
+func formatValue(abiType abi.Type, value reflect.Value, nested bool) string {
+	switch abiType.T {
+	case abi.TupleTy:
+		named := hasFieldNames(abiType)
+		fields := make([]string, len(abiType.TupleElems))
+		for idx, elemType := range abiType.TupleElems {
+			fields[idx] = formatValue(*elemType, value.Field(idx), true)
+			if named {
+				fields[idx] = abiType.TupleRawNames[idx] + ": " + fields[idx]
+			}
+		}
+		return "(" + strings.Join(fields, ", ") + ")"
+	case abi.SliceTy, abi.ArrayTy:
+		elems := make([]string, value.Len())
+		for idx := range elems {
+			elems[idx] = formatValue(*abiType.Elem, value.Index(idx), true)
+		}
+		return "[" + strings.Join(elems, ", ") + "]"
+	case abi.AddressTy:
+		return value.Interface().(common.Address).Hex()
+	case abi.BytesTy:
+		return hexutil.Encode(value.Bytes())
+	case abi.FixedBytesTy, abi.FunctionTy:
+		b := make([]byte, value.Len())
+		reflect.Copy(reflect.ValueOf(b), value)
+		return hexutil.Encode(b)
+	case abi.StringTy:
+		if nested {
+			return strconv.Quote(value.String())
+		}
+		return value.String()
+	default:
+		return fmt.Sprint(value.Interface())
+	}
+}
+
+// Reports whether the tuple's field names were provided, rather than generated by ParseTypes.
+func hasFieldNames(tupleType abi.Type) bool {
+	for idx, name := range tupleType.TupleRawNames {
+		if name != unnamedFieldPrefix+strconv.Itoa(idx) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,141 @@
+package encdec
+
+import (
+	"fmt"
+	"reflect"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+/*
+  - Encodes the comma-separated `inputValuesStr` as per `dataTypesStr` the way Solidity's
+    abi.encodePacked does, eg: for role ids, signatures and Merkle leaves. Values are parsed
+    as Encode parses them.
+  - Each value takes the fewest bytes its type needs, with no padding and no length: uintN and
+    intN take N/8 bytes, address 20, bool 1, bytesN N, and string and bytes their raw bytes.
+  - The elements of arrays are padded to 32 bytes, as in the standard encoding, and arrays have
+    no length, eg: "[1,2]" packs to 64 bytes for "uint8[]". Structs, nested arrays and arrays of
+    string or bytes cannot be packed, as Solidity does not pack them.
+*/
+func EncodePacked(inputValuesStr string, dataTypesStr string) (string, error) {
+	if len(inputValuesStr) == 0 {
+		return "0x", nil
+	}
+
+	args, err := ParseTypes(dataTypesStr)
+	if err != nil {
+		return "", err
+	}
+
+	packed, err := EncodePackedArguments(inputValuesStr, args)
+	if err != nil {
+		return "", err
+	}
+
+	return hexutil.Encode(packed), nil
+}
+
+/*
+  - Packs the comma-separated `inputValuesStr` as per `args`, eg: the arguments returned by
+    ParseTypes, the way EncodePacked does.
+*/
+func EncodePackedArguments(inputValuesStr string, args abi.Arguments) ([]byte, error) {
+	inputValuesSlice, err := parseValues(inputValuesStr)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrTypeMismatch, err)
+	}
+	if len(inputValuesSlice) != len(args) {
+		return nil, fmt.Errorf("%w: Number of input values does not match number of types - %d inputs to  %d types", ErrTypeMismatch, len(inputValuesSlice), len(args))
+	}
+
+	var packed []byte
+	for idx, arg := range args {
+		b, err := packValue(inputValuesSlice[idx], arg.Type, arg.Name)
+		if err != nil {
+			return nil, err
+		}
+		packed = append(packed, b...)
+	}
+	return packed, nil
+}
+
+// Packs a single value. Elementary values take the fewest bytes their type needs, while
+// the elements of arrays are padded to 32 bytes, as Solidity packs them.
+func packValue(inpValue valueNode, abiType abi.Type, label string) ([]byte, error) {
+	switch abiType.T {
+	case abi.TupleTy:
+		return nil, fmt.Errorf("%w: argument %s: type %s cannot be packed, Solidity does not pack structs", ErrTypeMismatch, label, abiType.String())
+	case abi.SliceTy, abi.ArrayTy:
+		return packArray(inpValue, abiType, label)
+	}
+
+	typedValue, err := convertValue(inpValue, abiType, label)
+	if err != nil {
+		return nil, err
+	}
+
+	switch abiType.T {
+	case abi.StringTy:
+		return []byte(typedValue.String()), nil
+	case abi.BytesTy:
+		return typedValue.Bytes(), nil
+	}
+
+	word, err := packWord(typedValue, abiType, label)
+	if err != nil {
+		return nil, err
+	}
+	size := packedSize(abiType)
+	if abiType.T == abi.FixedBytesTy || abiType.T == abi.FunctionTy {
+		return word[:size], nil
+	}
+	return word[len(word)-size:], nil
+}
+
+// Packs the elements of an array one after the other, each padded to 32 bytes, with no
+// length. Solidity only packs arrays of static elementary types, eg: not string[] or uint[][].
+func packArray(inpValue valueNode, abiType abi.Type, label string) ([]byte, error) {
+	switch abiType.Elem.T {
+	case abi.TupleTy, abi.SliceTy, abi.ArrayTy, abi.StringTy, abi.BytesTy:
+		return nil, fmt.Errorf("%w: argument %s: type %s cannot be packed, Solidity only packs arrays of static elementary types", ErrTypeMismatch, label, abiType.String())
+	}
+
+	typedValue, err := convertValue(inpValue, abiType, label)
+	if err != nil {
+		return nil, err
+	}
+
+	var packed []byte
+	for idx := 0; idx < typedValue.Len(); idx++ {
+		word, err := packWord(typedValue.Index(idx), *abiType.Elem, fmt.Sprintf("%s[%d]", label, idx))
+		if err != nil {
+			return nil, err
+		}
+		packed = append(packed, word...)
+	}
+	return packed, nil
+}
+
+// Returns the standard encoding of a value of a static elementary type: its packed encoding,
+// padded to 32 bytes on the left for numbers, addresses and bools, and on the right for bytesN.
+func packWord(typedValue reflect.Value, abiType abi.Type, label string) ([]byte, error) {
+	word, err := abi.Arguments{{Type: abiType}}.Pack(typedValue.Interface())
+	if err != nil {
+		return nil, fmt.Errorf("%w: argument %s: %v", ErrTypeMismatch, label, err)
+	}
+	return word, nil
+}
+
+// Returns the number of bytes a value of the static elementary type `abiType` takes when packed.
+func packedSize(abiType abi.Type) int {
+	switch abiType.T {
+	case abi.UintTy, abi.IntTy:
+		return abiType.Size / 8
+	case abi.AddressTy:
+		return 20
+	case abi.BoolTy:
+		return 1
+	}
+	return abiType.Size
+}
@@ -0,0 +1,136 @@
+package encdec
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+func TestEncodePacked(t *testing.T) {
+	tests := []struct {
+		name      string
+		input     string
+		dataTypes string
+		want      string
+		wantErr   string
+	}{
+		{
+			// the example of the Solidity docs, "Non-standard Packed Mode".
+			name:      "solidity docs example",
+			input:     `-1, 0x42, 0x03, "Hello, world!"`,
+			dataTypes: "int16, bytes1, uint16, string",
+			want:      "0xffff42000348656c6c6f2c20776f726c6421",
+		},
+		{
+			name:      "address and uint256",
+			input:     "0x208AA722Aca42399eaC5192EE778e4D42f4E5De3, 100",
+			dataTypes: "address, uint",
+			want:      "0x208aa722aca42399eac5192ee778e4d42f4e5de30000000000000000000000000000000000000000000000000000000000000064",
+		},
+		{
+			name:      "bools, bytes and negative ints",
+			input:     "true, false, 0xdeadbeef, -2",
+			dataTypes: "bool, bool, bytes, int40",
+			want:      "0x0100deadbeeffffffffffe",
+		},
+		{
+			name:      "array elements are padded",
+			input:     "[1, 2], 3",
+			dataTypes: "uint8[], uint8",
+			want:      "0x0000000000000000000000000000000000000000000000000000000000000001000000000000000000000000000000000000000000000000000000000000000203",
+		},
+		{
+			name:      "fixed arrays of addresses, bools and bytesN",
+			input:     "[0x208AA722Aca42399eaC5192EE778e4D42f4E5De3], [true, false], [0xabcd]",
+			dataTypes: "address[1], bool[2], bytes2[]",
+			want:      "0x000000000000000000000000208aa722aca42399eac5192ee778e4d42f4e5de300000000000000000000000000000000000000000000000000000000000000010000000000000000000000000000000000000000000000000000000000000000abcd000000000000000000000000000000000000000000000000000000000000",
+		},
+		{
+			name:      "empty array",
+			input:     "[], 0x01",
+			dataTypes: "uint256[], bytes1",
+			want:      "0x01",
+		},
+		{
+			name:      "empty input",
+			dataTypes: "uint256",
+			want:      "0x",
+		},
+		{
+			name:      "tuples cannot be packed",
+			input:     "(1, 2)",
+			dataTypes: "(uint8, uint8)",
+			wantErr:   "Solidity does not pack structs",
+		},
+		{
+			name:      "nested arrays cannot be packed",
+			input:     "[[1], [2]]",
+			dataTypes: "uint8[][]",
+			wantErr:   "only packs arrays of static elementary types",
+		},
+		{
+			name:      "arrays of strings cannot be packed",
+			input:     "[a, b]",
+			dataTypes: "string[]",
+			wantErr:   "only packs arrays of static elementary types",
+		},
+		{
+			name:      "array element overflow",
+			input:     "[1, 256]",
+			dataTypes: "uint8[]",
+			wantErr:   "overflows uint8",
+		},
+		{
+			name:      "overflow",
+			input:     "256",
+			dataTypes: "uint8",
+			wantErr:   "overflows uint8",
+		},
+		{
+			name:      "mismatched number of values",
+			input:     "1, 2",
+			dataTypes: "uint8",
+			wantErr:   "Number of input values does not match number of types",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := EncodePacked(tc.input, tc.dataTypes)
+			if tc.wantErr != "" {
+				if !errors.Is(err, ErrTypeMismatch) || !strings.Contains(err.Error(), tc.wantErr) {
+					t.Errorf("EncodePacked() error = %v, want %v containing %q", err, ErrTypeMismatch, tc.wantErr)
+				}
+				return
+			}
+			if err != nil || got != tc.want {
+				t.Errorf("EncodePacked(%q, %q) = %s, %v, want %s", tc.input, tc.dataTypes, got, err, tc.want)
+			}
+		})
+	}
+}
+
+// Computes the address of Uniswap V2's USDC/WETH pair, which the factory deploys with CREATE2:
+// keccak256(abi.encodePacked(hex"ff", factory, keccak256(abi.encodePacked(token0, token1)), initCodeHash)).
+func TestEncodePackedCreate2Address(t *testing.T) {
+	keccakPacked := func(input, dataTypes string) common.Hash {
+		packed, err := EncodePacked(input, dataTypes)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return crypto.Keccak256Hash(common.FromHex(packed))
+	}
+
+	salt := keccakPacked("0xA0b86991c6218b36c1d19D4a2e9Eb0cE3606eB48, 0xC02aaA39b223FE8D0A0e5C4F27eAD9083C756Cc2", "address, address")
+	hash := keccakPacked(
+		"0xff, 0x5C69bEe701ef814a2B6a3EDD4B1652CB9cc5aA6f, "+salt.Hex()+", 0x96e8ac4277198ff8b6f785478aa9a39f403cb768dd02cbee326c3e7da348845f",
+		"bytes1, address, bytes32, bytes32",
+	)
+	want := common.HexToAddress("0xB4e16d0168e52d35CaCD2c6185b44281Ec28C9Dc")
+	if got := common.BytesToAddress(hash[12:]); got != want {
+		t.Errorf("CREATE2 address = %s, want %s", got, want)
+	}
+}
@@ -0,0 +1,186 @@
+package encdec
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/zeuslawyer/hextool/internal/typescan"
+)
+
+// Tuple components that are not given a name in a type string are named
+// `field0`, `field1`, ... so that go-ethereum can build a struct for them.
+const unnamedFieldPrefix = "field"
+
+var (
+	// matches an elementary type, eg: "uint256", "bytes32", "address", with optional array suffixes.
+	elementaryTypeRegex = regexp.MustCompile(`^([a-z]+)([0-9]*)((?:` + typescan.ArraySuffix + `)*)$`)
+	// matches the array suffixes that may follow a tuple, eg: "[]" or "[2][]".
+	arraySuffixRegex = regexp.MustCompile(`^((?:` + typescan.ArraySuffix + `)*)`)
+	// matches a parameter name, as Solidity allows them, eg: "_to".
+	paramNameRegex = regexp.MustCompile(`^[a-zA-Z_$][a-zA-Z0-9_$]*$`)
+)
+
+// ParseTypes parses a comma-separated list of Solidity types into abi.Arguments.
+// Tuples may be written as `(type1,type2)` or `tuple(type1,type2)`, nested to any depth,
+// and followed by array suffixes, eg: "uint256, (address to, uint256 amount)[]".
+// Each type may be followed by a parameter name, which is used as the argument or
+// tuple field name. `uint` and `int` are canonicalized to `uint256` and `int256`.
+func ParseTypes(dataTypes string) (abi.Arguments, error) {
+	marshalings, err := parseTypeList(dataTypes)
+	if err != nil {
+		return nil, err
+	}
+
+	abiArgs := make(abi.Arguments, len(marshalings))
+	for idx, m := range marshalings {
+		abiType, err := abi.NewType(m.Type, "", m.Components)
+		if err != nil {
+			return nil, fmt.Errorf("%w: Unsupported type %q: %v", ErrTypeMismatch, m.Type, err)
+		}
+		name := m.Name
+		if name == "" {
+			name = fmt.Sprintf("arg%d", idx)
+		}
+		abiArgs[idx] = abi.Argument{
+			Name:    name,
+			Type:    abiType,
+			Indexed: false,
+		}
+	}
+
+	return abiArgs, nil
+}
+
+// ParseTypeList parses a comma-separated list of Solidity types, as ParseTypes does, into
+// their JSON ABI form. Unlike ParseTypes, it leaves unnamed arguments unnamed.
+func ParseTypeList(dataTypes string) ([]abi.ArgumentMarshaling, error) {
+	return parseTypeList(dataTypes)
+}
+
+// Parses each top-level comma-separated type in `typeList`.
+func parseTypeList(typeList string) ([]abi.ArgumentMarshaling, error) {
+	if strings.TrimSpace(typeList) == "" {
+		return []abi.ArgumentMarshaling{}, nil
+	}
+
+	parts, err := typescan.SplitTopLevel(typeList)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrTypeMismatch, err)
+	}
+
+	marshalings := make([]abi.ArgumentMarshaling, len(parts))
+	for idx, part := range parts {
+		m, err := parseType(strings.TrimSpace(part))
+		if err != nil {
+			return nil, err
+		}
+		marshalings[idx] = m
+	}
+	return marshalings, nil
+}
+
+// Parses a single, possibly named, type such as "uint256", "address[2] to",
+// "(uint8,bytes32)[]" or "tuple(address to, uint256 amount) transfer".
+func parseType(typeStr string) (abi.ArgumentMarshaling, error) {
+	if typeStr == "" {
+		return abi.ArgumentMarshaling{}, fmt.Errorf("%w: Unsupported type %q: empty type", ErrTypeMismatch, typeStr)
+	}
+
+	if strings.HasPrefix(typeStr, "(") || strings.HasPrefix(typeStr, "tuple(") {
+		return parseTupleType(typeStr)
+	}
+
+	fields := strings.Fields(typeStr)
+	if len(fields) > 2 {
+		return abi.ArgumentMarshaling{}, fmt.Errorf("%w: Unsupported type %q", ErrTypeMismatch, typeStr)
+	}
+
+	typeName, err := canonicalElementaryType(fields[0])
+	if err != nil {
+		return abi.ArgumentMarshaling{}, err
+	}
+
+	m := abi.ArgumentMarshaling{Type: typeName}
+	if len(fields) == 2 {
+		if m.Name, err = paramName(fields[1], typeStr); err != nil {
+			return abi.ArgumentMarshaling{}, err
+		}
+	}
+	return m, nil
+}
+
+// Parses a tuple type with its components, array suffixes and name.
+func parseTupleType(typeStr string) (abi.ArgumentMarshaling, error) {
+	open := strings.Index(typeStr, "(")
+	closing, err := typescan.MatchingParen(typeStr, open)
+	if err != nil {
+		return abi.ArgumentMarshaling{}, fmt.Errorf("%w: Unsupported type %q: %v", ErrTypeMismatch, typeStr, err)
+	}
+
+	components, err := parseTypeList(typeStr[open+1 : closing])
+	if err != nil {
+		return abi.ArgumentMarshaling{}, err
+	}
+	for idx := range components {
+		if components[idx].Name == "" {
+			components[idx].Name = unnamedFieldPrefix + strconv.Itoa(idx)
+		}
+	}
+
+	rest := typeStr[closing+1:]
+	suffix := arraySuffixRegex.FindString(rest)
+
+	m := abi.ArgumentMarshaling{Type: "tuple" + suffix, Components: components}
+	if name := strings.TrimSpace(rest[len(suffix):]); name != "" {
+		if m.Name, err = paramName(name, typeStr); err != nil {
+			return abi.ArgumentMarshaling{}, err
+		}
+	}
+	return m, nil
+}
+
+// Validates an elementary type (with optional array suffixes) and returns its canonical form.
+func canonicalElementaryType(typeName string) (string, error) {
+	matches := elementaryTypeRegex.FindStringSubmatch(typeName)
+	if matches == nil {
+		return "", fmt.Errorf("%w: Unsupported type %q", ErrTypeMismatch, typeName)
+	}
+	base, size, suffix := matches[1], matches[2], matches[3]
+
+	switch base {
+	case "uint", "int":
+		if size == "" {
+			// uints throw an error when creating an Abi.Type, so convert them to uint256.
+			size = "256"
+		}
+		bits, _ := strconv.Atoi(size)
+		if bits < 8 || bits > 256 || bits%8 != 0 {
+			return "", fmt.Errorf("%w: Unsupported type %q: bit size must be a multiple of 8 between 8 and 256", ErrTypeMismatch, typeName)
+		}
+	case "bytes":
+		if size != "" {
+			n, _ := strconv.Atoi(size)
+			if n < 1 || n > 32 {
+				return "", fmt.Errorf("%w: Unsupported type %q: fixed bytes size must be between 1 and 32", ErrTypeMismatch, typeName)
+			}
+		}
+	case "address", "bool", "string", "function":
+		if size != "" {
+			return "", fmt.Errorf("%w: Unsupported type %q", ErrTypeMismatch, typeName)
+		}
+	default:
+		return "", fmt.Errorf("%w: Unsupported type %q", ErrTypeMismatch, typeName)
+	}
+
+	return base + size + suffix, nil
+}
+
+func paramName(name string, typeStr string) (string, error) {
+	if !paramNameRegex.MatchString(name) {
+		return "", fmt.Errorf("%w: invalid parameter name %q in %q", ErrTypeMismatch, name, typeStr)
+	}
+	return name, nil
+}
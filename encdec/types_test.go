@@ -0,0 +1,98 @@
+package encdec
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestParseTypes(t *testing.T) {
+	tests := []struct {
+		name      string
+		dataTypes string
+		want      []string // canonical type of each argument
+		wantNames []string
+		wantErr   error
+	}{
+		{
+			name:      "scalars - uint and int canonicalized",
+			dataTypes: "uint, int, address, string",
+			want:      []string{"uint256", "int256", "address", "string"},
+			wantNames: []string{"arg0", "arg1", "arg2", "arg3"},
+		},
+		{
+			name:      "named arguments",
+			dataTypes: "address to, uint amount",
+			want:      []string{"address", "uint256"},
+			wantNames: []string{"to", "amount"},
+		},
+		{
+			name:      "tuple in parentheses",
+			dataTypes: "(address,uint256)",
+			want:      []string{"(address,uint256)"},
+			wantNames: []string{"arg0"},
+		},
+		{
+			name:      "tuple keyword with array suffix",
+			dataTypes: "tuple(uint8,bytes32)[]",
+			want:      []string{"(uint8,bytes32)[]"},
+			wantNames: []string{"arg0"},
+		},
+		{
+			name:      "named nested tuples",
+			dataTypes: "uint16 id, ((address to, uint amount)[2] transfers, string memo) batch",
+			want:      []string{"uint16", "((address,uint256)[2],string)"},
+			wantNames: []string{"id", "batch"},
+		},
+		{
+			name:      "uniswap ExactInputParams",
+			dataTypes: "(bytes path, address recipient, uint256 deadline, uint256 amountIn, uint256 amountOutMinimum) params",
+			want:      []string{"(bytes,address,uint256,uint256,uint256)"},
+			wantNames: []string{"params"},
+		},
+		{
+			name:      "unsupported bit size",
+			dataTypes: "uint999",
+			wantErr:   ErrTypeMismatch,
+		},
+		{
+			name:      "unknown type",
+			dataTypes: "hextool",
+			wantErr:   ErrTypeMismatch,
+		},
+		{
+			name:      "unbalanced parentheses",
+			dataTypes: "(address,uint256",
+			wantErr:   ErrTypeMismatch,
+		},
+		{
+			name:      "invalid name",
+			dataTypes: "(address 2to)",
+			wantErr:   ErrTypeMismatch,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := ParseTypes(tc.dataTypes)
+			if tc.wantErr != nil {
+				if !errors.Is(err, tc.wantErr) {
+					t.Errorf("ParseTypes() error = %v, want %v", err, tc.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseTypes() unexpected error: %v", err)
+			}
+			if len(got) != len(tc.want) {
+				t.Fatalf("ParseTypes() returned %d arguments, want %d", len(got), len(tc.want))
+			}
+			for idx, arg := range got {
+				if arg.Type.String() != tc.want[idx] {
+					t.Errorf("argument %d type = %s, want %s", idx, arg.Type.String(), tc.want[idx])
+				}
+				if arg.Name != tc.wantNames[idx] {
+					t.Errorf("argument %d name = %s, want %s", idx, arg.Name, tc.wantNames[idx])
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,146 @@
+package encdec
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// A parsed input value: either a scalar, or a list of values wrapped in
+// square brackets (arrays) or parentheses (tuples).
+type valueNode struct {
+	text   string      // the scalar value, without surrounding quotes.
+	isList bool        // true when the value is a bracketed list.
+	open   byte        // the bracket that opened the list: '[' or '('.
+	items  []valueNode // the elements of the list.
+}
+
+// Describes the value for use in error messages.
+func (n valueNode) String() string {
+	if !n.isList {
+		return strconv.Quote(n.text)
+	}
+	items := make([]string, len(n.items))
+	for idx, item := range n.items {
+		items[idx] = item.String()
+	}
+	closing := "]"
+	if n.open == '(' {
+		closing = ")"
+	}
+	return string(n.open) + strings.Join(items, ",") + closing
+}
+
+// Parses a comma-separated list of input values. The grammar is JSON-like:
+//   - arrays are wrapped in square brackets and may be nested, eg: "[[1,2],[3]]".
+//   - tuples are wrapped in parentheses (or square brackets), eg: "(0x208A..., 5)".
+//   - strings may be wrapped in double quotes, so they can contain commas, brackets or
+//     leading and trailing spaces, eg: "\"hello, world\"". Go escape sequences are supported.
+//   - any other value runs up to the next comma or closing bracket, with surrounding spaces trimmed.
+func parseValues(input string) ([]valueNode, error) {
+	p := &valueParser{input: input}
+	if strings.TrimSpace(input) == "" {
+		return []valueNode{}, nil
+	}
+
+	values, err := p.parseList(0)
+	if err != nil {
+		return nil, err
+	}
+	if p.pos < len(p.input) {
+		return nil, fmt.Errorf("unexpected %q at position %d in %q", p.input[p.pos], p.pos, p.input)
+	}
+	return values, nil
+}
+
+type valueParser struct {
+	input string
+	pos   int
+}
+
+// Parses comma-separated values until `closing` (or the end of input when `closing` is 0).
+func (p *valueParser) parseList(closing byte) ([]valueNode, error) {
+	values := []valueNode{}
+
+	p.skipSpaces()
+	if closing != 0 && p.peek() == closing {
+		return values, nil // empty list.
+	}
+
+	for {
+		value, err := p.parseValue()
+		if err != nil {
+			return nil, err
+		}
+		values = append(values, value)
+
+		p.skipSpaces()
+		if p.peek() != ',' {
+			return values, nil
+		}
+		p.pos++
+	}
+}
+
+func (p *valueParser) parseValue() (valueNode, error) {
+	p.skipSpaces()
+
+	switch c := p.peek(); c {
+	case '[', '(':
+		closing := byte(']')
+		if c == '(' {
+			closing = ')'
+		}
+		start := p.pos
+		p.pos++
+		items, err := p.parseList(closing)
+		if err != nil {
+			return valueNode{}, err
+		}
+		if p.peek() != closing {
+			return valueNode{}, fmt.Errorf("missing %q to close the list at position %d in %q", closing, start, p.input)
+		}
+		p.pos++
+		return valueNode{isList: true, open: c, items: items}, nil
+	case '"':
+		return p.parseQuoted()
+	default:
+		start := p.pos
+		for p.pos < len(p.input) && !strings.ContainsRune(",])", rune(p.input[p.pos])) {
+			p.pos++
+		}
+		return valueNode{text: strings.TrimSpace(p.input[start:p.pos])}, nil
+	}
+}
+
+// Parses a double-quoted string, unescaping it.
+func (p *valueParser) parseQuoted() (valueNode, error) {
+	start := p.pos
+	for p.pos++; p.pos < len(p.input); p.pos++ {
+		switch p.input[p.pos] {
+		case '\\':
+			p.pos++ // skip the escaped character.
+		case '"':
+			p.pos++
+			text, err := strconv.Unquote(p.input[start:p.pos])
+			if err != nil {
+				return valueNode{}, fmt.Errorf("invalid quoted string %s: %v", p.input[start:p.pos], err)
+			}
+			return valueNode{text: text}, nil
+		}
+	}
+	return valueNode{}, fmt.Errorf("unterminated quoted string at position %d in %q", start, p.input)
+}
+
+func (p *valueParser) peek() byte {
+	if p.pos >= len(p.input) {
+		return 0
+	}
+	return p.input[p.pos]
+}
+
+func (p *valueParser) skipSpaces() {
+	for p.pos < len(p.input) && (p.input[p.pos] == ' ' || p.input[p.pos] == '\t' || p.input[p.pos] == '\n') {
+		p.pos++
+	}
+}
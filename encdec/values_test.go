@@ -0,0 +1,77 @@
+package encdec
+
+import (
+	"testing"
+)
+
+func TestParseValues(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		want    []string // String() of each parsed value
+		wantErr bool
+	}{
+		{
+			name:  "scalars are trimmed",
+			input: " 1, hextool is rad ,0x208AA722Aca42399eaC5192EE778e4D42f4E5De3",
+			want:  []string{`"1"`, `"hextool is rad"`, `"0x208AA722Aca42399eaC5192EE778e4D42f4E5De3"`},
+		},
+		{
+			name:  "quoted strings keep commas, brackets and spaces",
+			input: `"a, b", " (c) ", "say \"hi\""`,
+			want:  []string{`"a, b"`, `" (c) "`, `"say \"hi\""`},
+		},
+		{
+			name:  "nested arrays",
+			input: "[[1, 2], [], [3]]",
+			want:  []string{`[["1","2"],[],["3"]]`},
+		},
+		{
+			name:  "tuples and arrays",
+			input: `7, (0x01, ["x,y", z])`,
+			want:  []string{`"7"`, `("0x01",["x,y","z"])`},
+		},
+		{
+			name:  "empty input",
+			input: "  ",
+			want:  []string{},
+		},
+		{
+			name:    "unterminated list",
+			input:   "[1, 2",
+			wantErr: true,
+		},
+		{
+			name:    "unterminated quote",
+			input:   `"hextool`,
+			wantErr: true,
+		},
+		{
+			name:    "stray closing bracket",
+			input:   "1, 2]",
+			wantErr: true,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := parseValues(tc.input)
+			if tc.wantErr {
+				if err == nil {
+					t.Errorf("parseValues(%q) expected an error, got %v", tc.input, got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("parseValues(%q) unexpected error: %v", tc.input, err)
+			}
+			if len(got) != len(tc.want) {
+				t.Fatalf("parseValues(%q) returned %d values, want %d", tc.input, len(got), len(tc.want))
+			}
+			for idx, value := range got {
+				if value.String() != tc.want[idx] {
+					t.Errorf("value %d = %s, want %s", idx, value.String(), tc.want[idx])
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,183 @@
+package eventlog
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/zeuslawyer/hextool/encdec"
+	"github.com/zeuslawyer/hextool/selector"
+)
+
+// Field is a single named parameter decoded from an event log.
+type Field struct {
+	Name    string
+	Type    abi.Type
+	Value   any
+	Indexed bool // true when the value was decoded from a topic rather than the log data.
+	Hashed  bool // true when Value is the keccak256 hash of an indexed dynamic value, not the value itself.
+}
+
+// Log is an event log decoded as per the event it was emitted by.
+type Log struct {
+	Event  *abi.Event
+	Fields []Field
+}
+
+// Formats the log as its event signature followed by one `name: value` line per field, eg:
+//
+//	Transfer(address,address,uint256)
+//	  from (indexed): 0x208AA722Aca42399eaC5192EE778e4D42f4E5De3
+//	  to (indexed): 0x0000000000000000000000000000000000000001
+//	  value: 1000
+func (l *Log) String() string {
+	var sb strings.Builder
+	sb.WriteString(l.Event.Sig)
+	if l.Event.Anonymous {
+		sb.WriteString(" anonymous")
+	}
+	for _, field := range l.Fields {
+		value := encdec.FormatValue(field.Type, field.Value)
+		switch {
+		case field.Hashed:
+			fmt.Fprintf(&sb, "\n  %s (indexed, keccak256 of %s): %s", field.Name, field.Type.String(), field.Value.(common.Hash).Hex())
+		case field.Indexed:
+			fmt.Fprintf(&sb, "\n  %s (indexed): %s", field.Name, value)
+		default:
+			fmt.Fprintf(&sb, "\n  %s: %s", field.Name, value)
+		}
+	}
+	return sb.String()
+}
+
+// Decodes an event log from its 32 byte hex `topics` and hex `data`, using the ABI from the
+// provided file path or URL. If both a path and URL are provided it will default to using the file path.
+//
+// The event is found by its topic hash, `topics[0]`. Its indexed parameters are decoded from the
+// remaining topics, and its other parameters from `data`. When no event has that topic hash, every
+// anonymous event with as many indexed parameters as there are topics is tried, so several Logs
+// can be returned for anonymous events that decode the same topics and data.
+func Decode(topics []string, data string, abiPath string, abiUrl string) ([]*Log, error) {
+	parsedAbi, err := selector.LoadABI(selector.NewAbiSource(abiPath, abiUrl))
+	if err != nil {
+		return nil, err
+	}
+
+	topicHashes := make([]common.Hash, len(topics))
+	for idx, topic := range topics {
+		topicBytes, err := hexutil.Decode(strings.TrimSpace(topic))
+		if err != nil || len(topicBytes) != common.HashLength {
+			return nil, fmt.Errorf("%w: topic %d %q must be 32 bytes of 0x-prefixed hex", encdec.ErrInvalidHex, idx, topic)
+		}
+		topicHashes[idx] = common.BytesToHash(topicBytes)
+	}
+
+	dataBytes, err := hexutil.Decode(data)
+	if err != nil {
+		return nil, fmt.Errorf("%w: log data %q: %v", encdec.ErrInvalidHex, data, err)
+	}
+
+	return DecodeWithAbi(topicHashes, dataBytes, parsedAbi)
+}
+
+// Decodes an event log from its topics and data using an already parsed ABI. See Decode.
+func DecodeWithAbi(topics []common.Hash, data []byte, parsedAbi *abi.ABI) ([]*Log, error) {
+	if len(topics) > 0 {
+		if ev, err := parsedAbi.EventByID(topics[0]); err == nil {
+			decoded, err := decodeEvent(ev, topics[1:], data)
+			if err != nil {
+				return nil, err
+			}
+			return []*Log{decoded}, nil
+		}
+	}
+
+	// Anonymous events do not log their topic hash, so all their topics are indexed parameters.
+	var anonymous []abi.Event
+	for _, ev := range parsedAbi.Events {
+		if ev.Anonymous && countIndexed(ev.Inputs) == len(topics) {
+			anonymous = append(anonymous, ev)
+		}
+	}
+	sort.Slice(anonymous, func(i, j int) bool { return anonymous[i].Sig < anonymous[j].Sig })
+
+	var logs []*Log
+	for idx := range anonymous {
+		if decoded, err := decodeEvent(&anonymous[idx], topics, data); err == nil {
+			logs = append(logs, decoded)
+		}
+	}
+	if len(logs) == 0 {
+		return nil, fmt.Errorf("%w: no event matches topic hash %s, and no anonymous event decodes %d topics", selector.ErrSelectorNotFound, topicOrNone(topics), len(topics))
+	}
+	return logs, nil
+}
+
+// Decodes `indexedTopics` (the topics after the topic hash, if any) and `data` as per `ev`.
+func decodeEvent(ev *abi.Event, indexedTopics []common.Hash, data []byte) (*Log, error) {
+	if want := countIndexed(ev.Inputs); want != len(indexedTopics) {
+		return nil, fmt.Errorf("%w: %s has %d indexed parameters, but %d topics were given for them", encdec.ErrTypeMismatch, ev.Sig, want, len(indexedTopics))
+	}
+
+	nonIndexedValues, err := ev.Inputs.NonIndexed().Unpack(data)
+	if err != nil {
+		return nil, fmt.Errorf("%w: unpacking the data of %s: %v", encdec.ErrTypeMismatch, ev.Sig, err)
+	}
+
+	decoded := &Log{Event: ev, Fields: make([]Field, len(ev.Inputs))}
+	topicIdx, dataIdx := 0, 0
+	for idx, input := range ev.Inputs {
+		field := Field{Name: input.Name, Type: input.Type, Indexed: input.Indexed}
+		if field.Name == "" {
+			field.Name = fmt.Sprintf("arg%d", idx)
+		}
+
+		if input.Indexed {
+			field.Value, field.Hashed, err = decodeTopic(input.Type, indexedTopics[topicIdx])
+			if err != nil {
+				return nil, fmt.Errorf("%w: decoding topic for %s in %s: %v", encdec.ErrTypeMismatch, field.Name, ev.Sig, err)
+			}
+			topicIdx++
+		} else {
+			field.Value = nonIndexedValues[dataIdx]
+			dataIdx++
+		}
+		decoded.Fields[idx] = field
+	}
+	return decoded, nil
+}
+
+// Decodes a single indexed parameter from its topic. Dynamic types (strings, bytes, arrays and
+// tuples) are logged as the keccak256 hash of their value, so the hash is returned instead.
+func decodeTopic(abiType abi.Type, topic common.Hash) (any, bool, error) {
+	switch abiType.T {
+	case abi.StringTy, abi.BytesTy, abi.SliceTy, abi.ArrayTy, abi.TupleTy:
+		return topic, true, nil
+	}
+
+	values, err := abi.Arguments{{Type: abiType}}.Unpack(topic.Bytes())
+	if err != nil {
+		return nil, false, err
+	}
+	return values[0], false, nil
+}
+
+func countIndexed(inputs abi.Arguments) int {
+	count := 0
+	for _, input := range inputs {
+		if input.Indexed {
+			count++
+		}
+	}
+	return count
+}
+
+func topicOrNone(topics []common.Hash) string {
+	if len(topics) == 0 {
+		return "(none)"
+	}
+	return topics[0].Hex()
+}
@@ -0,0 +1,115 @@
+package eventlog
+
+import (
+	"errors"
+	"path"
+	"strings"
+	"testing"
+
+	"github.com/zeuslawyer/hextool/encdec"
+	"github.com/zeuslawyer/hextool/selector"
+)
+
+const (
+	transferTopic   = "0xddf252ad1be2c89b69c2b068fc378daa952ba7f163c4a11628f55a4df523b3ef"
+	registeredTopic = "0x51b0282f0a2e3849645ba990b59515ab6ae282eb5bae3172e3b02bcb0cf3212f"
+	addrTopic       = "0x000000000000000000000000208aa722aca42399eac5192ee778e4d42f4e5de3"
+	oneTopic        = "0x0000000000000000000000000000000000000000000000000000000000000001"
+	amountData      = "0x00000000000000000000000000000000000000000000000000000000000003e8"
+	hextoolHash     = "0xea509063f5930cc0ee500e5118269d86e77c88830b30f4174a4a33c3e24f6c57" // keccak256("hextool")
+)
+
+func TestDecode(t *testing.T) {
+	eventsAbi := path.Join("testdata", "events.abi.json")
+
+	tests := []struct {
+		name    string
+		topics  []string
+		data    string
+		want    []string // String() of each decoded log
+		wantErr error
+	}{
+		{
+			name:   "Transfer - indexed addresses and value in data",
+			topics: []string{transferTopic, addrTopic, oneTopic},
+			data:   amountData,
+			want: []string{"Transfer(address,address,uint256)" +
+				"\n  from (indexed): 0x208AA722Aca42399eaC5192EE778e4D42f4E5De3" +
+				"\n  to (indexed): 0x0000000000000000000000000000000000000001" +
+				"\n  value: 1000"},
+		},
+		{
+			name:   "indexed string is shown as its hash, tuple in data",
+			topics: []string{registeredTopic, hextoolHash},
+			data: "0x000000000000000000000000208aa722aca42399eac5192ee778e4d42f4e5de3" +
+				"0000000000000000000000000000000000000000000000000000000000000007" +
+				"1111111111111111111111111111111111111111111111111111111111111111",
+			want: []string{"Registered(string,address,(uint8,bytes32))" +
+				"\n  name (indexed, keccak256 of string): " + hextoolHash +
+				"\n  owner: 0x208AA722Aca42399eaC5192EE778e4D42f4E5De3" +
+				"\n  info: (id: 7, tag: 0x1111111111111111111111111111111111111111111111111111111111111111)"},
+		},
+		{
+			name:   "anonymous events matched by topic count",
+			topics: []string{addrTopic},
+			data:   amountData,
+			want: []string{
+				"Deposit(address,uint256) anonymous\n  account (indexed): 0x208AA722Aca42399eaC5192EE778e4D42f4E5De3\n  amount: 1000",
+				"Withdrawal(address,uint256) anonymous\n  account (indexed): 0x208AA722Aca42399eaC5192EE778e4D42f4E5De3\n  amount: 1000",
+			},
+		},
+		{
+			name:   "anonymous event with only indexed parameters",
+			topics: []string{addrTopic, oneTopic},
+			data:   "0x",
+			want: []string{"Linked(address,address) anonymous" +
+				"\n  from (indexed): 0x208AA722Aca42399eaC5192EE778e4D42f4E5De3" +
+				"\n  to (indexed): 0x0000000000000000000000000000000000000001"},
+		},
+		{
+			name:    "wrong number of topics",
+			topics:  []string{transferTopic, addrTopic},
+			data:    amountData,
+			wantErr: encdec.ErrTypeMismatch,
+		},
+		{
+			name:    "missing data",
+			topics:  []string{transferTopic, addrTopic, oneTopic},
+			data:    "0x",
+			wantErr: encdec.ErrTypeMismatch,
+		},
+		{
+			name:    "unknown topic hash",
+			topics:  []string{oneTopic, addrTopic, oneTopic, oneTopic},
+			data:    "0x",
+			wantErr: selector.ErrSelectorNotFound,
+		},
+		{
+			name:    "topic is not 32 bytes",
+			topics:  []string{"0xddf252ad"},
+			data:    "0x",
+			wantErr: encdec.ErrInvalidHex,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := Decode(tc.topics, tc.data, eventsAbi, "")
+			if tc.wantErr != nil {
+				if !errors.Is(err, tc.wantErr) {
+					t.Errorf("Decode() error = %v, want %v", err, tc.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Decode() unexpected error: %v", err)
+			}
+			gotStrings := make([]string, len(got))
+			for idx, decoded := range got {
+				gotStrings[idx] = decoded.String()
+			}
+			if strings.Join(gotStrings, "\n\n") != strings.Join(tc.want, "\n\n") {
+				t.Errorf("Decode() = %q, want %q", gotStrings, tc.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,43 @@
+// Package hashing computes the hashes contracts and wallets use, eg: keccak256 role ids
+// and EIP-191 message hashes.
+package hashing
+
+import (
+	"crypto/sha256"
+	"errors"
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/crypto"
+	"golang.org/x/crypto/ripemd160"
+)
+
+// The hash algorithms of Sum.
+const (
+	Keccak256 = "keccak256"
+	Sha256    = "sha256"
+	Ripemd160 = "ripemd160"
+	// The keccak256 hash of a message prefixed with "\x19Ethereum Signed Message:\n" and
+	// its length, which personal_sign and eth_sign sign (EIP-191 version 0x45).
+	EIP191 = "eip191"
+)
+
+// Returned by Sum for an algorithm it does not know.
+var ErrUnknownAlgorithm = errors.New("unknown hash algorithm")
+
+// Returns the hash of `data` with `algorithm`, one of Keccak256, Sha256, Ripemd160 or EIP191.
+func Sum(algorithm string, data []byte) ([]byte, error) {
+	switch algorithm {
+	case Keccak256:
+		return crypto.Keccak256(data), nil
+	case Sha256:
+		sum := sha256.Sum256(data)
+		return sum[:], nil
+	case Ripemd160:
+		hasher := ripemd160.New()
+		hasher.Write(data)
+		return hasher.Sum(nil), nil
+	case EIP191:
+		return crypto.Keccak256([]byte(fmt.Sprintf("\x19Ethereum Signed Message:\n%d", len(data))), data), nil
+	}
+	return nil, fmt.Errorf("%w %q, must be %q, %q, %q or %q", ErrUnknownAlgorithm, algorithm, Keccak256, Sha256, Ripemd160, EIP191)
+}
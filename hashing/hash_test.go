@@ -0,0 +1,73 @@
+package hashing
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+func TestSum(t *testing.T) {
+	tests := []struct {
+		name      string
+		algorithm string
+		data      string
+		want      string
+		wantErr   string
+	}{
+		{
+			name:      "keccak256 of a role name",
+			algorithm: Keccak256,
+			data:      "MINTER_ROLE",
+			want:      "0x9f2df0fed2c77648de5860a4cc508cd0818c85b8b8a1ab4ceeef8d981c8956a6",
+		},
+		{
+			name:      "keccak256 of nothing",
+			algorithm: Keccak256,
+			want:      "0xc5d2460186f7233c927e7db2dcc703c0e500b653ca82273b7bfad8045d85a470",
+		},
+		{
+			name:      "sha256",
+			algorithm: Sha256,
+			data:      "hello",
+			want:      "0x2cf24dba5fb0a30e26e83b2ac5b9e29e1b161e5c1fa7425e73043362938b9824",
+		},
+		{
+			name:      "ripemd160",
+			algorithm: Ripemd160,
+			data:      "hello",
+			want:      "0x108f07b8382412612c048d07d13f814118445acd",
+		},
+		{
+			name:      "eip191",
+			algorithm: EIP191,
+			data:      "hello",
+			want:      "0x50b2c43fd39106bafbba0da34fc430e1f91e3c96ea2acee2bc34119f92b37750",
+		},
+		{
+			name:      "unknown algorithm",
+			algorithm: "md5",
+			data:      "hello",
+			wantErr:   `unknown hash algorithm "md5"`,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			sum, err := Sum(tc.algorithm, []byte(tc.data))
+			if tc.wantErr != "" {
+				if !errors.Is(err, ErrUnknownAlgorithm) || !strings.Contains(err.Error(), tc.wantErr) {
+					t.Errorf("Sum() error = %v, want %q", err, tc.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := hexutil.Encode(sum); got != tc.want {
+				t.Errorf("Sum(%q, %q) = %s, want %s", tc.algorithm, tc.data, got, tc.want)
+			}
+		})
+	}
+}
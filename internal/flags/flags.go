@@ -1,6 +1,10 @@
 package flags
 
-import "github.com/urfave/cli/v2"
+import (
+	"time"
+
+	"github.com/urfave/cli/v2"
+)
 
 // Create a map of flags with keys as the flag name and values as the cli.Flag type
 var CommandFlags = make(map[string]cli.Flag)
@@ -19,26 +23,222 @@ func init() {
 		Name:  "topic",
 		Usage: "topic hash - 32 bytes",
 	}
+	CommandFlags["data"] = &cli.StringFlag{
+		Name:  "data",
+		Usage: "calldata hex string, starting with '0x' and the 4 byte function selector",
+	}
+	CommandFlags["topics"] = &cli.StringFlag{
+		Name:  "topics",
+		Usage: "comma-separated list of a log's 32 byte topics, starting with topic0 (the event's topic hash) unless the event is anonymous",
+	}
+	CommandFlags["logdata"] = &cli.StringFlag{
+		Name:  "data",
+		Value: "0x",
+		Usage: "a log's data hex string, holding the abi-encoded values of the event's non-indexed parameters",
+	}
+	CommandFlags["revertdata"] = &cli.StringFlag{
+		Name:  "data",
+		Value: "0x",
+		Usage: "hex string returned by a reverted call, starting with the 4 byte error selector",
+	}
 	CommandFlags["path"] = &cli.StringFlag{
 		Name:  "path",
-		Usage: "absolute path to the ABI file",
+		Usage: "path to the ABI file or compiled artifact (any extension), a directory of artifacts searched recursively, a glob pattern such as './out/**/*.json', '-' to read it from stdin, or the ABI JSON itself",
+	}
+	CommandFlags["proxy"] = &cli.StringFlag{
+		Name:  "proxy",
+		Value: "all",
+		Usage: "proxy whose admin functions are checked too: 'transparent', 'uups', 'diamond', 'all' of them, 'none', or the path to the proxy's own ABI",
+	}
+	CommandFlags["format"] = &cli.StringFlag{
+		Name:  "format",
+		Value: "text",
+		Usage: "output format: 'text', 'json' or 'markdown'",
 	}
 	CommandFlags["url"] = &cli.StringFlag{
 		Name:  "url",
-		Usage: "public API endpoint from where to fetch the object containing the abi property",
+		Usage: "public API endpoint from where to fetch the ABI array, or the object containing the abi property. The URL does not need to end in '.json'",
 	}
 	CommandFlags["sig"] = &cli.StringFlag{
 		Name:  "sig",
-		Usage: "Function signature in quotes. Exclude the the 'function' keyword. Must follow the ABI spec e.g.  'function foo(uint32 a, int b)' = 'foo(uint32,int256)'",
+		Usage: "Function signature in quotes, eg: 'foo(uint32,int256)', or its Solidity declaration, eg: 'function foo(uint32 a, int b) external returns (bool)'. Structs are defined first, eg: 'struct S { uint a; }; function foo(S s)'",
+	}
+	CommandFlags["eventsig"] = &cli.StringFlag{
+		Name:  "sig",
+		Usage: "Event signature in quotes, eg: 'Transfer(address,address,uint256)', or its Solidity declaration, eg: 'event Transfer(address indexed from, address indexed to, uint value)'",
+	}
+	CommandFlags["errorsig"] = &cli.StringFlag{
+		Name:  "sig",
+		Usage: "Custom error signature in quotes, eg: 'InsufficientBalance(uint256,uint256)', or its Solidity declaration, eg: 'error InsufficientBalance(uint available, uint required)'",
+	}
+	CommandFlags["method"] = &cli.StringFlag{
+		Name:  "method",
+		Usage: "name of a function in the ABI, eg: 'transfer'. Pass the full signature, eg: 'safeTransferFrom(address,address,uint256)', to pick one of several overloaded functions",
 	}
 	CommandFlags["types"] = &cli.StringFlag{
 		Name:  "types",
 		Value: "",
-		Usage: "comma-separated list of types to encode/decode the hex string to. Eg: 'string, uint, bool, uint'",
+		Usage: "comma-separated list of types to encode/decode the hex string to. Eg: 'string, uint, bool, uint'. Tuples go in parentheses and may name their fields, eg: '(address to, uint256 amount)[]'",
 	}
 	CommandFlags["values"] = &cli.StringFlag{
 		Name:  "values",
 		Value: "",
-		Usage: "comma-separated list of data values to encode the hex string to. Eg: 'string, uint, bool, uint'",
+		Usage: "comma-separated list of data values to encode the hex string to. Eg: 'string, uint, bool, uint'. Tuple values go in parentheses, eg: '(0x208AA722Aca42399eaC5192EE778e4D42f4E5De3, 100)', array values in square brackets, eg: '[[1,2],[3]]', and strings containing commas in double quotes",
+	}
+	CommandFlags["string"] = &cli.StringFlag{
+		Name:  "string",
+		Usage: "UTF-8 string to hash, eg: 'MINTER_ROLE'",
+	}
+	CommandFlags["hashhex"] = &cli.StringFlag{
+		Name:  "hex",
+		Usage: "0x-prefixed hex bytes to hash",
+	}
+	CommandFlags["packed-values"] = &cli.StringFlag{
+		Name:  "packed-values",
+		Usage: "comma-separated list of values to abi.encodePacked as per --types, then hash. Eg: '0x208AA722Aca42399eaC5192EE778e4D42f4E5De3, 100'",
+	}
+	CommandFlags["algo"] = &cli.StringFlag{
+		Name:  "algo",
+		Value: "keccak256",
+		Usage: "hash algorithm: 'keccak256', 'sha256', 'ripemd160' or 'eip191' (the keccak256 of the EIP-191 signed message, as personal_sign hashes it)",
+	}
+	CommandFlags["typeddata"] = &cli.StringFlag{
+		Name:     "file",
+		Required: true,
+		Usage:    "path to the EIP-712 typed data JSON, as eth_signTypedData_v4 takes it: an object with 'types', 'primaryType', 'domain' and 'message'",
+	}
+	CommandFlags["as"] = &cli.StringFlag{
+		Name:  "as",
+		Value: "hex",
+		Usage: "how to show the byte strings of the RLP tree: 'hex', 'int' (big-endian integers), 'address' (20-byte strings) or 'string' (printable UTF-8). Strings that cannot be interpreted are shown as hex",
+	}
+	CommandFlags["rlpjson"] = &cli.StringFlag{
+		Name:     "json",
+		Required: true,
+		Usage:    "JSON value to RLP-encode: arrays are lists, '0x'-prefixed strings are hex bytes, other strings their UTF-8 bytes and integers their big-endian bytes. Eg: '[\"0x6ac7ea33f8831ea9dcc53393aaa88b25a785dbf0\", 1]'",
+	}
+	CommandFlags["spec"] = &cli.StringFlag{
+		Name:  "spec",
+		Usage: "path to the JSON spec of the transaction, with its fields named as in the JSON-RPC API. Eg: '{\"type\": 2, \"chainId\": 1, \"nonce\": 0, \"gas\": 21000, \"maxFeePerGas\": \"30000000000\", \"maxPriorityFeePerGas\": \"1000000000\", \"to\": \"0x...\"}'. The other flags override its fields",
+	}
+	CommandFlags["txtype"] = &cli.StringFlag{
+		Name:  "type",
+		Usage: "transaction type: 0 (legacy), 1 (EIP-2930), 2 (EIP-1559, the default), 3 (EIP-4844) or 4 (EIP-7702)",
+	}
+	CommandFlags["chain-id"] = &cli.StringFlag{
+		Name:  "chain-id",
+		Usage: "chain id, required for typed transactions. Without it, legacy transactions are signed without EIP-155 replay protection",
+	}
+	CommandFlags["nonce"] = &cli.StringFlag{
+		Name:  "nonce",
+		Usage: "nonce of the sender",
+	}
+	CommandFlags["gas"] = &cli.StringFlag{
+		Name:  "gas",
+		Usage: "gas limit",
+	}
+	CommandFlags["gas-price"] = &cli.StringFlag{
+		Name:  "gas-price",
+		Usage: "gas price in wei, for legacy and EIP-2930 transactions",
+	}
+	CommandFlags["max-fee-per-gas"] = &cli.StringFlag{
+		Name:  "max-fee-per-gas",
+		Usage: "maximum fee per gas in wei, for EIP-1559, EIP-4844 and EIP-7702 transactions",
+	}
+	CommandFlags["max-priority-fee-per-gas"] = &cli.StringFlag{
+		Name:  "max-priority-fee-per-gas",
+		Usage: "maximum priority fee (tip) per gas in wei, for EIP-1559, EIP-4844 and EIP-7702 transactions",
+	}
+	CommandFlags["max-fee-per-blob-gas"] = &cli.StringFlag{
+		Name:  "max-fee-per-blob-gas",
+		Usage: "maximum fee per blob gas in wei, for EIP-4844 transactions",
+	}
+	CommandFlags["to"] = &cli.StringFlag{
+		Name:  "to",
+		Usage: "recipient address. Without it, the transaction creates a contract",
+	}
+	CommandFlags["value"] = &cli.StringFlag{
+		Name:  "value",
+		Usage: "value in wei",
+	}
+	CommandFlags["input"] = &cli.StringFlag{
+		Name:    "input",
+		Aliases: []string{"data"},
+		Usage:   "0x-prefixed calldata, eg: the output of hextool calldata.encode or abi.encode",
+	}
+	CommandFlags["blob-hashes"] = &cli.StringFlag{
+		Name:  "blob-hashes",
+		Usage: "comma-separated blob versioned hashes, for EIP-4844 transactions",
+	}
+	CommandFlags["keystore"] = &cli.StringFlag{
+		Name:  "keystore",
+		Usage: "path to an encrypted JSON keystore file to sign the transaction with, as geth writes them. Needs --password-file",
+	}
+	CommandFlags["password-file"] = &cli.StringFlag{
+		Name:  "password-file",
+		Usage: "path to the file holding the password of --keystore",
+	}
+	CommandFlags["private-key-file"] = &cli.StringFlag{
+		Name:  "private-key-file",
+		Usage: "path to a file holding the hex private key to sign the transaction with",
+	}
+	CommandFlags["signature"] = &cli.StringFlag{
+		Name:     "signature",
+		Required: true,
+		Usage:    "0x-prefixed ECDSA signature: 65 bytes r || s || v, with v 0, 1, 27 or 28, or 64 bytes in the EIP-2098 compact form",
+	}
+	CommandFlags["message"] = &cli.StringFlag{
+		Name:  "message",
+		Usage: "UTF-8 message signed with personal_sign (EIP-191), eg: a Sign-In with Ethereum message",
+	}
+	CommandFlags["message-hex"] = &cli.StringFlag{
+		Name:  "message-hex",
+		Usage: "0x-prefixed hex bytes signed with personal_sign (EIP-191)",
+	}
+	CommandFlags["digest"] = &cli.StringFlag{
+		Name:  "digest",
+		Usage: "0x-prefixed 32-byte digest signed as is, eg: an EIP-712 digest from hextool eip712.hash or a transaction signing hash",
+	}
+	CommandFlags["signer"] = &cli.StringFlag{
+		Name:     "address",
+		Required: true,
+		Usage:    "address expected to have signed",
+	}
+	CommandFlags["v"] = &cli.UintFlag{
+		Name:  "v",
+		Value: 27,
+		Usage: "v of the output signature for a y parity of 0: 27, as personal_sign, ecrecover and most wallets use, or 0, as go-ethereum's crypto.Sign and some hardware wallets use",
+	}
+	CommandFlags["artifacts"] = &cli.StringFlag{
+		Name:     "path",
+		Required: true,
+		Usage:    "path or glob pattern of the compiled artifacts or ABI files to import. '**' matches any number of directories, eg: './out/**/*.json'. Quote the pattern so that the shell does not expand it",
+	}
+	CommandFlags["source"] = &cli.StringFlag{
+		Name:  "source",
+		Usage: "name of the contract the signatures were imported from, eg: 'ERC20'",
+	}
+	CommandFlags["registrysig"] = &cli.StringFlag{
+		Name:  "sig",
+		Usage: "signature to remove, eg: 'transfer(address,uint256)'",
+	}
+	CommandFlags["query"] = &cli.StringFlag{
+		Name:     "query",
+		Required: true,
+		Usage:    "selector or topic hash to look up, or text to search for in signatures and contract names",
+	}
+	CommandFlags["resolver"] = &cli.StringFlag{
+		Name:  "resolver",
+		Usage: "base URL of a signature directory to query when no ABI is given and the selector is not built-in or registered, eg: 'http://localhost:8080'. Pass 'openchain' or '4byte' to use their public endpoints",
+	}
+	CommandFlags["resolverFormat"] = &cli.StringFlag{
+		Name:  "resolver-format",
+		Value: "openchain",
+		Usage: "API format of the --resolver endpoint: 'openchain' or '4byte'",
+	}
+	CommandFlags["resolverTimeout"] = &cli.DurationFlag{
+		Name:  "resolver-timeout",
+		Value: 10 * time.Second,
+		Usage: "how long to wait for the --resolver endpoint to respond",
 	}
 }
@@ -0,0 +1,82 @@
+// Package typescan finds the brackets and top-level commas of Solidity type lists and
+// signatures, for the packages that parse them.
+package typescan
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// An array suffix, eg: "[]" or "[2]".
+const ArraySuffix = `\[[0-9]*\]`
+
+// matches an array type, with its element type and the size in its last suffix. The
+// suffix cannot be separated from the element type by a space.
+var arrayTypeRegex = regexp.MustCompile(`^(.*\S)(` + ArraySuffix + `)$`)
+
+// Returns the index of the parenthesis that closes the one at `open`, eg: in a tuple type
+// or a function signature.
+func MatchingParen(s string, open int) (int, error) {
+	depth := 0
+	for i := open; i < len(s); i++ {
+		switch s[i] {
+		case '(':
+			depth++
+		case ')':
+			depth--
+			if depth == 0 {
+				return i, nil
+			}
+		}
+	}
+	return 0, fmt.Errorf("unbalanced parentheses")
+}
+
+// Splits `s` on the commas that are not nested inside parentheses or square brackets, eg:
+// a list of types or parameters.
+func SplitTopLevel(s string) ([]string, error) {
+	var parts []string
+	depth, start := 0, 0
+	for i, c := range s {
+		switch c {
+		case '(', '[':
+			depth++
+		case ')', ']':
+			depth--
+			if depth < 0 {
+				return nil, fmt.Errorf("unbalanced brackets in %q", s)
+			}
+		case ',':
+			if depth == 0 {
+				parts = append(parts, s[start:i])
+				start = i + 1
+			}
+		}
+	}
+	if depth != 0 {
+		return nil, fmt.Errorf("unbalanced brackets in %q", s)
+	}
+	return append(parts, s[start:]), nil
+}
+
+// Splits the last array suffix off `typ`, eg: "Person[2][]" into "Person[2]" and a size of -1
+// for a dynamic array, or "uint8[3]" into "uint8" and 3. Suffixes are read as encdec.ParseTypes
+// reads them, so that arrays of types it does not know, eg: EIP-712 structs, are read the same
+// way. It reports false if `typ` is not an array.
+func SplitArrayType(typ string) (elem string, size int, ok bool) {
+	match := arrayTypeRegex.FindStringSubmatch(typ)
+	if match == nil {
+		return "", 0, false
+	}
+	digits := strings.Trim(match[2], "[]")
+	if digits == "" {
+		return match[1], -1, true
+	}
+	size, err := strconv.Atoi(digits)
+	if err != nil {
+		return "", 0, false
+	}
+	return match[1], size, true
+}
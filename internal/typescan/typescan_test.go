@@ -0,0 +1,75 @@
+package typescan
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestSplitTopLevelAndMatchingParen(t *testing.T) {
+	tests := []struct {
+		input     string
+		wantParts []string
+		wantClose int // of the first parenthesis, -1 when unbalanced
+	}{
+		{input: "address,uint256", wantParts: []string{"address", "uint256"}, wantClose: -1},
+		{input: "(address,uint256)[],bool", wantParts: []string{"(address,uint256)[]", "bool"}, wantClose: 16},
+		{input: "((uint8,bytes)[2],string) memory order,address", wantParts: []string{"((uint8,bytes)[2],string) memory order", "address"}, wantClose: 24},
+		{input: "(address,uint256", wantClose: -1},
+		{input: "uint256[2]],bool", wantClose: -1},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.input, func(t *testing.T) {
+			parts, err := SplitTopLevel(tc.input)
+			if tc.wantParts == nil {
+				if err == nil {
+					t.Errorf("SplitTopLevel() = %q, expected an error", parts)
+				}
+			} else if err != nil || !reflect.DeepEqual(parts, tc.wantParts) {
+				t.Errorf("SplitTopLevel() = %q, %v, want %q", parts, err, tc.wantParts)
+			}
+
+			open := strings.Index(tc.input, "(")
+			if open == -1 {
+				return
+			}
+			closing, err := MatchingParen(tc.input, open)
+			if tc.wantClose == -1 {
+				if err == nil {
+					t.Errorf("MatchingParen() = %d, expected an error", closing)
+				}
+			} else if err != nil || closing != tc.wantClose {
+				t.Errorf("MatchingParen() = %d, %v, want %d", closing, err, tc.wantClose)
+			}
+		})
+	}
+}
+
+func TestSplitArrayType(t *testing.T) {
+	tests := []struct {
+		typ      string
+		wantElem string
+		wantSize int
+		wantOk   bool
+	}{
+		{typ: "uint256", wantOk: false},
+		{typ: "Person[]", wantElem: "Person", wantSize: -1, wantOk: true},
+		{typ: "uint8[2][3]", wantElem: "uint8[2]", wantSize: 3, wantOk: true},
+		{typ: "uint8[02]", wantElem: "uint8", wantSize: 2, wantOk: true},
+		{typ: "(uint8,bool)[]", wantElem: "(uint8,bool)", wantSize: -1, wantOk: true},
+		{typ: "uint8[ 2]", wantOk: false},
+		{typ: "uint8 [2]", wantOk: false},
+		{typ: "uint8[-2]", wantOk: false},
+		{typ: "[2]", wantOk: false},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.typ, func(t *testing.T) {
+			elem, size, ok := SplitArrayType(tc.typ)
+			if elem != tc.wantElem || size != tc.wantSize || ok != tc.wantOk {
+				t.Errorf("SplitArrayType() = %q, %d, %t, want %q, %d, %t", elem, size, ok, tc.wantElem, tc.wantSize, tc.wantOk)
+			}
+		})
+	}
+}
@@ -1,14 +1,28 @@
 package main
 
 import (
+	"crypto/ecdsa"
+	"errors"
 	"fmt"
 	"log"
 	"os"
+	"strings"
 
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
 	cli "github.com/urfave/cli/v2"
+	"github.com/zeuslawyer/hextool/calldata"
+	"github.com/zeuslawyer/hextool/eip712"
 	"github.com/zeuslawyer/hextool/encdec"
+	"github.com/zeuslawyer/hextool/eventlog"
+	"github.com/zeuslawyer/hextool/hashing"
 	"github.com/zeuslawyer/hextool/internal/flags"
+	"github.com/zeuslawyer/hextool/revert"
+	"github.com/zeuslawyer/hextool/rlp"
 	"github.com/zeuslawyer/hextool/selector"
+	"github.com/zeuslawyer/hextool/sig"
+	"github.com/zeuslawyer/hextool/tx"
 )
 
 const (
@@ -26,7 +40,11 @@ func main() {
 			Aliases: []string{"getstring"},
 			Usage:   "decode a hex string to string",
 			Action: func(cliCtx *cli.Context) error {
-				fmt.Printf("%v\n", encdec.DecodeHexToString(cliCtx.String("hex")))
+				decoded, err := encdec.HexToString(cliCtx.String("hex"))
+				if err != nil {
+					return exitError(err)
+				}
+				fmt.Printf("%v\n", decoded)
 				return nil
 			},
 			Flags: []cli.Flag{
@@ -38,7 +56,11 @@ func main() {
 			Aliases: []string{"getint"},
 			Usage:   "decode a hex string to int",
 			Action: func(cliCtx *cli.Context) error {
-				fmt.Printf("%v\n", encdec.DecodeHexToBigInt(cliCtx.String("hex")))
+				decoded, err := encdec.HexToBigInt(cliCtx.String("hex"))
+				if err != nil {
+					return exitError(err)
+				}
+				fmt.Printf("%v\n", decoded)
 				return nil
 			},
 			Flags: []cli.Flag{
@@ -51,76 +73,665 @@ func main() {
 			Aliases: []string{"selectorFromSig"},
 			Usage:   "calculates the function selector from a given function signature.",
 			Action: func(cliCtx *cli.Context) error {
-				fmt.Printf("%v\n", selector.SelectorFromSig(cliCtx.String("sig")))
+				sel, err := selector.FromSig(cliCtx.String("sig"))
+				if err != nil {
+					return exitError(err)
+				}
+				fmt.Printf("%v\n", sel)
 				return nil
 			},
 			Flags: []cli.Flag{
 				flags.CommandFlags["sig"],
 			},
 		},
+		{
+			Name:    "topic",
+			Aliases: []string{"topicFromSig"},
+			Usage:   "calculates the 32 byte topic hash (topic0) of an event from its signature",
+			Action: func(cliCtx *cli.Context) error {
+				topic, err := selector.TopicFromSig(cliCtx.String("sig"))
+				if err != nil {
+					return exitError(err)
+				}
+				fmt.Printf("%v\n", topic)
+				return nil
+			},
+			Flags: []cli.Flag{
+				flags.CommandFlags["eventsig"],
+			},
+		},
+		{
+			Name:    "errorselector",
+			Aliases: []string{"errorSelectorFromSig"},
+			Usage:   "calculates the 4 byte selector of a custom error from its signature",
+			Action: func(cliCtx *cli.Context) error {
+				sel, err := selector.ErrorSelectorFromSig(cliCtx.String("sig"))
+				if err != nil {
+					return exitError(err)
+				}
+				fmt.Printf("%v\n", sel)
+				return nil
+			},
+			Flags: []cli.Flag{
+				flags.CommandFlags["errorsig"],
+			},
+		},
+		{
+			Name:    "keccak",
+			Aliases: []string{"hash"},
+			Usage:   "hash a string (--string), hex bytes (--hex) or abi.encodePacked values (--packed-values and --types) with keccak256, or the algorithm given by --algo",
+			Action: func(cliCtx *cli.Context) error {
+				data, err := hashInput(cliCtx)
+				if err != nil {
+					return exitError(err)
+				}
+				sum, err := hashing.Sum(cliCtx.String("algo"), data)
+				if err != nil {
+					return exitError(err)
+				}
+				fmt.Printf("%v\n", hexutil.Encode(sum))
+				return nil
+			},
+			Flags: []cli.Flag{
+				flags.CommandFlags["string"],
+				flags.CommandFlags["hashhex"],
+				flags.CommandFlags["packed-values"],
+				flags.CommandFlags["types"],
+				flags.CommandFlags["algo"],
+			},
+		},
 		{
 			Name:    "decodeMethodSelector",
 			Aliases: []string{"methodsig"},
-			Usage:   "Look through the provided ABI to find a function signature that matches the given function selector",
+			Usage:   "Look through the provided ABI, or the built-in signature database if no ABI is given, to find the function signature(s) that match the given function selector",
 			Action: func(cliCtx *cli.Context) error {
-				fmt.Printf("%v\n", selector.SigFromSelector(
-					cliCtx.String("selector"),
-					cliCtx.String("path"),
-					cliCtx.String("url")),
-				)
+				found, err := sigFromFlags(cliCtx, selector.KindFunction, cliCtx.String("selector"), selector.MethodSig)
+				if err != nil {
+					return exitError(err)
+				}
+				fmt.Printf("%v\n", found)
 				return nil
 			},
 			Flags: []cli.Flag{
 				flags.CommandFlags["selector"],
 				flags.CommandFlags["path"],
 				flags.CommandFlags["url"],
+				flags.CommandFlags["resolver"],
+				flags.CommandFlags["resolverFormat"],
+				flags.CommandFlags["resolverTimeout"],
 			},
 		},
 		{
 			Name:    "decodeErrorSelector",
 			Aliases: []string{"errorSig"},
-			Usage:   "Look through the provided ABI to find the error signature that matches the given error selector",
+			Usage:   "Look through the provided ABI, or the built-in signature database if no ABI is given, to find the error signature(s) that match the given error selector",
 			Action: func(cliCtx *cli.Context) error {
-				fmt.Printf("%v\n", selector.ErrorSigFromSelector(
-					cliCtx.String("selector"),
-					cliCtx.String("path"),
-					cliCtx.String("url")),
-				)
+				found, err := sigFromFlags(cliCtx, selector.KindError, cliCtx.String("selector"), selector.ErrorSig)
+				if err != nil {
+					return exitError(err)
+				}
+				fmt.Printf("%v\n", found)
 				return nil
 			},
 			Flags: []cli.Flag{
 				flags.CommandFlags["selector"],
 				flags.CommandFlags["path"],
 				flags.CommandFlags["url"],
+				flags.CommandFlags["resolver"],
+				flags.CommandFlags["resolverFormat"],
+				flags.CommandFlags["resolverTimeout"],
 			},
 		},
 		{
 			Name:    "decodeEvent",
 			Aliases: []string{"eventsig"},
-			Usage:   "Look through the provided ABI to find the event signature that matches the given 32 byte topic hash",
+			Usage:   "Look through the provided ABI, or the built-in signature database if no ABI is given, to find the event signature(s) that match the given 32 byte topic hash",
 			Action: func(cliCtx *cli.Context) error {
-				fmt.Printf("%v\n", selector.EventFromTopicHash(
-					cliCtx.String("topic"),
+				found, err := sigFromFlags(cliCtx, selector.KindEvent, cliCtx.String("topic"), selector.EventSig)
+				if err != nil {
+					return exitError(err)
+				}
+				fmt.Printf("%v\n", found)
+				return nil
+			},
+			Flags: []cli.Flag{
+				flags.CommandFlags["topic"],
+				flags.CommandFlags["path"],
+				flags.CommandFlags["url"],
+				flags.CommandFlags["resolver"],
+				flags.CommandFlags["resolverFormat"],
+				flags.CommandFlags["resolverTimeout"],
+			},
+		},
+		{
+			Name:    "selectors.check",
+			Aliases: []string{"checkselectors"},
+			Usage:   "Check that no two functions in the provided ABIs, or in the admin functions of a proxy, share a selector. Exits with code 4 when some do, for use in CI",
+			Action: func(cliCtx *cli.Context) error {
+				functions, err := selector.ContractFunctions(selector.NewAbiSource(cliCtx.String("path"), cliCtx.String("url")))
+				if err != nil {
+					return exitError(err)
+				}
+				proxyFunctions, err := selector.ProxyFunctions(cliCtx.String("proxy"))
+				if err != nil {
+					return exitError(err)
+				}
+
+				collisions := selector.FindCollisions(append(functions, proxyFunctions...))
+				if len(collisions) > 0 {
+					fmt.Printf("%v\n", selector.FormatCollisions(collisions))
+					return exitError(fmt.Errorf("%w: %d selector(s) shared by functions with different signatures", selector.ErrSelectorCollision, len(collisions)))
+				}
+				fmt.Printf("No selector collisions among %d functions\n", len(functions))
+				return nil
+			},
+			Flags: []cli.Flag{
+				flags.CommandFlags["path"],
+				flags.CommandFlags["url"],
+				flags.CommandFlags["proxy"],
+			},
+		},
+		{
+			Name:    "calldata.decode",
+			Aliases: []string{"decodecalldata"},
+			Usage:   "decode calldata into the function signature and its named arguments, using the function selector in its first 4 bytes to find the function in the provided ABI",
+			Action: func(cliCtx *cli.Context) error {
+				call, err := calldata.Decode(
+					cliCtx.String("data"),
 					cliCtx.String("path"),
-					cliCtx.String("url")),
+					cliCtx.String("url"),
 				)
+				if err != nil {
+					return exitError(err)
+				}
+				fmt.Printf("%v\n", call)
 				return nil
 			},
 			Flags: []cli.Flag{
-				flags.CommandFlags["topic"],
+				flags.CommandFlags["data"],
+				flags.CommandFlags["path"],
+				flags.CommandFlags["url"],
+			},
+		},
+		{
+			Name:    "calldata.encode",
+			Aliases: []string{"encodecalldata"},
+			Usage:   "encode calldata (function selector followed by the abi-encoded values) for a function signature, or for a method in the provided ABI",
+			Action: func(cliCtx *cli.Context) error {
+				var encoded string
+				var err error
+				if cliCtx.String("sig") != "" {
+					encoded, err = calldata.Encode(
+						cliCtx.String("sig"),
+						cliCtx.String("values"),
+					)
+				} else {
+					encoded, err = calldata.EncodeFromAbi(
+						cliCtx.String("method"),
+						cliCtx.String("values"),
+						cliCtx.String("path"),
+						cliCtx.String("url"),
+					)
+				}
+				if err != nil {
+					return exitError(err)
+				}
+				fmt.Printf("%v\n", encoded)
+				return nil
+			},
+			Flags: []cli.Flag{
+				flags.CommandFlags["sig"],
+				flags.CommandFlags["values"],
+				flags.CommandFlags["method"],
+				flags.CommandFlags["path"],
+				flags.CommandFlags["url"],
+			},
+		},
+		{
+			Name:    "tx.decode",
+			Aliases: []string{"decodetx"},
+			Usage:   "decode a raw legacy, EIP-2930, EIP-1559, EIP-4844 or EIP-7702 transaction into its fields, hash and sender. With an ABI, its input is decoded into the function call",
+			Action: func(cliCtx *cli.Context) error {
+				decoded, err := tx.Decode(cliCtx.String("hex"))
+				if err != nil {
+					return exitError(err)
+				}
+				fmt.Printf("%v\n", decoded)
+
+				path, url := cliCtx.String("path"), cliCtx.String("url")
+				if len(decoded.Input) == 0 || (path == "" && url == "") {
+					return nil
+				}
+				input, isCall := decoded.Calldata()
+				if !isCall {
+					fmt.Println("call: none, the transaction creates a contract and its input is init code")
+					return nil
+				}
+				parsedAbi, err := selector.LoadABI(selector.NewAbiSource(path, url))
+				if err != nil {
+					return exitError(err)
+				}
+				call, err := calldata.DecodeWithAbi(input, parsedAbi)
+				if err != nil {
+					return exitError(err)
+				}
+				fmt.Printf("call: %v\n", call)
+				return nil
+			},
+			Flags: []cli.Flag{
+				flags.CommandFlags["hex"],
+				flags.CommandFlags["path"],
+				flags.CommandFlags["url"],
+			},
+		},
+		{
+			Name:    "tx.build",
+			Aliases: []string{"buildtx"},
+			Usage:   "build a transaction from a JSON spec or flags, offline, and print its signing hash. With a keystore or private key file, sign it and print the raw transaction",
+			Action: func(cliCtx *cli.Context) error {
+				spec, err := txSpecFromFlags(cliCtx)
+				if err != nil {
+					return exitError(err)
+				}
+				built, err := tx.Build(spec)
+				if err != nil {
+					return exitError(err)
+				}
+				fmt.Printf("signing hash: %s\n", built.SigningHash().Hex())
+
+				key, err := signingKey(cliCtx)
+				if err != nil {
+					return exitError(err)
+				}
+				if key == nil {
+					fmt.Printf("unsigned: %s\n", hexutil.Encode(built.Encode()))
+					return nil
+				}
+				if err := built.Sign(key); err != nil {
+					return exitError(err)
+				}
+				fmt.Printf("from: %s\nhash: %s\nraw: %s\n", crypto.PubkeyToAddress(key.PublicKey).Hex(), built.Hash().Hex(), hexutil.Encode(built.Encode()))
+				return nil
+			},
+			Flags: []cli.Flag{
+				flags.CommandFlags["spec"],
+				flags.CommandFlags["txtype"],
+				flags.CommandFlags["chain-id"],
+				flags.CommandFlags["nonce"],
+				flags.CommandFlags["gas"],
+				flags.CommandFlags["gas-price"],
+				flags.CommandFlags["max-fee-per-gas"],
+				flags.CommandFlags["max-priority-fee-per-gas"],
+				flags.CommandFlags["max-fee-per-blob-gas"],
+				flags.CommandFlags["to"],
+				flags.CommandFlags["value"],
+				flags.CommandFlags["input"],
+				flags.CommandFlags["blob-hashes"],
+				flags.CommandFlags["keystore"],
+				flags.CommandFlags["password-file"],
+				flags.CommandFlags["private-key-file"],
+			},
+		},
+		{
+			Name:    "sig.recover",
+			Aliases: []string{"recoversig", "ecrecover"},
+			Usage:   "recover the address that signed a personal_sign (EIP-191) message or a raw digest",
+			Action: func(cliCtx *cli.Context) error {
+				signature, digest, err := signatureInput(cliCtx)
+				if err != nil {
+					return exitError(err)
+				}
+				signer, err := signature.Recover(digest)
+				if err != nil {
+					return exitError(err)
+				}
+				fmt.Printf("digest: %s\nsigner: %s\n", hexutil.Encode(digest), signer.Hex())
+				return nil
+			},
+			Flags: []cli.Flag{
+				flags.CommandFlags["signature"],
+				flags.CommandFlags["message"],
+				flags.CommandFlags["message-hex"],
+				flags.CommandFlags["digest"],
+			},
+		},
+		{
+			Name:    "sig.verify",
+			Aliases: []string{"verifysig"},
+			Usage:   "check that a personal_sign (EIP-191) message or a raw digest was signed by an address. Exits with code 5 if another address signed it",
+			Action: func(cliCtx *cli.Context) error {
+				signature, digest, err := signatureInput(cliCtx)
+				if err != nil {
+					return exitError(err)
+				}
+				expected := cliCtx.String("address")
+				if !common.IsHexAddress(expected) {
+					return exitError(fmt.Errorf("%w: --address %q is not a hex address", encdec.ErrInvalidHex, expected))
+				}
+				signer, err := signature.Verify(digest, common.HexToAddress(expected))
+				if err != nil {
+					return exitError(err)
+				}
+				fmt.Printf("valid: signed by %s\n", signer.Hex())
+				if signature.IsHighS() {
+					fmt.Println("warning: s is high, so transactions and OpenZeppelin's ECDSA reject this signature, see `hextool sig.normalize`")
+				}
+				return nil
+			},
+			Flags: []cli.Flag{
+				flags.CommandFlags["signature"],
+				flags.CommandFlags["message"],
+				flags.CommandFlags["message-hex"],
+				flags.CommandFlags["digest"],
+				flags.CommandFlags["signer"],
+			},
+		},
+		{
+			Name:    "sig.split",
+			Aliases: []string{"splitsig"},
+			Usage:   "split a 65-byte or EIP-2098 compact signature into r, s and v, and show its 65-byte and compact forms",
+			Action: func(cliCtx *cli.Context) error {
+				signature, err := sig.Parse(cliCtx.String("signature"))
+				if err != nil {
+					return exitError(err)
+				}
+				fmt.Printf("%v\n", signature)
+				return nil
+			},
+			Flags: []cli.Flag{
+				flags.CommandFlags["signature"],
+			},
+		},
+		{
+			Name:    "sig.compact",
+			Aliases: []string{"compactsig"},
+			Usage:   "convert a 65-byte low-s signature into its 64-byte EIP-2098 compact form",
+			Action: func(cliCtx *cli.Context) error {
+				signature, err := sig.Parse(cliCtx.String("signature"))
+				if err != nil {
+					return exitError(err)
+				}
+				compact, err := signature.CompactBytes()
+				if err != nil {
+					return exitError(err)
+				}
+				fmt.Printf("%v\n", hexutil.Encode(compact))
+				return nil
+			},
+			Flags: []cli.Flag{
+				flags.CommandFlags["signature"],
+			},
+		},
+		{
+			Name:    "sig.normalize",
+			Aliases: []string{"normalizesig", "expandsig"},
+			Usage:   "convert a signature into the 65-byte low-s form, with v 27/28 or 0/1. EIP-2098 compact signatures are expanded",
+			Action: func(cliCtx *cli.Context) error {
+				signature, err := sig.Parse(cliCtx.String("signature"))
+				if err != nil {
+					return exitError(err)
+				}
+				normalized := signature.Normalize()
+				switch cliCtx.Uint("v") {
+				case 27:
+					fmt.Printf("%v\n", hexutil.Encode(normalized.Bytes()))
+				case 0:
+					fmt.Printf("%v\n", hexutil.Encode(normalized.RecoveryBytes()))
+				default:
+					return exitError(fmt.Errorf("%w: --v must be 27 or 0, got %d", sig.ErrInvalidSignature, cliCtx.Uint("v")))
+				}
+				return nil
+			},
+			Flags: []cli.Flag{
+				flags.CommandFlags["signature"],
+				flags.CommandFlags["v"],
+			},
+		},
+		{
+			Name:    "log.decode",
+			Aliases: []string{"decodelog"},
+			Usage:   "decode an event log's indexed parameters from its topics and its other parameters from its data, using the event in the provided ABI",
+			Action: func(cliCtx *cli.Context) error {
+				var topics []string
+				if cliCtx.String("topics") != "" {
+					topics = strings.Split(cliCtx.String("topics"), ",")
+				}
+				logs, err := eventlog.Decode(
+					topics,
+					cliCtx.String("data"),
+					cliCtx.String("path"),
+					cliCtx.String("url"),
+				)
+				if err != nil {
+					return exitError(err)
+				}
+				for idx, decoded := range logs {
+					if idx > 0 {
+						fmt.Println()
+					}
+					fmt.Printf("%v\n", decoded)
+				}
+				return nil
+			},
+			Flags: []cli.Flag{
+				flags.CommandFlags["topics"],
+				flags.CommandFlags["logdata"],
 				flags.CommandFlags["path"],
 				flags.CommandFlags["url"],
 			},
 		},
+		{
+			Name:    "revert.decode",
+			Aliases: []string{"decoderevert"},
+			Usage:   "decode the data returned by a reverted call. Error(string) and Panic(uint256) are decoded without an ABI. Custom errors need the provided ABI",
+			Action: func(cliCtx *cli.Context) error {
+				decoded, err := revert.Decode(
+					cliCtx.String("data"),
+					cliCtx.String("path"),
+					cliCtx.String("url"),
+				)
+				if err != nil {
+					return exitError(err)
+				}
+				fmt.Printf("%v\n", decoded)
+				return nil
+			},
+			Flags: []cli.Flag{
+				flags.CommandFlags["revertdata"],
+				flags.CommandFlags["path"],
+				flags.CommandFlags["url"],
+			},
+		},
+		{
+			Name:  "sigdb",
+			Usage: "Manage the local signature registry that decodeMethodSelector, decodeErrorSelector and decodeEvent consult when no ABI is given",
+			Subcommands: []*cli.Command{
+				{
+					Name:  "add",
+					Usage: "Import every function, event and error signature from compiled artifacts or ABI files",
+					Action: func(cliCtx *cli.Context) error {
+						// an unquoted pattern is expanded by the shell into several arguments.
+						patterns := append([]string{cliCtx.String("path")}, cliCtx.Args().Slice()...)
+
+						registry, err := selector.OpenDefaultRegistry()
+						if err != nil {
+							return exitError(err)
+						}
+						imported, err := selector.SignaturesFromArtifacts(patterns)
+						if err != nil {
+							return exitError(err)
+						}
+						added, err := registry.Add(imported.Signatures...)
+						if err != nil {
+							return exitError(err)
+						}
+						if err := registry.Save(); err != nil {
+							return exitError(err)
+						}
+						if len(imported.Skipped) > 0 {
+							fmt.Printf("skipped %d files without an ABI\n", len(imported.Skipped))
+						}
+						fmt.Printf("Added %d signatures from %d files to %s\n", added, imported.Files, registry.Path())
+						return nil
+					},
+					Flags: []cli.Flag{
+						flags.CommandFlags["artifacts"],
+					},
+				},
+				{
+					Name:  "list",
+					Usage: "List the signatures in the registry",
+					Action: func(cliCtx *cli.Context) error {
+						registry, err := selector.OpenDefaultRegistry()
+						if err != nil {
+							return exitError(err)
+						}
+						if err := printSignatures(registry.List()); err != nil {
+							return exitError(err)
+						}
+						return nil
+					},
+				},
+				{
+					Name:  "search",
+					Usage: "Find the registered signatures with a selector or topic hash, or that contain some text in their signature or contract name",
+					Action: func(cliCtx *cli.Context) error {
+						registry, err := selector.OpenDefaultRegistry()
+						if err != nil {
+							return exitError(err)
+						}
+						matches := registry.Search(cliCtx.String("query"))
+						if len(matches) == 0 {
+							return exitError(fmt.Errorf("%w: no registered signature matches %q", selector.ErrSelectorNotFound, cliCtx.String("query")))
+						}
+						if err := printSignatures(matches); err != nil {
+							return exitError(err)
+						}
+						return nil
+					},
+					Flags: []cli.Flag{
+						flags.CommandFlags["query"],
+					},
+				},
+				{
+					Name:  "remove",
+					Usage: "Remove a signature, all the signatures imported from a contract, or a signature imported from a given contract",
+					Action: func(cliCtx *cli.Context) error {
+						text, source := cliCtx.String("sig"), cliCtx.String("source")
+						if text == "" && source == "" {
+							return exitError(errors.New("pass --sig, --source or both to choose the signatures to remove"))
+						}
+						registry, err := selector.OpenDefaultRegistry()
+						if err != nil {
+							return exitError(err)
+						}
+						removed := registry.Remove(text, source)
+						if err := registry.Save(); err != nil {
+							return exitError(err)
+						}
+						fmt.Printf("Removed %d signatures from %s\n", removed, registry.Path())
+						return nil
+					},
+					Flags: []cli.Flag{
+						flags.CommandFlags["registrysig"],
+						flags.CommandFlags["source"],
+					},
+				},
+			},
+		},
+		{
+			Name:    "rlp.decode",
+			Aliases: []string{"decoderlp"},
+			Usage:   "decode the given RLP hex into its tree of lists and byte strings, optionally showing the byte strings as integers, addresses or strings",
+			Action: func(cliCtx *cli.Context) error {
+				item, err := rlp.Decode(cliCtx.String("hex"))
+				if err != nil {
+					return exitError(err)
+				}
+				tree, err := item.Format(cliCtx.String("as"))
+				if err != nil {
+					return exitError(err)
+				}
+				fmt.Printf("%v\n", tree)
+				return nil
+			},
+			Flags: []cli.Flag{
+				flags.CommandFlags["hex"],
+				flags.CommandFlags["as"],
+			},
+		},
+		{
+			Name:    "rlp.encode",
+			Aliases: []string{"encoderlp"},
+			Usage:   "RLP-encode the given JSON value, where nested arrays are lists, into hex",
+			Action: func(cliCtx *cli.Context) error {
+				item, err := rlp.ParseJSON(cliCtx.String("json"))
+				if err != nil {
+					return exitError(err)
+				}
+				fmt.Printf("%v\n", hexutil.Encode(item.Encode()))
+				return nil
+			},
+			Flags: []cli.Flag{
+				flags.CommandFlags["rlpjson"],
+			},
+		},
+		{
+			Name:    "eip712.hash",
+			Aliases: []string{"hashtypeddata"},
+			Usage:   "compute the domain separator, encodeType, typeHash, hashStruct and digest of EIP-712 typed data, as eth_signTypedData_v4 does",
+			Action: func(cliCtx *cli.Context) error {
+				typedData, err := eip712.LoadTypedData(cliCtx.String("file"))
+				if err != nil {
+					return exitError(err)
+				}
+				hashes, err := typedData.Hash()
+				if err != nil {
+					return exitError(err)
+				}
+				fmt.Printf("%v\n", hashes)
+				return nil
+			},
+			Flags: []cli.Flag{
+				flags.CommandFlags["typeddata"],
+			},
+		},
+		{
+			Name:    "abi.inspect",
+			Aliases: []string{"inspectabi"},
+			Usage:   "list every function with its selector, event with its topic0 and error with its selector in the provided ABI, as a text or markdown table, or as JSON",
+			Action: func(cliCtx *cli.Context) error {
+				parsedAbi, err := selector.LoadABI(selector.NewAbiSource(cliCtx.String("path"), cliCtx.String("url")))
+				if err != nil {
+					return exitError(err)
+				}
+				table, err := selector.FormatEntries(selector.AbiEntries(parsedAbi), cliCtx.String("format"))
+				if err != nil {
+					return exitError(err)
+				}
+				fmt.Printf("%v\n", table)
+				return nil
+			},
+			Flags: []cli.Flag{
+				flags.CommandFlags["path"],
+				flags.CommandFlags["url"],
+				flags.CommandFlags["format"],
+			},
+		},
 		{
 			Name:    "abi.decode",
 			Aliases: []string{"abidecode"},
 			Usage:   "abi-decode the given input hex into its corresponding data as per the comma-separated types provided",
 			Action: func(cliCtx *cli.Context) error {
-				fmt.Printf("%v\n", encdec.AbiDecode(
-					cliCtx.String("hex"),
-					cliCtx.String("types"),
-				))
+				args, err := encdec.ParseTypes(cliCtx.String("types"))
+				if err != nil {
+					return exitError(err)
+				}
+				values, err := encdec.DecodeArguments(cliCtx.String("hex"), args)
+				if err != nil {
+					return exitError(err)
+				}
+				fmt.Printf("%v\n", encdec.FormatValues(args, values))
 				return nil
 			},
 			Flags: []cli.Flag{
@@ -133,10 +744,34 @@ func main() {
 			Aliases: []string{"abiencode"},
 			Usage:   "abi-encode the given input values into hex, as per the data types provided. Input values and data types be comma-separated",
 			Action: func(cliCtx *cli.Context) error {
-				fmt.Printf("%v\n", encdec.AbiEncode(
+				encoded, err := encdec.Encode(
+					cliCtx.String("values"),
+					cliCtx.String("types"),
+				)
+				if err != nil {
+					return exitError(err)
+				}
+				fmt.Printf("%v\n", encoded)
+				return nil
+			},
+			Flags: []cli.Flag{
+				flags.CommandFlags["values"],
+				flags.CommandFlags["types"],
+			},
+		},
+		{
+			Name:    "abi.encodePacked",
+			Aliases: []string{"abiencodepacked"},
+			Usage:   "encode the given input values into hex the way Solidity's abi.encodePacked does, as per the data types provided. Input values and data types must be comma-separated",
+			Action: func(cliCtx *cli.Context) error {
+				encoded, err := encdec.EncodePacked(
 					cliCtx.String("values"),
 					cliCtx.String("types"),
-				))
+				)
+				if err != nil {
+					return exitError(err)
+				}
+				fmt.Printf("%v\n", encoded)
 				return nil
 			},
 			Flags: []cli.Flag{
@@ -150,3 +785,224 @@ func main() {
 		log.Fatal(err)
 	}
 }
+
+// Looks up `id` in the ABI given by the --path or --url flags using `fromAbi`. When no
+// ABI is given, it falls back to the built-in signatures, the registry and, if the
+// --resolver flag is set, the signature directory it points at.
+func sigFromFlags(cliCtx *cli.Context, kind selector.SigKind, id string, fromAbi func(id, path, url string) (string, error)) (string, error) {
+	path, url := cliCtx.String("path"), cliCtx.String("url")
+	if path != "" || url != "" || cliCtx.String("resolver") == "" {
+		return fromAbi(id, path, url)
+	}
+
+	resolver, err := selector.NewHTTPResolver(
+		cliCtx.String("resolver"),
+		cliCtx.String("resolver-format"),
+		cliCtx.Duration("resolver-timeout"),
+	)
+	if err != nil {
+		return "", err
+	}
+	return selector.ResolveSig(kind, id, resolver)
+}
+
+// Returns the bytes the keccak command hashes, from exactly one of the --string, --hex
+// or --packed-values flags.
+func hashInput(cliCtx *cli.Context) ([]byte, error) {
+	var inputs []string
+	for _, name := range []string{"string", "hex", "packed-values"} {
+		if cliCtx.IsSet(name) {
+			inputs = append(inputs, "--"+name)
+		}
+	}
+	if len(inputs) != 1 {
+		return nil, fmt.Errorf("%w: pass exactly one of --string, --hex or --packed-values, got %d", errInvalidFlags, len(inputs))
+	}
+	if cliCtx.IsSet("types") && !cliCtx.IsSet("packed-values") {
+		return nil, fmt.Errorf("%w: --types only applies to --packed-values", errInvalidFlags)
+	}
+
+	switch inputs[0] {
+	case "--hex":
+		return encdec.HexToBytes(cliCtx.String("hex"))
+	case "--packed-values":
+		args, err := encdec.ParseTypes(cliCtx.String("types"))
+		if err != nil {
+			return nil, err
+		}
+		return encdec.EncodePackedArguments(cliCtx.String("packed-values"), args)
+	}
+	return []byte(cliCtx.String("string")), nil
+}
+
+// Returns the signature of the sig.recover and sig.verify commands, and the digest it signs:
+// the EIP-191 hash of --message or --message-hex, or --digest as is.
+func signatureInput(cliCtx *cli.Context) (*sig.Signature, []byte, error) {
+	var inputs []string
+	for _, name := range []string{"message", "message-hex", "digest"} {
+		if cliCtx.IsSet(name) {
+			inputs = append(inputs, "--"+name)
+		}
+	}
+	if len(inputs) != 1 {
+		return nil, nil, fmt.Errorf("%w: pass exactly one of --message, --message-hex or --digest, got %d", errInvalidFlags, len(inputs))
+	}
+
+	signature, err := sig.Parse(cliCtx.String("signature"))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var digest []byte
+	switch inputs[0] {
+	case "--message":
+		digest, err = hashing.Sum(hashing.EIP191, []byte(cliCtx.String("message")))
+	case "--message-hex":
+		var message []byte
+		if message, err = encdec.HexToBytes(cliCtx.String("message-hex")); err == nil {
+			digest, err = hashing.Sum(hashing.EIP191, message)
+		}
+	case "--digest":
+		digest, err = encdec.HexToBytes(cliCtx.String("digest"))
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	return signature, digest, nil
+}
+
+// Returns the spec of the tx.build command: the one in the --spec file, if any, with the
+// fields given by the other flags overriding its own.
+func txSpecFromFlags(cliCtx *cli.Context) (*tx.Spec, error) {
+	spec := &tx.Spec{}
+	if path := cliCtx.String("spec"); path != "" {
+		var err error
+		if spec, err = tx.LoadSpec(path); err != nil {
+			return nil, err
+		}
+	}
+
+	quantities := map[string]**tx.Quantity{
+		"type":                     &spec.Type,
+		"chain-id":                 &spec.ChainID,
+		"nonce":                    &spec.Nonce,
+		"gas":                      &spec.Gas,
+		"gas-price":                &spec.GasPrice,
+		"max-fee-per-gas":          &spec.MaxFeePerGas,
+		"max-priority-fee-per-gas": &spec.MaxPriorityFeePerGas,
+		"max-fee-per-blob-gas":     &spec.MaxFeePerBlobGas,
+		"value":                    &spec.Value,
+	}
+	for name, field := range quantities {
+		if !cliCtx.IsSet(name) {
+			continue
+		}
+		q, err := tx.ParseQuantity(cliCtx.String(name))
+		if err != nil {
+			return nil, fmt.Errorf("--%s: %w", name, err)
+		}
+		*field = &q
+	}
+
+	if cliCtx.IsSet("to") {
+		to := cliCtx.String("to")
+		if !common.IsHexAddress(to) {
+			return nil, fmt.Errorf("%w: --to %q is not a hex address", encdec.ErrInvalidHex, to)
+		}
+		address := common.HexToAddress(to)
+		spec.To = &address
+	}
+	if cliCtx.IsSet("input") {
+		input, err := encdec.HexToBytes(cliCtx.String("input"))
+		if err != nil {
+			return nil, err
+		}
+		spec.Input, spec.Data = (*hexutil.Bytes)(&input), nil
+	}
+	if cliCtx.IsSet("blob-hashes") {
+		spec.BlobVersionedHashes = nil
+		for _, hash := range strings.Split(cliCtx.String("blob-hashes"), ",") {
+			b, err := encdec.HexToBytes(strings.TrimSpace(hash))
+			if err != nil {
+				return nil, err
+			}
+			if len(b) != common.HashLength {
+				return nil, fmt.Errorf("%w: blob versioned hash %s is %d bytes long, want 32", encdec.ErrInvalidHex, hash, len(b))
+			}
+			spec.BlobVersionedHashes = append(spec.BlobVersionedHashes, common.BytesToHash(b))
+		}
+	}
+	return spec, nil
+}
+
+// Returns the key given by the --keystore and --password-file flags, or by --private-key-file,
+// or nil when none is given.
+func signingKey(cliCtx *cli.Context) (*ecdsa.PrivateKey, error) {
+	keystorePath, keyPath := cliCtx.String("keystore"), cliCtx.String("private-key-file")
+	switch {
+	case keystorePath != "" && keyPath != "":
+		return nil, fmt.Errorf("%w: pass --keystore or --private-key-file, not both", errInvalidFlags)
+	case keystorePath != "":
+		if cliCtx.String("password-file") == "" {
+			return nil, fmt.Errorf("%w: --keystore needs --password-file", errInvalidFlags)
+		}
+		return tx.LoadKeystore(keystorePath, cliCtx.String("password-file"))
+	case keyPath != "":
+		return tx.LoadKeyFile(keyPath)
+	}
+	return nil, nil
+}
+
+// Prints one signature per line with its selector or topic hash and source contract.
+func printSignatures(sigs []selector.Signature) error {
+	for _, s := range sigs {
+		id, err := s.ID()
+		if err != nil {
+			return err
+		}
+		fmt.Printf("%-8s %s %s (%s)\n", s.Kind, id, s.Text, s.Source)
+	}
+	return nil
+}
+
+// Exit codes returned by hextool commands when they fail.
+const (
+	exitCodeFailure      = 1 // any error not covered by a more specific code.
+	exitCodeInvalidInput = 2 // malformed hex, types, values, signatures, typed data, RLP, transactions or ECDSA signatures.
+	exitCodeNotFound     = 3 // the selector or topic hash is not in the ABI.
+	exitCodeCollision    = 4 // functions with different signatures share a selector.
+	exitCodeMismatch     = 5 // the signature was made by another address than the expected one.
+)
+
+// Returned when the flags of a command do not go together, eg: when exactly one of
+// several flags must be given. exitError maps it to exitCodeInvalidInput.
+var errInvalidFlags = errors.New("invalid flags")
+
+// Maps errors returned by the encdec and selector packages onto a clean message
+// and a non-zero exit code, so that scripts can tell failures apart.
+func exitError(err error) error {
+	code := exitCodeFailure
+	switch {
+	case errors.Is(err, encdec.ErrInvalidHex),
+		errors.Is(err, encdec.ErrTypeMismatch),
+		errors.Is(err, hashing.ErrUnknownAlgorithm),
+		errors.Is(err, errInvalidFlags),
+		errors.Is(err, selector.ErrInvalidSignature),
+		errors.Is(err, eip712.ErrInvalidTypedData),
+		errors.Is(err, rlp.ErrInvalidRLP),
+		errors.Is(err, tx.ErrInvalidTx),
+		errors.Is(err, tx.ErrInvalidTxSignature),
+		errors.Is(err, sig.ErrInvalidSignature),
+		errors.Is(err, sig.ErrInvalidDigest),
+		errors.Is(err, calldata.ErrAmbiguousMethod):
+		code = exitCodeInvalidInput
+	case errors.Is(err, selector.ErrSelectorNotFound):
+		code = exitCodeNotFound
+	case errors.Is(err, selector.ErrSelectorCollision):
+		code = exitCodeCollision
+	case errors.Is(err, sig.ErrSignerMismatch):
+		code = exitCodeMismatch
+	}
+
+	return cli.Exit(fmt.Sprintf("Error: %v", err), code)
+}
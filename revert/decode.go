@@ -0,0 +1,151 @@
+package revert
+
+import (
+	"bytes"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/zeuslawyer/hextool/calldata"
+	"github.com/zeuslawyer/hextool/encdec"
+	"github.com/zeuslawyer/hextool/selector"
+)
+
+var (
+	// selector of the Error(string) revert emitted by `require(condition, "reason")` and `revert("reason")`.
+	errorSelector = crypto.Keccak256([]byte("Error(string)"))[:4]
+	// selector of the Panic(uint256) revert emitted by failing assertions and checked arithmetic.
+	panicSelector = crypto.Keccak256([]byte("Panic(uint256)"))[:4]
+)
+
+// Meanings of the Panic(uint256) codes emitted by the Solidity compiler. See
+// https://docs.soliditylang.org/en/latest/control-structures.html#panic-via-assert-and-error-via-require
+var panicCodes = map[uint64]string{
+	0x00: "generic compiler inserted panic",
+	0x01: "assert(false): an assertion failed",
+	0x11: "arithmetic operation overflowed or underflowed outside of an unchecked block",
+	0x12: "division or modulo by zero",
+	0x21: "conversion of a value that is too big or negative into an enum type",
+	0x22: "access to an incorrectly encoded storage byte array",
+	0x31: "pop() on an empty array",
+	0x32: "array index out of bounds",
+	0x41: "too much memory allocated, or an array that is too large",
+	0x51: "call to a zero-initialized variable of internal function type",
+}
+
+// Revert is the decoded data of a reverted call.
+type Revert struct {
+	Sig  string // the signature of the error, eg: "Error(string)". Empty when the revert had no data.
+	Args []calldata.Arg
+}
+
+// Formats the revert as its error signature followed by one `name: value` line per argument.
+// Panic codes are followed by their meaning.
+func (r *Revert) String() string {
+	if r.Sig == "" {
+		return "empty revert data: revert() or require(condition) without a reason, or an out of gas error"
+	}
+
+	var sb strings.Builder
+	sb.WriteString(r.Sig)
+	for _, arg := range r.Args {
+		value := encdec.FormatValue(arg.Type, arg.Value)
+		if r.Sig == "Panic(uint256)" {
+			value = PanicReason(arg.Value.(*big.Int))
+		}
+		fmt.Fprintf(&sb, "\n  %s: %s", arg.Name, value)
+	}
+	return sb.String()
+}
+
+// Decodes the hex string `data` returned by a reverted call. The built in Error(string) and
+// Panic(uint256) errors are always recognized. Custom errors are looked up in the ABI from the
+// provided file path or URL, which are optional. If both are provided it will default to using the file path.
+func Decode(data string, abiPath string, abiUrl string) (*Revert, error) {
+	dataBytes, err := hexutil.Decode(data)
+	if err != nil {
+		return nil, fmt.Errorf("%w: revert data %q: %v", encdec.ErrInvalidHex, data, err)
+	}
+
+	var parsedAbi *abi.ABI
+	if abiPath != "" || abiUrl != "" {
+		if parsedAbi, err = selector.LoadABI(selector.NewAbiSource(abiPath, abiUrl)); err != nil {
+			return nil, err
+		}
+	}
+
+	return DecodeWithAbi(dataBytes, parsedAbi)
+}
+
+// Decodes revert data using an already parsed ABI, which may be nil. See Decode.
+func DecodeWithAbi(data []byte, parsedAbi *abi.ABI) (*Revert, error) {
+	if len(data) == 0 {
+		return &Revert{}, nil
+	}
+	if len(data) < 4 {
+		return nil, fmt.Errorf("%w: revert data must be empty or at least 4 bytes, got %d", encdec.ErrInvalidHex, len(data))
+	}
+
+	switch {
+	case bytes.Equal(data[:4], errorSelector):
+		return unpackBuiltin("Error(string)", "string reason", data[4:])
+	case bytes.Equal(data[:4], panicSelector):
+		return unpackBuiltin("Panic(uint256)", "uint256 code", data[4:])
+	}
+
+	if parsedAbi == nil {
+		return nil, fmt.Errorf("%w: custom error selector %s. Pass an ABI with --path or --url to decode it", selector.ErrSelectorNotFound, hexutil.Encode(data[:4]))
+	}
+
+	var id [4]byte
+	copy(id[:], data[:4])
+	customErr, err := parsedAbi.ErrorByID(id)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", selector.ErrSelectorNotFound, err)
+	}
+
+	values, err := customErr.Unpack(data)
+	if err != nil {
+		return nil, fmt.Errorf("%w: unpacking the arguments of %s: %v", encdec.ErrTypeMismatch, customErr.Sig, err)
+	}
+	return newRevert(customErr.Sig, customErr.Inputs, values.([]any)), nil
+}
+
+// PanicReason describes a Panic(uint256) code, eg: "0x11 (arithmetic operation overflowed ...)".
+func PanicReason(code *big.Int) string {
+	reason := "unknown panic code"
+	if code.IsUint64() {
+		if r, ok := panicCodes[code.Uint64()]; ok {
+			reason = r
+		}
+	}
+	return fmt.Sprintf("0x%02x (%s)", code, reason)
+}
+
+// Unpacks the arguments of the built in Error(string) and Panic(uint256) errors.
+func unpackBuiltin(sig string, dataType string, argData []byte) (*Revert, error) {
+	args, err := encdec.ParseTypes(dataType)
+	if err != nil {
+		return nil, err
+	}
+	values, err := args.Unpack(argData)
+	if err != nil {
+		return nil, fmt.Errorf("%w: unpacking the arguments of %s: %v", encdec.ErrTypeMismatch, sig, err)
+	}
+	return newRevert(sig, args, values), nil
+}
+
+func newRevert(sig string, inputs abi.Arguments, values []any) *Revert {
+	r := &Revert{Sig: sig, Args: make([]calldata.Arg, len(values))}
+	for idx, input := range inputs {
+		name := input.Name
+		if name == "" {
+			name = fmt.Sprintf("arg%d", idx)
+		}
+		r.Args[idx] = calldata.Arg{Name: name, Type: input.Type, Value: values[idx]}
+	}
+	return r
+}
@@ -0,0 +1,98 @@
+package revert
+
+import (
+	"errors"
+	"path"
+	"testing"
+
+	"github.com/zeuslawyer/hextool/encdec"
+	"github.com/zeuslawyer/hextool/selector"
+)
+
+func TestDecode(t *testing.T) {
+	errorsAbi := path.Join("testdata", "errors.abi.json")
+
+	tests := []struct {
+		name    string
+		data    string
+		path    string
+		want    string
+		wantErr error
+	}{
+		{
+			name: "Error(string) without an ABI",
+			data: "0x08c379a000000000000000000000000000000000000000000000000000000000000000200000000000000000000000000000000000000000000000000000000000000014696e73756666696369656e742062616c616e6365000000000000000000000000",
+			want: "Error(string)\n  reason: insufficient balance",
+		},
+		{
+			name: "Panic(uint256) overflow",
+			data: "0x4e487b710000000000000000000000000000000000000000000000000000000000000011",
+			want: "Panic(uint256)\n  code: 0x11 (arithmetic operation overflowed or underflowed outside of an unchecked block)",
+		},
+		{
+			name: "Panic(uint256) out of bounds",
+			data: "0x4e487b710000000000000000000000000000000000000000000000000000000000000032",
+			want: "Panic(uint256)\n  code: 0x32 (array index out of bounds)",
+		},
+		{
+			name: "Panic(uint256) unknown code",
+			data: "0x4e487b7100000000000000000000000000000000000000000000000000000000000000ff",
+			want: "Panic(uint256)\n  code: 0xff (unknown panic code)",
+		},
+		{
+			name: "custom error with arguments",
+			data: "0xcf47918100000000000000000000000000000000000000000000000000000000000003e800000000000000000000000000000000000000000000000000000000000007d0",
+			path: errorsAbi,
+			want: "InsufficientBalance(uint256,uint256)\n  available: 1000\n  required: 2000",
+		},
+		{
+			name: "custom error without arguments",
+			data: "0x82b42900",
+			path: errorsAbi,
+			want: "Unauthorized()",
+		},
+		{
+			name: "empty revert data",
+			data: "0x",
+			want: "empty revert data: revert() or require(condition) without a reason, or an out of gas error",
+		},
+		{
+			name:    "custom error without an ABI",
+			data:    "0x82b42900",
+			wantErr: selector.ErrSelectorNotFound,
+		},
+		{
+			name:    "custom error not in the ABI",
+			data:    "0xdeadbeef",
+			path:    errorsAbi,
+			wantErr: selector.ErrSelectorNotFound,
+		},
+		{
+			name:    "truncated Error(string)",
+			data:    "0x08c379a00000000000000000000000000000000000000000000000000000000000000020",
+			wantErr: encdec.ErrTypeMismatch,
+		},
+		{
+			name:    "shorter than a selector",
+			data:    "0x08c3",
+			wantErr: encdec.ErrInvalidHex,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := Decode(tc.data, tc.path, "")
+			if tc.wantErr != nil {
+				if !errors.Is(err, tc.wantErr) {
+					t.Errorf("Decode() error = %v, want %v", err, tc.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Decode() unexpected error: %v", err)
+			}
+			if got.String() != tc.want {
+				t.Errorf("Decode() = %q, want %q", got.String(), tc.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,11 @@
+package rlp
+
+import "errors"
+
+// Sentinel errors returned by this package. Callers can match them with errors.Is.
+// Hex input that is not valid hex wraps encdec.ErrInvalidHex.
+var (
+	// ErrInvalidRLP is returned when the input is not canonical RLP, or when a JSON value
+	// cannot be encoded as RLP.
+	ErrInvalidRLP = errors.New("invalid RLP")
+)
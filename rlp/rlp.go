@@ -0,0 +1,197 @@
+// Package rlp decodes and encodes RLP, the serialization of raw transactions, block headers
+// and CREATE addresses, as nested lists of byte strings.
+package rlp
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	gethrlp "github.com/ethereum/go-ethereum/rlp"
+	"github.com/zeuslawyer/hextool/encdec"
+)
+
+// The ways Format shows the byte strings of an item.
+const (
+	AsHex     = "hex"
+	AsInt     = "int"
+	AsAddress = "address"
+	AsString  = "string"
+)
+
+// An RLP item: a byte string, or a list of items.
+type Item struct {
+	IsList bool
+	Bytes  []byte // the byte string, when IsList is false.
+	Items  []Item // the items of the list, when IsList is true.
+}
+
+// Decodes the 0x-prefixed hex of a single RLP item. Non-canonical encodings, eg: a
+// single byte below 0x80 with a length prefix, and trailing bytes are rejected.
+func Decode(hexInput string) (Item, error) {
+	b, err := encdec.HexToBytes(hexInput)
+	if err != nil {
+		return Item{}, err
+	}
+	return DecodeBytes(b)
+}
+
+// Decodes a single RLP item, as Decode does.
+func DecodeBytes(b []byte) (Item, error) {
+	item, rest, err := decodeItem(b)
+	if err != nil {
+		return Item{}, err
+	}
+	if len(rest) != 0 {
+		return Item{}, fmt.Errorf("%w: %d trailing bytes after the first item", ErrInvalidRLP, len(rest))
+	}
+	return item, nil
+}
+
+// Decodes the first item of `b` and returns the bytes after it.
+func decodeItem(b []byte) (Item, []byte, error) {
+	kind, content, rest, err := gethrlp.Split(b)
+	if err != nil {
+		return Item{}, nil, fmt.Errorf("%w: %v", ErrInvalidRLP, err)
+	}
+	if kind != gethrlp.List {
+		return Item{Bytes: content}, rest, nil
+	}
+
+	list := Item{IsList: true, Items: []Item{}}
+	for len(content) > 0 {
+		var elem Item
+		if elem, content, err = decodeItem(content); err != nil {
+			return Item{}, nil, err
+		}
+		list.Items = append(list.Items, elem)
+	}
+	return list, rest, nil
+}
+
+// Returns the RLP encoding of the item.
+func (it Item) Encode() []byte {
+	b, err := gethrlp.EncodeToBytes(it.value())
+	if err != nil {
+		// byte strings and lists of them always encode.
+		panic(err)
+	}
+	return b
+}
+
+// Returns the item as the values go-ethereum encodes: []byte for strings and []any for lists.
+func (it Item) value() any {
+	if !it.IsList {
+		return it.Bytes
+	}
+	values := make([]any, len(it.Items))
+	for idx, elem := range it.Items {
+		values[idx] = elem.value()
+	}
+	return values
+}
+
+// Parses a JSON value into an item: arrays become lists, 0x-prefixed strings their hex
+// bytes, other strings their UTF-8 bytes, and non-negative integers their big-endian bytes
+// with no leading zeros, so 0 is the empty string, eg: `["0x6ac7ea33f8831ea9dcc53393aaa88b25a785dbf0", 1]`.
+func ParseJSON(input string) (Item, error) {
+	decoder := json.NewDecoder(strings.NewReader(input))
+	decoder.UseNumber()
+	var value any
+	if err := decoder.Decode(&value); err != nil {
+		return Item{}, fmt.Errorf("%w: %q is not valid JSON: %v", ErrInvalidRLP, input, err)
+	}
+	if decoder.More() {
+		return Item{}, fmt.Errorf("%w: %q holds more than one JSON value, wrap them in an array", ErrInvalidRLP, input)
+	}
+	return parseValue(value)
+}
+
+func parseValue(value any) (Item, error) {
+	switch v := value.(type) {
+	case []any:
+		list := Item{IsList: true, Items: make([]Item, len(v))}
+		for idx, elem := range v {
+			item, err := parseValue(elem)
+			if err != nil {
+				return Item{}, err
+			}
+			list.Items[idx] = item
+		}
+		return list, nil
+	case string:
+		if strings.HasPrefix(v, "0x") {
+			b, err := encdec.HexToBytes(v)
+			if err != nil {
+				return Item{}, err
+			}
+			return Item{Bytes: b}, nil
+		}
+		return Item{Bytes: []byte(v)}, nil
+	case json.Number:
+		n, ok := new(big.Int).SetString(v.String(), 10)
+		if !ok || n.Sign() < 0 {
+			return Item{}, fmt.Errorf("%w: %s is not a non-negative integer", ErrInvalidRLP, v)
+		}
+		return Item{Bytes: n.Bytes()}, nil
+	}
+	return Item{}, fmt.Errorf("%w: %v is not an array, string or integer", ErrInvalidRLP, value)
+}
+
+// Formats the item as an indented tree, one item per line, with the index of each item in
+// its list. Byte strings are shown as hex, or as `as` interprets them: AsInt shows strings of
+// up to 32 bytes as big-endian integers, AsAddress shows 20-byte strings as checksummed
+// addresses, and AsString shows valid, printable UTF-8 as quoted strings. Strings that cannot
+// be interpreted are shown as hex.
+func (it Item) Format(as string) (string, error) {
+	switch as {
+	case AsHex, AsInt, AsAddress, AsString:
+	default:
+		return "", fmt.Errorf("unknown interpretation %q, must be %q, %q, %q or %q", as, AsHex, AsInt, AsAddress, AsString)
+	}
+
+	var buf bytes.Buffer
+	it.format(&buf, as, "", "")
+	return strings.TrimRight(buf.String(), "\n"), nil
+}
+
+func (it Item) format(buf *bytes.Buffer, as string, indent string, label string) {
+	if !it.IsList {
+		fmt.Fprintf(buf, "%s%s%s\n", indent, label, formatBytes(it.Bytes, as))
+		return
+	}
+	noun := "items"
+	if len(it.Items) == 1 {
+		noun = "item"
+	}
+	fmt.Fprintf(buf, "%s%slist, %d %s\n", indent, label, len(it.Items), noun)
+	for idx, elem := range it.Items {
+		elem.format(buf, as, indent+"  ", fmt.Sprintf("[%d] ", idx))
+	}
+}
+
+func formatBytes(b []byte, as string) string {
+	switch {
+	case as == AsInt && len(b) <= 32:
+		return new(big.Int).SetBytes(b).String()
+	case as == AsAddress && len(b) == common.AddressLength:
+		return common.BytesToAddress(b).Hex()
+	case as == AsString && len(b) > 0 && utf8.Valid(b) && isPrintable(string(b)):
+		return fmt.Sprintf("%q", b)
+	}
+	return hexutil.Encode(b)
+}
+
+func isPrintable(s string) bool {
+	for _, r := range s {
+		if r < 0x20 || r == 0x7f {
+			return false
+		}
+	}
+	return true
+}
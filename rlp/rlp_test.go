@@ -0,0 +1,140 @@
+package rlp
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/zeuslawyer/hextool/encdec"
+)
+
+// The vectors of the RLP specification, https://ethereum.org/en/developers/docs/data-structures-and-encoding/rlp/
+func TestEncodeDecode(t *testing.T) {
+	tests := []struct {
+		name string
+		json string
+		want string
+	}{
+		{name: "string", json: `"dog"`, want: "0x83646f67"},
+		{name: "list", json: `["cat", "dog"]`, want: "0xc88363617483646f67"},
+		{name: "empty string", json: `""`, want: "0x80"},
+		{name: "empty list", json: `[]`, want: "0xc0"},
+		{name: "zero", json: `0`, want: "0x80"},
+		{name: "single byte", json: `"0x0f"`, want: "0x0f"},
+		{name: "integer", json: `1024`, want: "0x820400"},
+		{name: "set theoretical representation of three", json: `[[], [[]], [[], [[]]]]`, want: "0xc7c0c1c0c3c0c1c0"},
+		{
+			name: "long string",
+			json: `"Lorem ipsum dolor sit amet, consectetur adipisicing elit"`,
+			want: "0xb8384c6f72656d20697073756d20646f6c6f722073697420616d65742c20636f6e7365637465747572206164697069736963696e6720656c6974",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			item, err := ParseJSON(tc.json)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := hexutil.Encode(item.Encode()); got != tc.want {
+				t.Errorf("Encode(%s) = %s, want %s", tc.json, got, tc.want)
+			}
+
+			decoded, err := Decode(tc.want)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := hexutil.Encode(decoded.Encode()); got != tc.want {
+				t.Errorf("Decode(%s) encodes back to %s", tc.want, got)
+			}
+		})
+	}
+}
+
+// The address of a contract deployed with CREATE is the last 20 bytes of the keccak256 of
+// the RLP list of its deployer and the deployer's nonce.
+func TestCreateAddress(t *testing.T) {
+	item, err := ParseJSON(`["0x6ac7ea33f8831ea9dcc53393aaa88b25a785dbf0", 1]`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "0x343c43a37d37dff08ae8c4a11544c718abb4fcf8"
+	if got := hexutil.Encode(crypto.Keccak256(item.Encode())[12:]); got != want {
+		t.Errorf("CREATE address = %s, want %s", got, want)
+	}
+}
+
+func TestFormat(t *testing.T) {
+	// [nonce, to, value, data], with a nested list.
+	item, err := ParseJSON(`[9, "0x208aa722aca42399eac5192ee778e4d42f4e5de3", ["hextool", "0x"], "0xff"]`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		as   string
+		want string
+	}{
+		{
+			as:   AsHex,
+			want: "list, 4 items\n  [0] 0x09\n  [1] 0x208aa722aca42399eac5192ee778e4d42f4e5de3\n  [2] list, 2 items\n    [0] 0x686578746f6f6c\n    [1] 0x\n  [3] 0xff",
+		},
+		{
+			as:   AsInt,
+			want: "list, 4 items\n  [0] 9\n  [1] 185779767054590644080798281127607192692473093603\n  [2] list, 2 items\n    [0] 29384965601849196\n    [1] 0\n  [3] 255",
+		},
+		{
+			as:   AsAddress,
+			want: "list, 4 items\n  [0] 0x09\n  [1] 0x208AA722Aca42399eaC5192EE778e4D42f4E5De3\n  [2] list, 2 items\n    [0] 0x686578746f6f6c\n    [1] 0x\n  [3] 0xff",
+		},
+		{
+			as:   AsString,
+			want: "list, 4 items\n  [0] 0x09\n  [1] 0x208aa722aca42399eac5192ee778e4d42f4e5de3\n  [2] list, 2 items\n    [0] \"hextool\"\n    [1] 0x\n  [3] 0xff",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.as, func(t *testing.T) {
+			got, err := item.Format(tc.as)
+			if err != nil || got != tc.want {
+				t.Errorf("Format(%q) = %q, %v, want %q", tc.as, got, err, tc.want)
+			}
+		})
+	}
+
+	if _, err := item.Format("base64"); err == nil {
+		t.Errorf("Format(%q) error = nil, want an unknown interpretation error", "base64")
+	}
+}
+
+func TestDecodeErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		hex     string
+		wantErr error
+		wantMsg string
+	}{
+		{name: "non-canonical single byte", hex: "0x8100", wantErr: ErrInvalidRLP, wantMsg: "non-canonical"},
+		{name: "truncated list", hex: "0xc88363617483646f", wantErr: ErrInvalidRLP, wantMsg: "value size exceeds available input length"},
+		{name: "trailing bytes", hex: "0x83646f6701", wantErr: ErrInvalidRLP, wantMsg: "1 trailing bytes"},
+		{name: "empty input", hex: "0x", wantErr: ErrInvalidRLP, wantMsg: "EOF"},
+		{name: "invalid hex", hex: "0xzz", wantErr: encdec.ErrInvalidHex},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := Decode(tc.hex)
+			if !errors.Is(err, tc.wantErr) || !strings.Contains(err.Error(), tc.wantMsg) {
+				t.Errorf("Decode(%s) error = %v, want %v containing %q", tc.hex, err, tc.wantErr, tc.wantMsg)
+			}
+		})
+	}
+
+	for _, input := range []string{`-1`, `1.5`, `{"a": 1}`, `true`, `[1] [2]`} {
+		if _, err := ParseJSON(input); !errors.Is(err, ErrInvalidRLP) {
+			t.Errorf("ParseJSON(%s) error = %v, want %v", input, err, ErrInvalidRLP)
+		}
+	}
+}
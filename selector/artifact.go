@@ -0,0 +1,222 @@
+package selector
+
+import (
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+)
+
+// A parsed ABI and the name of the contract it belongs to.
+type parsedContract struct {
+	Name string
+	Abi  *abi.ABI
+	Json []byte // the ABI array the contract was parsed from.
+}
+
+// Parses the ABI of each contract in `doc`. Contracts whose name the document does not
+// record, eg: in Foundry artifacts, are named after the file without its extensions,
+// eg: "ERC20" for "out/ERC20.sol/ERC20.json".
+func parseContractAbis(doc AbiDocument) ([]parsedContract, error) {
+	if err := sniffJSON(doc); err != nil {
+		return nil, err
+	}
+	abis, err := unmarshalAbis(doc.Data, doc.Name)
+	if err != nil {
+		return nil, err
+	}
+
+	contracts := make([]parsedContract, len(abis))
+	for idx, c := range abis {
+		abiJson, err := json.Marshal(c.Abi)
+		if err != nil {
+			return nil, fmt.Errorf("%w: marshalling ABI data to JSON bytes: %s", ErrInvalidAbi, err)
+		}
+		parsedAbi, err := abi.JSON(strings.NewReader(string(abiJson)))
+		if err != nil {
+			return nil, fmt.Errorf("%w: parsing ABI from %s: %v", ErrInvalidAbi, doc.Name, err)
+		}
+
+		name := c.Name
+		if name == "" {
+			name = filepath.Base(doc.Name)
+			if idx := strings.Index(name, "."); idx > 0 {
+				name = name[:idx]
+			}
+		}
+		contracts[idx] = parsedContract{Name: name, Abi: &parsedAbi, Json: abiJson}
+	}
+	return contracts, nil
+}
+
+// Returns the signatures of the functions, events and errors of `contract`, with the
+// contract's name as their source. Anonymous events are skipped, as they have no topic hash.
+func contractSignatures(contract parsedContract) []Signature {
+	var sigs []Signature
+	for _, method := range contract.Abi.Methods {
+		sigs = append(sigs, Signature{Kind: KindFunction, Text: method.Sig, Source: contract.Name})
+	}
+	for _, ev := range contract.Abi.Events {
+		if !ev.Anonymous {
+			sigs = append(sigs, Signature{Kind: KindEvent, Text: ev.Sig, Source: contract.Name})
+		}
+	}
+	for _, abiErr := range contract.Abi.Errors {
+		sigs = append(sigs, Signature{Kind: KindError, Text: abiErr.Sig, Source: contract.Name})
+	}
+	return sigs
+}
+
+// The ABI of one contract found in a JSON document, with the contract's name when the
+// document records it.
+type contractAbi struct {
+	Name string
+	Abi  []any
+}
+
+// Finds the ABIs in `data`, a decoded JSON document. The supported shapes are:
+//   - a bare ABI array, or an ethers-style human-readable ABI: an array of Solidity
+//     declarations, eg: ["function transfer(address to, uint amount) returns (bool)"].
+//   - an object with an `abi` property: Foundry, Hardhat and Truffle artifacts.
+//     Hardhat and Truffle artifacts also name the contract in `contractName`.
+//   - solc `--combined-json abi` output: `contracts["file.sol:Name"].abi`.
+//   - solc `--standard-json` output: `contracts["file.sol"]["Name"].abi`.
+//   - Etherscan's `getabi` response, whose `result` holds the ABI.
+//
+// An ABI, or the whole document, may also be a string holding JSON, as in older solc
+// combined-json output and Etherscan responses.
+func findAbis(data any, abiSourceUri string) ([]contractAbi, error) {
+	switch v := data.(type) {
+	case []any:
+		abiData, err := fromHumanReadable(v, abiSourceUri)
+		if err != nil {
+			return nil, err
+		}
+		return []contractAbi{{Abi: abiData}}, nil
+	case string:
+		var decoded any
+		if err := json.Unmarshal([]byte(v), &decoded); err != nil {
+			return nil, fmt.Errorf("%w: Data in file at %s is a string that does not hold JSON: %s", ErrInvalidAbi, abiSourceUri, err)
+		}
+		return findAbis(decoded, abiSourceUri)
+	case map[string]any:
+		if d, ok := v["abi"]; ok {
+			abiData, err := abiArray(d)
+			if err != nil {
+				return nil, fmt.Errorf("%w: value of property 'abi' in the file at %s is not an array", ErrInvalidAbi, abiSourceUri)
+			}
+			if abiData, err = fromHumanReadable(abiData, abiSourceUri); err != nil {
+				return nil, err
+			}
+			name, _ := v["contractName"].(string)
+			return []contractAbi{{Name: name, Abi: abiData}}, nil
+		}
+		if contracts, ok := v["contracts"].(map[string]any); ok {
+			return solcAbis(contracts, abiSourceUri)
+		}
+		if result, ok := v["result"]; ok {
+			if status, _ := v["status"].(string); status == "0" {
+				return nil, fmt.Errorf("%w: the response at %s is an error: %v", ErrInvalidAbi, abiSourceUri, result)
+			}
+			return findAbis(result, abiSourceUri)
+		}
+		return nil, fmt.Errorf("%w: Property 'abi' not found in unmarshalled JSON data. Check the file at %s", ErrInvalidAbi, abiSourceUri)
+	default:
+		return nil, fmt.Errorf("%w: Data in file at %s is neither an array nor an object", ErrInvalidAbi, abiSourceUri)
+	}
+}
+
+// Returns the ABI array held by the `abi` property value `d`, which may be string-encoded.
+func abiArray(d any) ([]any, error) {
+	if s, ok := d.(string); ok {
+		var decoded any
+		if err := json.Unmarshal([]byte(s), &decoded); err != nil {
+			return nil, err
+		}
+		d = decoded
+	}
+	abiData, ok := d.([]any)
+	if !ok {
+		return nil, fmt.Errorf("not an array")
+	}
+	return abiData, nil
+}
+
+// Converts `abiData` into JSON ABI entries if it is an ethers-style human-readable ABI,
+// otherwise returns it as is.
+func fromHumanReadable(abiData []any, abiSourceUri string) ([]any, error) {
+	entries, ok, err := humanReadableAbi(abiData)
+	if !ok {
+		return abiData, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%w: the human-readable ABI in the file at %s: %v", ErrInvalidAbi, abiSourceUri, err)
+	}
+	return entries, nil
+}
+
+// Returns the ABIs in the `contracts` object of solc's combined-json or standard-json output,
+// sorted by contract name.
+func solcAbis(contracts map[string]any, abiSourceUri string) ([]contractAbi, error) {
+	var abis []contractAbi
+	for key, value := range contracts {
+		contract, ok := value.(map[string]any)
+		if !ok {
+			return nil, fmt.Errorf("%w: contract %q in the file at %s is not an object", ErrInvalidAbi, key, abiSourceUri)
+		}
+
+		if d, ok := contract["abi"]; ok { // combined-json, keyed by "file.sol:Name".
+			abiData, err := abiArray(d)
+			if err != nil {
+				return nil, fmt.Errorf("%w: value of property 'abi' of contract %q in the file at %s is not an array", ErrInvalidAbi, key, abiSourceUri)
+			}
+			abis = append(abis, contractAbi{Name: key[strings.LastIndex(key, ":")+1:], Abi: abiData})
+			continue
+		}
+
+		for name, nested := range contract { // standard-json, keyed by file then name.
+			nestedContract, ok := nested.(map[string]any)
+			if !ok {
+				continue
+			}
+			d, ok := nestedContract["abi"]
+			if !ok {
+				continue
+			}
+			abiData, err := abiArray(d)
+			if err != nil {
+				return nil, fmt.Errorf("%w: value of property 'abi' of contract %q in the file at %s is not an array", ErrInvalidAbi, key+":"+name, abiSourceUri)
+			}
+			abis = append(abis, contractAbi{Name: name, Abi: abiData})
+		}
+	}
+	if len(abis) == 0 {
+		return nil, fmt.Errorf("%w: no contract with an 'abi' property in the file at %s", ErrInvalidAbi, abiSourceUri)
+	}
+
+	sort.Slice(abis, func(i, j int) bool { return abis[i].Name < abis[j].Name })
+	return abis, nil
+}
+
+// Joins the entries of `abis` into one ABI array, dropping entries that appear
+// in more than one contract, eg: a function inherited from a common base contract.
+func mergeAbiArrays(abis []contractAbi) ([]any, error) {
+	merged := []any{}
+	seen := map[string]bool{}
+	for _, c := range abis {
+		for _, entry := range c.Abi {
+			b, err := json.Marshal(entry)
+			if err != nil {
+				return nil, err
+			}
+			if !seen[string(b)] {
+				seen[string(b)] = true
+				merged = append(merged, entry)
+			}
+		}
+	}
+	return merged, nil
+}
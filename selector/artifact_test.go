@@ -0,0 +1,148 @@
+package selector
+
+import (
+	"errors"
+	"path"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestArtifactFormats(t *testing.T) {
+	var (
+		transfer     = "transfer(address,uint256)"
+		transferEv   = "Transfer(address,address,uint256)"
+		pause        = "pause()"
+		unauthorized = "Unauthorized(address)"
+	)
+
+	tests := []struct {
+		name        string
+		file        string
+		want        []Signature // sorted as Registry.List sorts them.
+		wantErr     error
+		wantErrText string
+	}{
+		{
+			name: "foundry - named after the file",
+			file: "foundry.json",
+			want: []Signature{
+				{Kind: KindFunction, Text: transfer, Source: "foundry"},
+				{Kind: KindEvent, Text: transferEv, Source: "foundry"},
+			},
+		},
+		{
+			name: "hardhat",
+			file: "hardhat.json",
+			want: []Signature{
+				{Kind: KindFunction, Text: transfer, Source: "Token"},
+				{Kind: KindEvent, Text: transferEv, Source: "Token"},
+			},
+		},
+		{
+			name: "truffle",
+			file: "truffle.json",
+			want: []Signature{
+				{Kind: KindFunction, Text: pause, Source: "Pausable"},
+				{Kind: KindError, Text: unauthorized, Source: "Pausable"},
+			},
+		},
+		{
+			name: "solc combined-json, with a string-encoded abi",
+			file: "solc-combined.json",
+			want: []Signature{
+				{Kind: KindFunction, Text: pause, Source: "Pausable"},
+				{Kind: KindFunction, Text: transfer, Source: "Pausable"},
+				{Kind: KindFunction, Text: transfer, Source: "Token"},
+				{Kind: KindError, Text: unauthorized, Source: "Pausable"},
+				{Kind: KindEvent, Text: transferEv, Source: "Token"},
+			},
+		},
+		{
+			name: "solc standard-json",
+			file: "solc-standard.json",
+			want: []Signature{
+				{Kind: KindFunction, Text: pause, Source: "Pausable"},
+				{Kind: KindFunction, Text: transfer, Source: "Token"},
+				{Kind: KindError, Text: unauthorized, Source: "Pausable"},
+				{Kind: KindEvent, Text: transferEv, Source: "Token"},
+			},
+		},
+		{
+			name: "etherscan getabi",
+			file: "etherscan-getabi.json",
+			want: []Signature{
+				{Kind: KindFunction, Text: pause, Source: "etherscan-getabi"},
+				{Kind: KindFunction, Text: transfer, Source: "etherscan-getabi"},
+				{Kind: KindEvent, Text: transferEv, Source: "etherscan-getabi"},
+			},
+		},
+		{
+			name: "ethers human-readable ABI",
+			file: "ethers.json",
+			want: []Signature{
+				{Kind: KindFunction, Text: transfer, Source: "ethers"},
+				{Kind: KindEvent, Text: transferEv, Source: "ethers"},
+			},
+		},
+		{
+			name:        "etherscan error",
+			file:        "etherscan-unverified.json",
+			wantErr:     ErrInvalidAbi,
+			wantErrText: "Contract source code not verified",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			file := path.Join("testdata", "formats", tc.file)
+			got, err := SignaturesFromArtifact(file)
+			if tc.wantErr != nil {
+				if !errors.Is(err, tc.wantErr) || !strings.Contains(err.Error(), tc.wantErrText) {
+					t.Errorf("error = %v, want %v containing %q", err, tc.wantErr, tc.wantErrText)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			r, _ := OpenRegistry(filepath.Join(t.TempDir(), "signatures.json"))
+			r.Add(got...)
+			if !reflect.DeepEqual(r.List(), tc.want) {
+				t.Errorf("signatures = %v, want %v", r.List(), tc.want)
+			}
+
+			// every selector command can load the same file, with the contracts' ABIs merged.
+			selector, _ := FromSig(tc.want[0].Text)
+			sig, err := MethodSig(selector, file, "")
+			if err != nil || sig != tc.want[0].Text {
+				t.Errorf("MethodSig(%s) = %q, %v, want %s", selector, sig, err, tc.want[0].Text)
+			}
+		})
+	}
+}
+
+func TestFindAbisErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		json    string
+		wantErr string
+	}{
+		{name: "object without abi", json: `{"bytecode": "0x"}`, wantErr: "Property 'abi' not found"},
+		{name: "abi is not an array", json: `{"abi": {}}`, wantErr: "value of property 'abi' in the file at inline JSON is not an array"},
+		{name: "abi string is not JSON", json: `{"abi": "[{"}`, wantErr: "value of property 'abi' in the file at inline JSON is not an array"},
+		{name: "solc contract without abi", json: `{"contracts": {"A.sol:A": {"bin": "00"}}}`, wantErr: "no contract with an 'abi' property"},
+		{name: "solc contract abi is not an array", json: `{"contracts": {"A.sol:A": {"abi": 1}}}`, wantErr: `contract "A.sol:A"`},
+		{name: "number", json: `1`, wantErr: "does not contain JSON"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := LoadABI(InlineSource{JSON: tc.json})
+			if !errors.Is(err, ErrInvalidAbi) || !strings.Contains(err.Error(), tc.wantErr) {
+				t.Errorf("error = %v, want %v containing %q", err, ErrInvalidAbi, tc.wantErr)
+			}
+		})
+	}
+}
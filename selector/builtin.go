@@ -0,0 +1,264 @@
+package selector
+
+import (
+	"bufio"
+	_ "embed"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/zeuslawyer/hextool/encdec"
+)
+
+// The kind of declaration a signature belongs to. Functions and errors are
+// identified by a 4 byte selector, events by a 32 byte topic hash.
+type SigKind int
+
+const (
+	KindFunction SigKind = iota
+	KindError
+	KindEvent
+)
+
+func (k SigKind) String() string {
+	switch k {
+	case KindFunction:
+		return "function"
+	case KindError:
+		return "error"
+	case KindEvent:
+		return "event"
+	}
+	return fmt.Sprintf("SigKind(%d)", int(k))
+}
+
+// Parses the keyword used for `k` in signature files, eg: "function".
+func parseSigKind(s string) (SigKind, error) {
+	for _, k := range []SigKind{KindFunction, KindError, KindEvent} {
+		if k.String() == s {
+			return k, nil
+		}
+	}
+	return 0, fmt.Errorf("unknown signature kind %q", s)
+}
+
+func (k SigKind) MarshalText() ([]byte, error) {
+	return []byte(k.String()), nil
+}
+
+func (k *SigKind) UnmarshalText(text []byte) error {
+	kind, err := parseSigKind(string(text))
+	if err != nil {
+		return err
+	}
+	*k = kind
+	return nil
+}
+
+// A known signature, eg: "transfer(address,uint256)", with the contract or
+// standard it comes from, eg: "ERC-20".
+type Signature struct {
+	Kind   SigKind `json:"kind"`
+	Text   string  `json:"signature"`
+	Source string  `json:"source"`
+}
+
+// Returns the selector (for functions and errors) or topic hash (for events) of the signature.
+// The error wraps ErrInvalidSignature when the text is malformed. SignatureDB, Registry and
+// DirIndex call it once, when a signature is added, and keep the result for lookups.
+func (s Signature) ID() (string, error) {
+	hash, err := sigHash(s.Text, s.Kind)
+	if err != nil {
+		return "", err
+	}
+	if s.Kind == KindEvent {
+		return hash.Hex(), nil
+	}
+	return hash.Hex()[:10], nil
+}
+
+// A set of signatures indexed by their selector or topic hash.
+type SignatureDB struct {
+	byID map[string][]Signature // keyed by kind and lowercase hex id, see dbKey.
+}
+
+func NewSignatureDB() *SignatureDB {
+	return &SignatureDB{byID: map[string][]Signature{}}
+}
+
+func dbKey(kind SigKind, id string) string {
+	return kind.String() + " " + strings.ToLower(id)
+}
+
+// Adds `sig` to the database. Adding a signature that is already present merges
+// its source into the existing entry, so that one signature is never listed twice.
+// The error wraps ErrInvalidSignature when the text of `sig` is malformed.
+func (db *SignatureDB) Add(sig Signature) error {
+	id, err := sig.ID()
+	if err != nil {
+		return err
+	}
+	key := dbKey(sig.Kind, id)
+	db.byID[key] = mergeSig(db.byID[key], sig)
+	return nil
+}
+
+// Adds `sig` to `sigs`, which share its selector or topic hash, unless a signature with
+// the same text is there already, in which case the source of `sig` is merged into it.
+func mergeSig(sigs []Signature, sig Signature) []Signature {
+	for idx, existing := range sigs {
+		if existing.Text == sig.Text {
+			if sig.Source != "" && !strings.Contains(existing.Source, sig.Source) {
+				sigs[idx].Source = existing.Source + ", " + sig.Source
+			}
+			return sigs
+		}
+	}
+	return append(sigs, sig)
+}
+
+// Returns every signature of `kind` whose selector or topic hash is `id`,
+// sorted by text. More than one signature means the selector collides.
+func (db *SignatureDB) Lookup(kind SigKind, id string) []Signature {
+	matches := append([]Signature(nil), db.byID[dbKey(kind, id)]...)
+	sort.Slice(matches, func(i, j int) bool { return matches[i].Text < matches[j].Text })
+	return matches
+}
+
+// Returns all signatures in the database, sorted by kind and text.
+func (db *SignatureDB) All() []Signature {
+	var all []Signature
+	for _, sigs := range db.byID {
+		all = append(all, sigs...)
+	}
+	sort.Slice(all, func(i, j int) bool {
+		if all[i].Kind != all[j].Kind {
+			return all[i].Kind < all[j].Kind
+		}
+		return all[i].Text < all[j].Text
+	})
+	return all
+}
+
+//go:embed data/builtin_signatures.txt
+var builtinSignaturesTxt string
+
+var (
+	builtinOnce sync.Once
+	builtinDB   *SignatureDB
+)
+
+// Returns the signature database embedded in hextool. It covers common token
+// standards (ERC-20, 721, 1155, 4626), Uniswap, Permit2, Safe, proxies and
+// OpenZeppelin's custom errors, and is used to decode selectors and topic
+// hashes when no ABI is given.
+func BuiltinSignatures() *SignatureDB {
+	builtinOnce.Do(func() {
+		db, err := parseSignatureList(builtinSignaturesTxt)
+		if err != nil {
+			panic(fmt.Sprintf("parsing built-in signatures: %v", err))
+		}
+		builtinDB = db
+	})
+	return builtinDB
+}
+
+// Parses a signature list. Lines starting with "## " set the source of the
+// signatures that follow, other lines starting with "#" and blank lines are
+// ignored, and each remaining line is a kind followed by a signature,
+// eg: "event Transfer(address,address,uint256)".
+func parseSignatureList(list string) (*SignatureDB, error) {
+	db := NewSignatureDB()
+	source := ""
+
+	scanner := bufio.NewScanner(strings.NewReader(list))
+	for lineNum := 1; scanner.Scan(); lineNum++ {
+		line := strings.TrimSpace(scanner.Text())
+		switch {
+		case strings.HasPrefix(line, "## "):
+			source = strings.TrimSpace(line[3:])
+			continue
+		case line == "" || strings.HasPrefix(line, "#"):
+			continue
+		}
+
+		fields := strings.Fields(line)
+		if len(fields) != 2 {
+			return nil, fmt.Errorf("line %d: expected a kind and a signature, got %q", lineNum, line)
+		}
+		kind, err := parseSigKind(fields[0])
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %v", lineNum, err)
+		}
+		if err := db.Add(Signature{Kind: kind, Text: fields[1], Source: source}); err != nil {
+			return nil, fmt.Errorf("line %d: %w", lineNum, err)
+		}
+	}
+	return db, scanner.Err()
+}
+
+// Returns the signatures of `kind` whose selector (for functions and errors) or topic hash
+// (for events) is `id`, one per line. The built-in signature database and the user's registry
+// are searched first. If neither has a match and `resolver` is not nil, `resolver` is asked.
+// The error wraps ErrSelectorNotFound when no signature matches.
+func ResolveSig(kind SigKind, id string, resolver SignatureResolver) (string, error) {
+	id, err := normalizeID(kind, id)
+	if err != nil {
+		return "", err
+	}
+
+	registry, err := OpenDefaultRegistry()
+	if err != nil {
+		return "", err
+	}
+
+	matches := BuiltinSignatures().Lookup(kind, id)
+	for _, sig := range registry.Lookup(kind, id) {
+		matches = mergeSig(matches, sig)
+	}
+
+	if len(matches) == 0 && resolver != nil {
+		resolved, err := resolver.Resolve(kind, id)
+		if err != nil {
+			return "", err
+		}
+		for _, sig := range resolved {
+			matches = mergeSig(matches, sig)
+		}
+	}
+	sort.Slice(matches, func(i, j int) bool { return matches[i].Text < matches[j].Text })
+
+	if len(matches) == 0 {
+		where := "built-in or registered"
+		if resolver != nil {
+			where = "built-in, registered or resolved"
+		}
+		return "", fmt.Errorf("%w: no %s %s signature matches %s. Pass --path or --url to use an ABI", ErrSelectorNotFound, where, kind, id)
+	}
+	texts := make([]string, len(matches))
+	for idx, match := range matches {
+		texts[idx] = match.Text
+	}
+	return strings.Join(texts, "\n"), nil
+}
+
+// Validates `id` and returns it in the form Signature.ID returns: the first 4 bytes
+// of a selector, or a 32 byte topic hash, as lowercase 0x-prefixed hex.
+func normalizeID(kind SigKind, id string) (string, error) {
+	if kind == KindEvent {
+		topicBytes, err := hexutil.Decode(id)
+		if err != nil {
+			return "", fmt.Errorf("%w: topic hash %q: %v", encdec.ErrInvalidHex, id, err)
+		}
+		return common.BytesToHash(topicBytes).Hex(), nil
+	}
+
+	selectorBytes, err := selectorToBytes(id)
+	if err != nil {
+		return "", err
+	}
+	return hexutil.Encode(selectorBytes[:]), nil
+}
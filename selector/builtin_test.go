@@ -0,0 +1,156 @@
+package selector
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/zeuslawyer/hextool/encdec"
+)
+
+// Every built-in signature must be canonical, or its selector will not match the one
+// solc computes: no spaces or parameter names, and `uint256` rather than `uint`.
+func TestBuiltinSignaturesAreCanonical(t *testing.T) {
+	all := BuiltinSignatures().All()
+	if len(all) == 0 {
+		t.Fatal("no built-in signatures")
+	}
+
+	for _, sig := range all {
+		open := strings.Index(sig.Text, "(")
+		params := sig.Text[open+1 : len(sig.Text)-1]
+		args, err := encdec.ParseTypes(params)
+		if err != nil {
+			t.Errorf("%s %s: %v", sig.Kind, sig.Text, err)
+			continue
+		}
+
+		types := make([]string, len(args))
+		for idx, arg := range args {
+			types[idx] = arg.Type.String()
+		}
+		if canonical := sig.Text[:open] + "(" + strings.Join(types, ",") + ")"; canonical != sig.Text {
+			t.Errorf("%s %s is not canonical, want %s", sig.Kind, sig.Text, canonical)
+		}
+		if sig.Source == "" {
+			t.Errorf("%s %s has no source", sig.Kind, sig.Text)
+		}
+
+		// ID hashes the signature as the commands that hash signatures do.
+		want, _ := FromSig(sig.Text)
+		if sig.Kind == KindEvent {
+			want, _ = TopicFromSig(sig.Text)
+		}
+		if id, err := sig.ID(); err != nil || id != want {
+			t.Errorf("%s %s: ID() = %s, %v, want %s", sig.Kind, sig.Text, id, err, want)
+		}
+	}
+}
+
+func TestBuiltinLookup(t *testing.T) {
+	tests := []struct {
+		name        string
+		kind        SigKind
+		id          string
+		wantTexts   []string
+		wantSources []string
+	}{
+		{
+			name:        "function",
+			kind:        KindFunction,
+			id:          "0xa9059cbb",
+			wantTexts:   []string{"transfer(address,uint256)"},
+			wantSources: []string{"ERC-20"},
+		},
+		{
+			name:        "uppercase hex",
+			kind:        KindFunction,
+			id:          "0xA9059CBB",
+			wantTexts:   []string{"transfer(address,uint256)"},
+			wantSources: []string{"ERC-20"},
+		},
+		{
+			name:        "collision",
+			kind:        KindFunction,
+			id:          "0x42966c68",
+			wantTexts:   []string{"burn(uint256)", "collate_propagate_storage(bytes16)"},
+			wantSources: []string{"ERC-20", "Known selector collisions"},
+		},
+		{
+			name:        "event shared by several standards",
+			kind:        KindEvent,
+			id:          "0xddf252ad1be2c89b69c2b068fc378daa952ba7f163c4a11628f55a4df523b3ef",
+			wantTexts:   []string{"Transfer(address,address,uint256)"},
+			wantSources: []string{"ERC-20, ERC-721"},
+		},
+		{
+			name:        "solidity panic",
+			kind:        KindError,
+			id:          "0x4e487b71",
+			wantTexts:   []string{"Panic(uint256)"},
+			wantSources: []string{"Solidity"},
+		},
+		{
+			name: "wrong kind",
+			kind: KindEvent,
+			id:   "0xa9059cbb",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got := BuiltinSignatures().Lookup(tc.kind, tc.id)
+			if len(got) != len(tc.wantTexts) {
+				t.Fatalf("Lookup(%s, %s) = %v, want %v", tc.kind, tc.id, got, tc.wantTexts)
+			}
+			for idx, sig := range got {
+				if sig.Text != tc.wantTexts[idx] || sig.Source != tc.wantSources[idx] {
+					t.Errorf("Lookup(%s, %s)[%d] = %s from %q, want %s from %q",
+						tc.kind, tc.id, idx, sig.Text, sig.Source, tc.wantTexts[idx], tc.wantSources[idx])
+				}
+			}
+		})
+	}
+}
+
+func TestParseSignatureList(t *testing.T) {
+	tests := []struct {
+		name    string
+		list    string
+		wantErr string
+	}{
+		{
+			name: "valid",
+			list: "# comment\n\n## Source\nfunction foo(uint256)\nevent Bar()\n",
+		},
+		{
+			name:    "unknown kind",
+			list:    "method foo(uint256)",
+			wantErr: `line 1: unknown signature kind "method"`,
+		},
+		{
+			name:    "missing kind",
+			list:    "## Source\nfoo(uint256)",
+			wantErr: "line 2: expected a kind and a signature",
+		},
+		{
+			name:    "malformed signature",
+			list:    "function foo(uint256",
+			wantErr: "line 1: invalid signature",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := parseSignatureList(tc.list)
+			if tc.wantErr == "" {
+				if err != nil {
+					t.Errorf("unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tc.wantErr) {
+				t.Errorf("error = %v, want it to contain %q", err, tc.wantErr)
+			}
+		})
+	}
+}
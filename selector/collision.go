@@ -0,0 +1,187 @@
+package selector
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// The admin functions that known proxy patterns add to the contract users call, keyed
+// by the pattern's name. An implementation or facet function whose selector matches
+// one of these, but whose signature does not, is shadowed by the proxy.
+var ProxyAdminFunctions = map[string][]string{
+	// OpenZeppelin's TransparentUpgradeableProxy, before and after v5.
+	"transparent": {"admin()", "implementation()", "changeAdmin(address)", "upgradeTo(address)", "upgradeToAndCall(address,bytes)"},
+	// ERC-1822 and OpenZeppelin's UUPSUpgradeable.
+	"uups": {"proxiableUUID()", "upgradeTo(address)", "upgradeToAndCall(address,bytes)"},
+	// ERC-2535 diamonds, with the loupe functions.
+	"diamond": {
+		"diamondCut((address,uint8,bytes4[])[],address,bytes)",
+		"facets()",
+		"facetFunctionSelectors(address)",
+		"facetAddresses()",
+		"facetAddress(bytes4)",
+	},
+}
+
+// A function and the contract that defines it.
+type ContractFunction struct {
+	Selector  string
+	Signature string
+	Contract  ContractRef
+}
+
+// Functions with different signatures that share a selector.
+type Collision struct {
+	Selector  string
+	Functions []ContractFunction // sorted by signature, then contract.
+}
+
+// Returns every function in the ABIs of `src` with the contract that defines it, so
+// that functions merged from several contracts can be told apart. Contracts in
+// directories are found with IndexDir, and their files are relative to the directory.
+func ContractFunctions(src AbiSource) ([]ContractFunction, error) {
+	if src == nil {
+		return nil, fmt.Errorf("%w: abiPath and url cannot both be empty", ErrNoAbiSource)
+	}
+
+	var functions []ContractFunction
+	if dirSrc, ok := src.(DirSource); ok {
+		idx, err := IndexDir(dirSrc.Dir)
+		if err != nil {
+			return nil, err
+		}
+		for rel, f := range idx.Files {
+			for _, contract := range f.Contracts {
+				for _, sig := range contract.Signatures {
+					if sig.Kind == KindFunction {
+						functions = append(functions, ContractFunction{
+							Selector:  sig.ID,
+							Signature: sig.Text,
+							Contract:  ContractRef{Name: contract.Name, File: rel},
+						})
+					}
+				}
+			}
+		}
+	} else {
+		docs, err := src.Documents()
+		if err != nil {
+			return nil, err
+		}
+		for _, doc := range docs {
+			contracts, err := parseContractAbis(doc)
+			if err != nil {
+				return nil, err
+			}
+			for _, contract := range contracts {
+				for _, method := range contract.Abi.Methods {
+					selector, err := FromSig(method.Sig)
+					if err != nil {
+						return nil, err
+					}
+					functions = append(functions, ContractFunction{
+						Selector:  selector,
+						Signature: method.Sig,
+						Contract:  ContractRef{Name: contract.Name, File: doc.Name},
+					})
+				}
+			}
+		}
+	}
+	if len(functions) == 0 {
+		return nil, fmt.Errorf("%w: %s has no functions", ErrInvalidAbi, src)
+	}
+
+	sortFunctions(functions)
+	return functions, nil
+}
+
+// Returns the admin functions of the proxy pattern `proxy`, or of every pattern in
+// ProxyAdminFunctions when it is "all". Other values are read as the path of the
+// proxy's own ABI, see NewAbiSource. "none" and "" return no functions.
+func ProxyFunctions(proxy string) ([]ContractFunction, error) {
+	switch proxy {
+	case "", "none":
+		return nil, nil
+	case "all":
+		var functions []ContractFunction
+		for name := range ProxyAdminFunctions {
+			proxyFunctions, err := ProxyFunctions(name)
+			if err != nil {
+				return nil, err
+			}
+			functions = append(functions, proxyFunctions...)
+		}
+		sortFunctions(functions)
+		return functions, nil
+	}
+
+	sigs, ok := ProxyAdminFunctions[proxy]
+	if !ok {
+		return ContractFunctions(NewAbiSource(proxy, ""))
+	}
+	functions := make([]ContractFunction, len(sigs))
+	for idx, sig := range sigs {
+		selector, err := FromSig(sig)
+		if err != nil {
+			return nil, err
+		}
+		functions[idx] = ContractFunction{Selector: selector, Signature: sig, Contract: ContractRef{Name: proxy + " proxy"}}
+	}
+	return functions, nil
+}
+
+// Returns the selectors shared by functions with different signatures, sorted by
+// selector. A function that several contracts define with the same signature, eg:
+// one inherited from a common base contract, is not a collision.
+func FindCollisions(functions []ContractFunction) []Collision {
+	bySelector := map[string][]ContractFunction{}
+	for _, function := range functions {
+		bySelector[function.Selector] = append(bySelector[function.Selector], function)
+	}
+
+	var collisions []Collision
+	for selector, shared := range bySelector {
+		for _, function := range shared[1:] {
+			if function.Signature != shared[0].Signature {
+				sortFunctions(shared)
+				collisions = append(collisions, Collision{Selector: selector, Functions: shared})
+				break
+			}
+		}
+	}
+	sort.Slice(collisions, func(i, j int) bool { return collisions[i].Selector < collisions[j].Selector })
+	return collisions
+}
+
+// Returns a report of `collisions`, one selector per paragraph, eg:
+//
+//	0x42966c68
+//	  burn(uint256) in Token (Token.sol/Token.json)
+//	  collate_propagate_storage(bytes16) in Clash (Clash.sol/Clash.json)
+func FormatCollisions(collisions []Collision) string {
+	var paragraphs []string
+	for _, collision := range collisions {
+		lines := []string{collision.Selector}
+		for _, function := range collision.Functions {
+			lines = append(lines, fmt.Sprintf("  %s in %s", function.Signature, function.Contract))
+		}
+		paragraphs = append(paragraphs, strings.Join(lines, "\n"))
+	}
+	return strings.Join(paragraphs, "\n\n")
+}
+
+// Sorts functions by signature, then by the file and name of their contract.
+func sortFunctions(functions []ContractFunction) {
+	sort.Slice(functions, func(i, j int) bool {
+		a, b := functions[i], functions[j]
+		if a.Signature != b.Signature {
+			return a.Signature < b.Signature
+		}
+		if a.Contract.File != b.Contract.File {
+			return a.Contract.File < b.Contract.File
+		}
+		return a.Contract.Name < b.Contract.Name
+	})
+}
@@ -0,0 +1,122 @@
+package selector
+
+import (
+	"errors"
+	"path"
+	"reflect"
+	"testing"
+)
+
+func TestFindCollisions(t *testing.T) {
+	var (
+		collisionsDir = path.Join("testdata", "collisions")
+		token         = ContractRef{Name: "Token", File: "Token.sol/Token.json"}
+		clash         = ContractRef{Name: "Clash", File: "Clash.sol/Clash.json"}
+		proxyAbi      = path.Join(collisionsDir, "proxy.json")
+		burnClash     = Collision{
+			Selector: "0x42966c68",
+			Functions: []ContractFunction{
+				{Selector: "0x42966c68", Signature: "burn(uint256)", Contract: token},
+				{Selector: "0x42966c68", Signature: "collate_propagate_storage(bytes16)", Contract: clash},
+			},
+		}
+	)
+
+	tests := []struct {
+		name  string
+		src   AbiSource
+		proxy string
+		want  []Collision
+	}{
+		{
+			name:  "directory, with transfer in both contracts",
+			src:   NewAbiSource(collisionsDir+"/out", ""),
+			proxy: "all",
+			want:  []Collision{burnClash},
+		},
+		{
+			name:  "glob",
+			src:   NewAbiSource(collisionsDir+"/out/**/*.json", ""),
+			proxy: "none",
+			want: []Collision{{
+				Selector: "0x42966c68",
+				Functions: []ContractFunction{
+					{Selector: "0x42966c68", Signature: "burn(uint256)", Contract: ContractRef{Name: "Token", File: "testdata/collisions/out/Token.sol/Token.json"}},
+					{Selector: "0x42966c68", Signature: "collate_propagate_storage(bytes16)", Contract: ContractRef{Name: "Clash", File: "testdata/collisions/out/Clash.sol/Clash.json"}},
+				},
+			}},
+		},
+		{
+			name:  "against the proxy's own ABI",
+			src:   FileSource{Path: path.Join(collisionsDir, "out", "Token.sol", "Token.json")},
+			proxy: proxyAbi,
+			want: []Collision{{
+				Selector: "0x42966c68",
+				Functions: []ContractFunction{
+					{Selector: "0x42966c68", Signature: "burn(uint256)", Contract: ContractRef{Name: "Token", File: "testdata/collisions/out/Token.sol/Token.json"}},
+					{Selector: "0x42966c68", Signature: "collate_propagate_storage(bytes16)", Contract: ContractRef{Name: "proxy", File: proxyAbi}},
+				},
+			}},
+		},
+		{
+			name:  "no collision",
+			src:   FileSource{Path: path.Join("testdata", "erc20.abi.json")},
+			proxy: "transparent",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			functions, err := ContractFunctions(tc.src)
+			if err != nil {
+				t.Fatal(err)
+			}
+			proxyFunctions, err := ProxyFunctions(tc.proxy)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := FindCollisions(append(functions, proxyFunctions...)); !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("FindCollisions() = %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestProxyFunctions(t *testing.T) {
+	functions, err := ProxyFunctions("transparent")
+	if err != nil {
+		t.Fatal(err)
+	}
+	admin := ContractFunction{Selector: "0xf851a440", Signature: "admin()", Contract: ContractRef{Name: "transparent proxy"}}
+	if !reflect.DeepEqual(functions[0], admin) {
+		t.Errorf("first transparent proxy function = %v, want %v", functions[0], admin)
+	}
+
+	// an implementation function with the selector of admin() is shadowed by the proxy.
+	shadowed := ContractFunction{Selector: admin.Selector, Signature: "notAdmin_1234()", Contract: ContractRef{Name: "Impl"}}
+	collisions := FindCollisions(append(functions, shadowed))
+	want := []Collision{{Selector: admin.Selector, Functions: []ContractFunction{admin, shadowed}}}
+	if !reflect.DeepEqual(collisions, want) {
+		t.Errorf("FindCollisions() = %v, want %v", collisions, want)
+	}
+	wantReport := "0xf851a440\n  admin() in transparent proxy\n  notAdmin_1234() in Impl"
+	if got := FormatCollisions(collisions); got != wantReport {
+		t.Errorf("FormatCollisions() = %q, want %q", got, wantReport)
+	}
+
+	// every proxy pattern together: upgradeTo(address) is in two of them, but is one function.
+	all, err := ProxyFunctions("all")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if collisions := FindCollisions(all); len(collisions) != 0 {
+		t.Errorf("the built-in proxy functions collide: %v", collisions)
+	}
+
+	if _, err := ContractFunctions(nil); !errors.Is(err, ErrNoAbiSource) {
+		t.Errorf("ContractFunctions(nil) error = %v, want %v", err, ErrNoAbiSource)
+	}
+	if _, err := ContractFunctions(InlineSource{JSON: `[{"type":"event","name":"E","inputs":[]}]`}); !errors.Is(err, ErrInvalidAbi) {
+		t.Errorf("ContractFunctions() of an ABI without functions: error = %v, want %v", err, ErrInvalidAbi)
+	}
+}
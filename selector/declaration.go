@@ -0,0 +1,415 @@
+package selector
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/zeuslawyer/hextool/encdec"
+	"github.com/zeuslawyer/hextool/internal/typescan"
+)
+
+var (
+	// matches the keyword a declaration starts with, and the rest of it.
+	declarationKeywordRegex = regexp.MustCompile(`^(function|event|error|constructor|fallback|receive)\b\s*(.*)$`)
+	// matches a struct definition, eg: "struct Order { address to; uint amount; }".
+	structRegex = regexp.MustCompile(`^struct\s+([A-Za-z_$][A-Za-z0-9_$]*)\s*\{(.*)\}$`)
+	// matches a type that may be a struct name, with optional array suffixes, eg: "Order[]".
+	typeRefRegex = regexp.MustCompile(`^([A-Za-z_$][A-Za-z0-9_$]*)((?:\[[0-9]*\])*)$`)
+	// matches the array suffixes that may follow a tuple, eg: "[]" or "[2][]".
+	tupleSuffixRegex = regexp.MustCompile(`^(?:\[[0-9]*\])*`)
+	// matches a function, event, error or parameter name.
+	identifierRegex = regexp.MustCompile(`^[A-Za-z_$][A-Za-z0-9_$]*$`)
+	// matches the `returns (` of a function declaration.
+	returnsRegex = regexp.MustCompile(`\breturns\s*\(`)
+)
+
+// A function, event, error, constructor, fallback or receive function declared in
+// Solidity, eg: "function transfer(address to, uint amount) external returns (bool)".
+// It marshals to its entry in a JSON ABI.
+type Declaration struct {
+	Type            string                   `json:"type"` // "function", "event", "error", "constructor", "fallback" or "receive".
+	Name            string                   `json:"name,omitempty"`
+	Inputs          []abi.ArgumentMarshaling `json:"inputs"`
+	Outputs         []abi.ArgumentMarshaling `json:"outputs,omitempty"`
+	StateMutability string                   `json:"stateMutability,omitempty"`
+	Anonymous       bool                     `json:"anonymous,omitempty"`
+
+	signature string
+}
+
+// Returns the canonical signature of a function, event or error, eg:
+// "transfer(address,uint256)", or "" for constructors, fallback and receive functions.
+func (d Declaration) Signature() string {
+	return d.signature
+}
+
+// Returns the kind of signature of the declaration. It reports false for constructors,
+// fallback and receive functions, which have no signature.
+func (d Declaration) Kind() (SigKind, bool) {
+	switch d.Type {
+	case "function":
+		return KindFunction, true
+	case "event":
+		return KindEvent, true
+	case "error":
+		return KindError, true
+	}
+	return 0, false
+}
+
+// Parses one function, event or error, in its canonical form, eg: "transfer(address,uint256)",
+// or as declared in Solidity, eg: "function transfer(address to, uint amount) external returns (bool)".
+// Declarations without a keyword are functions. Structs used as parameter types are defined
+// before the declaration, separated by ";", eg: "struct Order { address to; uint amount; }
+// function fill(Order order)". The error wraps ErrInvalidSignature.
+func ParseSig(sig string) (Declaration, error) {
+	return parseSig(sig, "function")
+}
+
+// Parses `sig` as ParseSig does, but declarations without a keyword are of `kind`, and
+// declarations of another kind are an error.
+func parseSigOfKind(sig string, kind SigKind) (Declaration, error) {
+	decl, err := parseSig(sig, kind.String())
+	if err != nil {
+		return Declaration{}, err
+	}
+	if declKind, _ := decl.Kind(); declKind != kind {
+		return Declaration{}, fmt.Errorf("%w: %q declares %s %s, not %s %s", ErrInvalidSignature, sig, article(decl.Type), decl.Type, article(kind.String()), kind)
+	}
+	return decl, nil
+}
+
+func parseSig(sig string, defaultType string) (Declaration, error) {
+	decls, err := parseDeclarationList(splitDeclarations(sig), defaultType)
+	if err != nil {
+		return Declaration{}, err
+	}
+	if len(decls) != 1 {
+		return Declaration{}, fmt.Errorf("%w: %q must declare one function, event or error, not %d", ErrInvalidSignature, sig, len(decls))
+	}
+	if _, ok := decls[0].Kind(); !ok {
+		return Declaration{}, fmt.Errorf("%w: a %s has no signature", ErrInvalidSignature, decls[0].Type)
+	}
+	return decls[0], nil
+}
+
+func article(word string) string {
+	if strings.IndexByte("aeiou", word[0]) != -1 {
+		return "an"
+	}
+	return "a"
+}
+
+// Parses Solidity declarations separated by ";" or new lines, eg: the declarations of an
+// interface. Struct definitions are not returned, but the structs they define can be used
+// as parameter types by the other declarations. See ParseSig.
+func ParseDeclarations(text string) ([]Declaration, error) {
+	return parseDeclarationList(splitDeclarations(text), "function")
+}
+
+// Parses `texts`, each a declaration or struct definition. Declarations without a keyword
+// are of type `defaultType`, eg: "function".
+func parseDeclarationList(texts []string, defaultType string) ([]Declaration, error) {
+	structs := map[string]string{} // struct names, and their fields as a parameter list.
+	var others []string
+	for _, text := range texts {
+		matches := structRegex.FindStringSubmatch(text)
+		if matches == nil {
+			others = append(others, text)
+			continue
+		}
+		var fields []string
+		for _, field := range strings.Split(matches[2], ";") {
+			if field = strings.TrimSpace(field); field != "" {
+				fields = append(fields, field)
+			}
+		}
+		structs[matches[1]] = strings.Join(fields, ",")
+	}
+
+	decls := make([]Declaration, 0, len(others))
+	for _, text := range others {
+		decl, err := parseDeclaration(text, structs, defaultType)
+		if err != nil {
+			return nil, err
+		}
+		decls = append(decls, decl)
+	}
+	return decls, nil
+}
+
+// Splits `text` on the ";" and new lines that are not inside parentheses or braces, and
+// after the closing brace of a struct.
+func splitDeclarations(text string) []string {
+	var texts []string
+	depth, braces, start := 0, 0, 0
+	add := func(end int) {
+		if t := strings.TrimSpace(text[start:end]); t != "" {
+			texts = append(texts, t)
+		}
+	}
+	for i, c := range text {
+		switch c {
+		case '(':
+			depth++
+		case ')':
+			depth--
+		case '{':
+			braces++
+		case '}':
+			braces--
+			if braces == 0 && depth == 0 {
+				add(i + 1)
+				start = i + 1
+			}
+		case ';', '\n':
+			if depth == 0 && braces == 0 {
+				add(i)
+				start = i + 1
+			}
+		}
+	}
+	add(len(text))
+	return texts
+}
+
+// Parses a declaration that is not a struct definition.
+func parseDeclaration(text string, structs map[string]string, defaultType string) (Declaration, error) {
+	text = strings.TrimSpace(text)
+	decl := Declaration{Type: defaultType}
+	rest := text
+	if matches := declarationKeywordRegex.FindStringSubmatch(text); matches != nil {
+		decl.Type, rest = matches[1], matches[2]
+	}
+	invalid := fmt.Errorf("%w: %q is not a valid %s signature", ErrInvalidSignature, text, decl.Type)
+
+	open := strings.Index(rest, "(")
+	if open == -1 {
+		return Declaration{}, invalid
+	}
+	closing, err := typescan.MatchingParen(rest, open)
+	if err != nil {
+		return Declaration{}, invalid
+	}
+	decl.Name = strings.TrimSpace(rest[:open])
+	hasName := decl.Type == "function" || decl.Type == "event" || decl.Type == "error"
+	if hasName != identifierRegex.MatchString(decl.Name) {
+		return Declaration{}, invalid
+	}
+
+	if decl.Inputs, err = parseParams(rest[open+1:closing], structs); err != nil {
+		return Declaration{}, err
+	}
+
+	modifiers := rest[closing+1:]
+	if loc := returnsRegex.FindStringIndex(modifiers); loc != nil && decl.Type == "function" {
+		returnsClosing, err := typescan.MatchingParen(modifiers, loc[1]-1)
+		if err != nil {
+			return Declaration{}, invalid
+		}
+		if decl.Outputs, err = parseParams(modifiers[loc[1]:returnsClosing], structs); err != nil {
+			return Declaration{}, err
+		}
+		modifiers = modifiers[:loc[0]] + " " + modifiers[returnsClosing+1:]
+	}
+	if err := decl.applyModifiers(modifiers); err != nil {
+		return Declaration{}, fmt.Errorf("%w: %v", invalid, err)
+	}
+
+	if hasName {
+		types := make([]string, len(decl.Inputs))
+		for idx, input := range decl.Inputs {
+			abiType, err := abi.NewType(input.Type, "", input.Components)
+			if err != nil {
+				return Declaration{}, fmt.Errorf("%w: unsupported type %q in %q: %v", ErrInvalidSignature, input.Type, text, err)
+			}
+			types[idx] = abiType.String()
+		}
+		decl.signature = decl.Name + "(" + strings.Join(types, ",") + ")"
+	}
+	return decl, nil
+}
+
+// Sets the state mutability or anonymity the modifiers declare. Visibility, `virtual`,
+// `override` and custom modifiers are dropped, as the ABI does not record them.
+func (d *Declaration) applyModifiers(modifiers string) error {
+	switch d.Type {
+	case "function", "constructor", "fallback":
+		d.StateMutability = "nonpayable"
+	case "receive":
+		d.StateMutability = "payable"
+	}
+
+	// drop the arguments of modifiers, eg: "override(A, B)".
+	for open := strings.Index(modifiers, "("); open != -1; open = strings.Index(modifiers, "(") {
+		closing, err := typescan.MatchingParen(modifiers, open)
+		if err != nil {
+			return err
+		}
+		modifiers = modifiers[:open] + " " + modifiers[closing+1:]
+	}
+
+	for _, word := range strings.Fields(modifiers) {
+		switch {
+		case d.Type == "event":
+			if word != "anonymous" {
+				return fmt.Errorf("unexpected %q", word)
+			}
+			d.Anonymous = true
+		case d.Type == "error":
+			return fmt.Errorf("unexpected %q", word)
+		case word == "view" || word == "pure" || word == "payable" || word == "nonpayable":
+			d.StateMutability = word
+		case word == "constant":
+			d.StateMutability = "view"
+		case !identifierRegex.MatchString(word):
+			return fmt.Errorf("unexpected %q", word)
+		}
+	}
+	return nil
+}
+
+// Parses a parameter list, eg: "address indexed from, Order[] memory orders", into its
+// JSON ABI form.
+func parseParams(params string, structs map[string]string) ([]abi.ArgumentMarshaling, error) {
+	parts, err := splitParams(params)
+	if err != nil {
+		return nil, err
+	}
+
+	typeList := make([]string, len(parts))
+	indexed := make([]bool, len(parts))
+	for idx, part := range parts {
+		typeList[idx], indexed[idx], err = rewriteParam(part, structs, map[string]bool{})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	args, err := encdec.ParseTypeList(strings.Join(typeList, ","))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidSignature, err)
+	}
+	for idx := range args {
+		args[idx].Indexed = indexed[idx]
+	}
+	return args, nil
+}
+
+// Rewrites a parameter as a type and name that encdec.ParseTypeList understands: struct
+// names are replaced with the tuple of their fields, and data locations, `indexed` and
+// `payable` are dropped. `seen` holds the structs being expanded, to detect recursive ones.
+func rewriteParam(param string, structs map[string]string, seen map[string]bool) (string, bool, error) {
+	param = strings.TrimSpace(param)
+	var typeStr string
+	var words []string
+
+	if strings.HasPrefix(param, "(") || strings.HasPrefix(param, "tuple(") {
+		open := strings.Index(param, "(")
+		closing, err := typescan.MatchingParen(param, open)
+		if err != nil {
+			return "", false, fmt.Errorf("%w: %v in %q", ErrInvalidSignature, err, param)
+		}
+		components, err := rewriteParamList(param[open+1:closing], structs, seen)
+		if err != nil {
+			return "", false, err
+		}
+		rest := param[closing+1:]
+		suffix := tupleSuffixRegex.FindString(rest)
+		typeStr = "(" + components + ")" + suffix
+		words = strings.Fields(rest[len(suffix):])
+	} else {
+		words = strings.Fields(param)
+		if len(words) == 0 {
+			return "", false, fmt.Errorf("%w: empty parameter", ErrInvalidSignature)
+		}
+		typeStr, words = words[0], words[1:]
+		if matches := typeRefRegex.FindStringSubmatch(typeStr); matches != nil {
+			if fields, ok := structs[matches[1]]; ok {
+				if seen[matches[1]] {
+					return "", false, fmt.Errorf("%w: struct %s contains itself", ErrInvalidSignature, matches[1])
+				}
+				seen[matches[1]] = true
+				components, err := rewriteParamList(fields, structs, seen)
+				delete(seen, matches[1])
+				if err != nil {
+					return "", false, err
+				}
+				typeStr = "(" + components + ")" + matches[2]
+			}
+		}
+	}
+
+	var name string
+	var indexed bool
+	for _, word := range words {
+		switch {
+		case word == "indexed":
+			indexed = true
+		case word == "memory" || word == "calldata" || word == "storage":
+		case word == "payable" && strings.HasPrefix(typeStr, "address"):
+		case name == "":
+			name = word
+		default:
+			return "", false, fmt.Errorf("%w: unexpected %q in parameter %q", ErrInvalidSignature, word, param)
+		}
+	}
+	if name != "" {
+		typeStr += " " + name
+	}
+	return typeStr, indexed, nil
+}
+
+func rewriteParamList(params string, structs map[string]string, seen map[string]bool) (string, error) {
+	parts, err := splitParams(params)
+	if err != nil {
+		return "", err
+	}
+	for idx, part := range parts {
+		if parts[idx], _, err = rewriteParam(part, structs, seen); err != nil {
+			return "", err
+		}
+	}
+	return strings.Join(parts, ","), nil
+}
+
+// Splits a parameter list on the commas that are not inside a tuple, see typescan.SplitTopLevel.
+func splitParams(params string) ([]string, error) {
+	if strings.TrimSpace(params) == "" {
+		return nil, nil
+	}
+	parts, err := typescan.SplitTopLevel(params)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidSignature, err)
+	}
+	return parts, nil
+}
+
+// Converts an ethers-style human-readable ABI, an array of declarations such as
+// "function transfer(address to, uint amount) returns (bool)", into JSON ABI entries.
+// It reports false if `entries` is not such an array.
+func humanReadableAbi(entries []any) ([]any, bool, error) {
+	if len(entries) == 0 {
+		return nil, false, nil
+	}
+	texts := make([]string, len(entries))
+	for idx, entry := range entries {
+		text, ok := entry.(string)
+		if !ok {
+			return nil, false, nil
+		}
+		texts[idx] = text
+	}
+
+	decls, err := parseDeclarationList(texts, "function")
+	if err != nil {
+		return nil, true, err
+	}
+	abiEntries := make([]any, len(decls))
+	for idx, decl := range decls {
+		abiEntries[idx] = decl
+	}
+	return abiEntries, true, nil
+}
@@ -0,0 +1,216 @@
+package selector
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+)
+
+func TestParseSig(t *testing.T) {
+	tests := []struct {
+		name           string
+		sig            string
+		want           string
+		wantKind       SigKind
+		wantMutability string
+		wantIndexed    []bool
+		wantAnonymous  bool
+		wantErr        string
+	}{
+		{name: "canonical", sig: "transfer(address,uint256)", want: "transfer(address,uint256)", wantMutability: "nonpayable"},
+		{
+			name:           "function with names, modifiers and returns",
+			sig:            "function transfer(address to, uint amount) external returns (bool)",
+			want:           "transfer(address,uint256)",
+			wantMutability: "nonpayable",
+		},
+		{
+			name:           "view, virtual and override",
+			sig:            "function balanceOf(address _owner) public view virtual override(IERC20, ERC20) returns (uint256 balance);",
+			want:           "balanceOf(address)",
+			wantMutability: "view",
+		},
+		{
+			name:           "payable address and data locations",
+			sig:            "function pay(address payable to, bytes calldata data, string memory note) external payable",
+			want:           "pay(address,bytes,string)",
+			wantMutability: "payable",
+		},
+		{
+			name:        "event with indexed parameters",
+			sig:         "event Transfer(address indexed from, address indexed to, uint value)",
+			want:        "Transfer(address,address,uint256)",
+			wantKind:    KindEvent,
+			wantIndexed: []bool{true, true, false},
+		},
+		{
+			name:          "anonymous event",
+			sig:           "event Debug(int value) anonymous",
+			want:          "Debug(int256)",
+			wantKind:      KindEvent,
+			wantIndexed:   []bool{false},
+			wantAnonymous: true,
+		},
+		{name: "error", sig: "error InsufficientBalance(uint available, uint256 required)", want: "InsufficientBalance(uint256,uint256)", wantKind: KindError},
+		{
+			name:           "structs referenced by name",
+			sig:            "struct Leg { address pool; uint24 fee; }\nstruct Route { Leg[] legs; uint amountIn; }\nfunction swap(Route calldata route, uint deadline) external",
+			want:           "swap(((address,uint24)[],uint256),uint256)",
+			wantMutability: "nonpayable",
+		},
+		{
+			name:           "inline tuples",
+			sig:            "function fill(tuple(address to, uint amount)[2] memory orders, (bytes32,uint8) sig)",
+			want:           "fill((address,uint256)[2],(bytes32,uint8))",
+			wantMutability: "nonpayable",
+		},
+		{name: "no keyword", sig: "approve(address spender, uint amount)", want: "approve(address,uint256)", wantMutability: "nonpayable"},
+		{name: "bad function", sig: "gibberish", wantErr: `"gibberish" is not a valid function signature`},
+		{name: "unbalanced parentheses", sig: "function foo(uint", wantErr: "is not a valid function signature"},
+		{name: "missing name", sig: "function (uint a)", wantErr: "is not a valid function signature"},
+		{name: "unknown type", sig: "function fill(Order o)", wantErr: `Unsupported type "Order"`},
+		{name: "recursive struct", sig: "struct Node { Node next; }; function f(Node n)", wantErr: "struct Node contains itself"},
+		{name: "modifier on an error", sig: "error Unauthorized() view", wantErr: "is not a valid error signature"},
+		{name: "too many words", sig: "function f(uint a b)", wantErr: `unexpected "b"`},
+		{name: "constructor", sig: "constructor(address owner)", wantErr: "a constructor has no signature"},
+		{name: "two declarations", sig: "function a(); function b()", wantErr: "not 2"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := ParseSig(tc.sig)
+			if tc.wantErr != "" {
+				if !errors.Is(err, ErrInvalidSignature) || !strings.Contains(err.Error(), tc.wantErr) {
+					t.Errorf("error = %v, want %v containing %q", err, ErrInvalidSignature, tc.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if got.Signature() != tc.want {
+				t.Errorf("Signature() = %s, want %s", got.Signature(), tc.want)
+			}
+			if kind, _ := got.Kind(); kind != tc.wantKind {
+				t.Errorf("Kind() = %s, want %s", kind, tc.wantKind)
+			}
+			if got.StateMutability != tc.wantMutability || got.Anonymous != tc.wantAnonymous {
+				t.Errorf("mutability, anonymous = %q, %v, want %q, %v", got.StateMutability, got.Anonymous, tc.wantMutability, tc.wantAnonymous)
+			}
+			if tc.wantIndexed != nil {
+				var indexed []bool
+				for _, input := range got.Inputs {
+					indexed = append(indexed, input.Indexed)
+				}
+				if !reflect.DeepEqual(indexed, tc.wantIndexed) {
+					t.Errorf("indexed = %v, want %v", indexed, tc.wantIndexed)
+				}
+			}
+		})
+	}
+}
+
+func TestParseDeclarationsAbi(t *testing.T) {
+	decls, err := ParseDeclarations(`
+		function transfer(address to, uint amount) external returns (bool success);
+		function balanceOf(address owner) external view returns (uint);
+		constructor(string name) payable
+		receive() external payable
+	`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []Declaration{
+		{
+			Type:            "function",
+			Name:            "transfer",
+			Inputs:          []abi.ArgumentMarshaling{{Name: "to", Type: "address"}, {Name: "amount", Type: "uint256"}},
+			Outputs:         []abi.ArgumentMarshaling{{Name: "success", Type: "bool"}},
+			StateMutability: "nonpayable",
+			signature:       "transfer(address,uint256)",
+		},
+		{
+			Type:            "function",
+			Name:            "balanceOf",
+			Inputs:          []abi.ArgumentMarshaling{{Name: "owner", Type: "address"}},
+			Outputs:         []abi.ArgumentMarshaling{{Type: "uint256"}},
+			StateMutability: "view",
+			signature:       "balanceOf(address)",
+		},
+		{Type: "constructor", Inputs: []abi.ArgumentMarshaling{{Name: "name", Type: "string"}}, StateMutability: "payable"},
+		{Type: "receive", Inputs: []abi.ArgumentMarshaling{}, StateMutability: "payable"},
+	}
+	if !reflect.DeepEqual(decls, want) {
+		t.Errorf("ParseDeclarations() = %+v, want %+v", decls, want)
+	}
+}
+
+func TestHumanReadableAbi(t *testing.T) {
+	tests := []struct {
+		name        string
+		json        string
+		wantMethods []string
+		wantEvents  []string
+		wantErrors  []string
+		wantErr     string
+	}{
+		{
+			name: "array",
+			json: `["struct Order { address to; uint amount; }",
+				"function fill(Order[] orders) returns (uint filled)",
+				"event Filled(address indexed to, uint amount)",
+				"error Expired(uint deadline)"]`,
+			wantMethods: []string{"fill((address,uint256)[])"},
+			wantEvents:  []string{"Filled(address,uint256)"},
+			wantErrors:  []string{"Expired(uint256)"},
+		},
+		{
+			name:        "abi property",
+			json:        `{"contractName": "Token", "abi": ["function transfer(address to, uint amount) returns (bool)"]}`,
+			wantMethods: []string{"transfer(address,uint256)"},
+		},
+		{
+			name:    "invalid declaration",
+			json:    `["function transfer(address to, uint amount)", "gibberish"]`,
+			wantErr: "human-readable ABI",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := LoadABI(InlineSource{JSON: tc.json})
+			if tc.wantErr != "" {
+				if !errors.Is(err, ErrInvalidAbi) || !strings.Contains(err.Error(), tc.wantErr) {
+					t.Errorf("error = %v, want %v containing %q", err, ErrInvalidAbi, tc.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			var methods, events, abiErrors []string
+			for _, method := range got.Methods {
+				methods = append(methods, method.Sig)
+			}
+			for _, ev := range got.Events {
+				events = append(events, ev.Sig)
+				for _, input := range ev.Inputs {
+					if input.Name == "to" && !input.Indexed {
+						t.Errorf("%s: %s is not indexed", ev.Sig, input.Name)
+					}
+				}
+			}
+			for _, abiErr := range got.Errors {
+				abiErrors = append(abiErrors, abiErr.Sig)
+			}
+			assertContainsOnce(t, "methods", methods, tc.wantMethods)
+			assertContainsOnce(t, "events", events, tc.wantEvents)
+			assertContainsOnce(t, "errors", abiErrors, tc.wantErrors)
+		})
+	}
+}
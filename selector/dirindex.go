@@ -0,0 +1,372 @@
+package selector
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// The environment variable that overrides where hextool caches directory indexes
+// and signature resolver responses.
+const CacheDirEnv = "HEXTOOL_CACHE_DIR"
+
+// Returns the directory hextool caches data in: $HEXTOOL_CACHE_DIR if set, otherwise
+// `hextool` under the user cache dir, eg: ~/.cache on Linux. Returns "" if neither is known.
+func cacheDir() string {
+	if dir := os.Getenv(CacheDirEnv); dir != "" {
+		return dir
+	}
+	userCacheDir, err := os.UserCacheDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(userCacheDir, "hextool")
+}
+
+// The maximum number of files parsed at the same time when indexing a directory.
+var maxIndexWorkers = runtime.NumCPU()
+
+// An index of the contract ABIs in every JSON file under a directory.
+type DirIndex struct {
+	Dir   string                  `json:"dir"`
+	Files map[string]*indexedFile `json:"files"` // keyed by path relative to Dir.
+}
+
+type indexedFile struct {
+	ModTime   time.Time         `json:"modTime"`
+	Size      int64             `json:"size"`
+	Contracts []indexedContract `json:"contracts"` // empty for JSON files that are not ABIs.
+}
+
+type indexedContract struct {
+	Name       string          `json:"name"`
+	Abi        json.RawMessage `json:"abi"`
+	Signatures []indexedSig    `json:"signatures"`
+}
+
+// A signature with its selector or topic hash, so lookups need not hash every signature.
+type indexedSig struct {
+	Signature
+	ID string `json:"id"`
+}
+
+// A contract that defines a signature, and the file it was found in.
+type ContractRef struct {
+	Name string
+	File string // relative to the indexed directory.
+}
+
+func (c ContractRef) String() string {
+	if c.File == "" {
+		return c.Name
+	}
+	return fmt.Sprintf("%s (%s)", c.Name, c.File)
+}
+
+// A signature found in a DirIndex, with every contract that defines it.
+type DirMatch struct {
+	Signature Signature
+	Contracts []ContractRef
+}
+
+// Indexes every `.json` file under `dir`, recursively, skipping directories whose
+// name starts with "." and `node_modules`. JSON files that are not ABIs or artifacts
+// are ignored. Files are parsed concurrently, and the index is cached, so that
+// files that have not changed since the last call are not parsed again.
+func IndexDir(dir string) (*DirIndex, error) {
+	absDir, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, fmt.Errorf("error indexing directory %s: %w", dir, err)
+	}
+
+	files, err := jsonFiles(absDir)
+	if err != nil {
+		return nil, fmt.Errorf("error indexing directory %s: %w", dir, err)
+	}
+
+	cached := loadCachedIndex(absDir)
+	idx := &DirIndex{Dir: absDir, Files: map[string]*indexedFile{}}
+	var stale []string
+	for rel, info := range files {
+		if f, ok := cached.Files[rel]; ok && f.ModTime.Equal(info.ModTime()) && f.Size == info.Size() {
+			idx.Files[rel] = f
+			continue
+		}
+		idx.Files[rel] = &indexedFile{ModTime: info.ModTime(), Size: info.Size()}
+		stale = append(stale, rel)
+	}
+
+	if err := idx.parseFiles(stale); err != nil {
+		return nil, err
+	}
+	if len(stale) > 0 || len(cached.Files) != len(idx.Files) {
+		idx.saveCache()
+	}
+	return idx, nil
+}
+
+// Returns the `.json` files under `dir`, keyed by their path relative to `dir`.
+func jsonFiles(dir string) (map[string]fs.FileInfo, error) {
+	files := map[string]fs.FileInfo{}
+	err := filepath.WalkDir(dir, func(walkPath string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			if walkPath != dir && (strings.HasPrefix(d.Name(), ".") || d.Name() == "node_modules") {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if filepath.Ext(walkPath) != ".json" {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(dir, walkPath)
+		if err != nil {
+			return err
+		}
+		files[filepath.ToSlash(rel)] = info
+		return nil
+	})
+	return files, err
+}
+
+// Parses the files at the relative paths `rels` with a bounded pool of workers.
+func (idx *DirIndex) parseFiles(rels []string) error {
+	jobs := make(chan string)
+	errs := make(chan error, len(rels))
+	var wg sync.WaitGroup
+
+	workers := maxIndexWorkers
+	if workers > len(rels) {
+		workers = len(rels)
+	}
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for rel := range jobs {
+				contracts, err := indexFile(filepath.Join(idx.Dir, filepath.FromSlash(rel)))
+				if err != nil {
+					errs <- err
+					continue
+				}
+				// each worker writes to a different, already allocated, entry.
+				idx.Files[rel].Contracts = contracts
+			}
+		}()
+	}
+	for _, rel := range rels {
+		jobs <- rel
+	}
+	close(jobs)
+	wg.Wait()
+	close(errs)
+
+	return <-errs // nil when no worker failed.
+}
+
+// Parses the contracts in a file. A file that cannot be read is an error,
+// but a file that does not hold an ABI just has no contracts.
+func indexFile(filePath string) ([]indexedContract, error) {
+	b, err := os.ReadFile(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("error reading ABI file: %w", err)
+	}
+	contracts, err := parseContractAbis(AbiDocument{Name: filePath, Data: b})
+	if err != nil {
+		return nil, nil
+	}
+
+	indexed := make([]indexedContract, len(contracts))
+	for i, contract := range contracts {
+		indexed[i] = indexedContract{Name: contract.Name, Abi: contract.Json}
+		for _, sig := range contractSignatures(contract) {
+			id, err := sig.ID()
+			if err != nil {
+				return nil, fmt.Errorf("error indexing %s: %w", filePath, err)
+			}
+			indexed[i].Signatures = append(indexed[i].Signatures, indexedSig{Signature: sig, ID: id})
+		}
+	}
+	return indexed, nil
+}
+
+// Returns every signature of `kind` in the index whose selector or topic hash is `id`,
+// sorted by text, with the contracts that define it sorted by file and name.
+func (idx *DirIndex) Lookup(kind SigKind, id string) []DirMatch {
+	id = strings.ToLower(id)
+	byText := map[string]*DirMatch{}
+	for rel, f := range idx.Files {
+		for _, contract := range f.Contracts {
+			for _, sig := range contract.Signatures {
+				if sig.Kind != kind || sig.ID != id {
+					continue
+				}
+				match, ok := byText[sig.Text]
+				if !ok {
+					match = &DirMatch{Signature: Signature{Kind: kind, Text: sig.Text}}
+					byText[sig.Text] = match
+				}
+				match.Contracts = append(match.Contracts, ContractRef{Name: contract.Name, File: rel})
+			}
+		}
+	}
+
+	matches := make([]DirMatch, 0, len(byText))
+	for _, match := range byText {
+		sort.Slice(match.Contracts, func(i, j int) bool {
+			a, b := match.Contracts[i], match.Contracts[j]
+			if a.File != b.File {
+				return a.File < b.File
+			}
+			return a.Name < b.Name
+		})
+		matches = append(matches, *match)
+	}
+	sort.Slice(matches, func(i, j int) bool { return matches[i].Signature.Text < matches[j].Signature.Text })
+	return matches
+}
+
+// Returns the ABI of every contract in the index, as documents sorted by file and contract.
+func (idx *DirIndex) documents() []AbiDocument {
+	rels := make([]string, 0, len(idx.Files))
+	for rel := range idx.Files {
+		rels = append(rels, rel)
+	}
+	sort.Strings(rels)
+
+	var docs []AbiDocument
+	for _, rel := range rels {
+		for _, contract := range idx.Files[rel].Contracts {
+			docs = append(docs, AbiDocument{Name: rel + ":" + contract.Name, Data: contract.Abi})
+		}
+	}
+	return docs
+}
+
+// The version of the cached index format. Caches of another version are discarded, so
+// that changing how files are indexed or IDs are computed re-indexes every directory.
+const indexCacheVersion = 1
+
+// A cached index, as written to disk. The checksum is the sha256 of Index, so that a cache
+// that was truncated or edited is discarded when it is loaded, and the IDs of the
+// signatures in a cache that was loaded can be trusted.
+type indexCache struct {
+	Version  int             `json:"version"`
+	Checksum string          `json:"checksum"`
+	Index    json.RawMessage `json:"index"`
+}
+
+func indexCachePath(absDir string) string {
+	dir := cacheDir()
+	if dir == "" {
+		return ""
+	}
+	dirHash := fmt.Sprintf("%x", sha256.Sum256([]byte(absDir)))
+	return filepath.Join(dir, "index", dirHash[:16]+".json")
+}
+
+// Returns the cached index of `absDir`, or an empty index if there is none, or the
+// cache is of another version or does not match its checksum.
+func loadCachedIndex(absDir string) *DirIndex {
+	idx := &DirIndex{Dir: absDir}
+	if cachePath := indexCachePath(absDir); cachePath != "" {
+		var cache indexCache
+		if b, err := os.ReadFile(cachePath); err == nil && json.Unmarshal(b, &cache) == nil &&
+			cache.Version == indexCacheVersion && cache.Checksum == indexChecksum(cache.Index) {
+			_ = json.Unmarshal(cache.Index, idx)
+		}
+	}
+	if idx.Files == nil || idx.Dir != absDir {
+		idx.Files = map[string]*indexedFile{}
+	}
+	return idx
+}
+
+// Caches the index. Failing to write the cache does not fail the lookup.
+func (idx *DirIndex) saveCache() {
+	cachePath := indexCachePath(idx.Dir)
+	if cachePath == "" {
+		return
+	}
+	indexJson, err := json.Marshal(idx)
+	if err != nil {
+		return
+	}
+	b, err := json.Marshal(indexCache{Version: indexCacheVersion, Checksum: indexChecksum(indexJson), Index: indexJson})
+	if err != nil || os.MkdirAll(filepath.Dir(cachePath), 0o755) != nil {
+		return
+	}
+	_ = os.WriteFile(cachePath, b, 0o644)
+}
+
+func indexChecksum(indexJson []byte) string {
+	return fmt.Sprintf("%x", sha256.Sum256(indexJson))
+}
+
+// A directory of ABI files and artifacts, searched recursively. See IndexDir.
+type DirSource struct {
+	Dir string
+}
+
+func (s DirSource) Documents() ([]AbiDocument, error) {
+	idx, err := IndexDir(s.Dir)
+	if err != nil {
+		return nil, err
+	}
+	docs := idx.documents()
+	if len(docs) == 0 {
+		return nil, fmt.Errorf("%w: no ABI files found in directory %s", ErrNoAbiSource, s.Dir)
+	}
+	return docs, nil
+}
+
+func (s DirSource) String() string { return s.Dir }
+
+// Looks up `id` in every ABI under `dir`, and returns the matching signatures, each
+// followed by the contracts that define it, eg:
+//
+//	transfer(address,uint256)
+//	  defined in Token (artifacts/Token.sol/Token.json)
+func dirSig(kind SigKind, id string, dir string) (string, error) {
+	id, err := normalizeID(kind, id)
+	if err != nil {
+		return "", err
+	}
+	idx, err := IndexDir(dir)
+	if err != nil {
+		return "", err
+	}
+
+	matches := idx.Lookup(kind, id)
+	if len(matches) == 0 {
+		return "", fmt.Errorf("%w: no %s in the ABI files in %s matches %s", ErrSelectorNotFound, kind, dir, id)
+	}
+	var lines []string
+	for _, match := range matches {
+		lines = append(lines, match.Signature.Text)
+		for _, contract := range match.Contracts {
+			lines = append(lines, "  defined in "+contract.String())
+		}
+	}
+	return strings.Join(lines, "\n"), nil
+}
+
+// Reports whether `abiPath` is a directory.
+func isDir(abiPath string) bool {
+	info, err := os.Stat(abiPath)
+	return err == nil && info.IsDir()
+}
@@ -0,0 +1,261 @@
+package selector
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/zeuslawyer/hextool/encdec"
+)
+
+const transferSelector = "0xa9059cbb"
+
+// Copies the artifacts in testdata/artifacts to a new directory, with JSON files that are
+// not ABIs, and an ABI under node_modules that must be skipped.
+func newArtifactDir(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+	files := map[string]string{
+		"hardhat/Token.json":        readFile(t, tokenArtifact),
+		"out/Vault.sol/Vault.json":  readFile(t, vaultArtifact),
+		"package.json":              `{"name": "contracts", "version": "1.0.0"}`,
+		"out/build-info/1234.json":  `{"id": "1234", "input": {}}`,
+		"node_modules/dep/Dep.json": `{"contractName": "Dep", "abi": [{"type": "function", "name": "transfer", "inputs": [{"type": "address"}, {"type": "uint256"}]}]}`,
+		"README.md":                 "not JSON",
+	}
+	for name, content := range files {
+		writeFile(t, filepath.Join(dir, filepath.FromSlash(name)), content)
+	}
+	return dir
+}
+
+func readFile(t *testing.T, name string) string {
+	t.Helper()
+	b, err := os.ReadFile(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(b)
+}
+
+func writeFile(t *testing.T, name string, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(name), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(name, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestIndexDir(t *testing.T) {
+	dir := newArtifactDir(t)
+
+	tests := []struct {
+		name string
+		kind SigKind
+		id   string
+		want []DirMatch
+	}{
+		{
+			name: "function defined in two contracts",
+			kind: KindFunction,
+			id:   transferSelector,
+			want: []DirMatch{{
+				Signature: Signature{Kind: KindFunction, Text: "transfer(address,uint256)"},
+				Contracts: []ContractRef{
+					{Name: "HardhatToken", File: "hardhat/Token.json"},
+					{Name: "Vault", File: "out/Vault.sol/Vault.json"},
+				},
+			}},
+		},
+		{
+			name: "error",
+			kind: KindError,
+			id:   "0x07711d3e",
+			want: []DirMatch{{
+				Signature: Signature{Kind: KindError, Text: "VaultLocked(uint64)"},
+				Contracts: []ContractRef{{Name: "Vault", File: "out/Vault.sol/Vault.json"}},
+			}},
+		},
+		{
+			name: "event",
+			kind: KindEvent,
+			id:   "0xC36B5179CB9C303B200074996EAB2B3473EAC370FDD7EBA3BEC636FE35109696",
+			want: []DirMatch{{
+				Signature: Signature{Kind: KindEvent, Text: "Swept(address,uint256)"},
+				Contracts: []ContractRef{{Name: "Vault", File: "out/Vault.sol/Vault.json"}},
+			}},
+		},
+		{
+			name: "a function selector is not an error selector",
+			kind: KindError,
+			id:   transferSelector,
+			want: []DirMatch{},
+		},
+	}
+
+	defer func(workers int) { maxIndexWorkers = workers }(maxIndexWorkers)
+	for _, workers := range []int{1, 4} {
+		maxIndexWorkers = workers
+		idx, err := IndexDir(dir)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, tc := range tests {
+			t.Run(tc.name, func(t *testing.T) {
+				if got := idx.Lookup(tc.kind, tc.id); !reflect.DeepEqual(got, tc.want) {
+					t.Errorf("Lookup(%s, %s) with %d workers = %v, want %v", tc.kind, tc.id, workers, got, tc.want)
+				}
+			})
+		}
+	}
+}
+
+func TestIndexDirCache(t *testing.T) {
+	dir := newArtifactDir(t)
+	token := filepath.Join(dir, "hardhat", "Token.json")
+	lookupTransfer := func() []DirMatch {
+		t.Helper()
+		idx, err := IndexDir(dir)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return idx.Lookup(KindFunction, transferSelector)
+	}
+
+	if got := lookupTransfer(); len(got) != 1 || len(got[0].Contracts) != 2 {
+		t.Fatalf("transfer = %v, want it in 2 contracts", got)
+	}
+
+	// rename the function without changing the file's size or modification time:
+	// the cached index is used, so the file is not parsed again.
+	info, err := os.Stat(token)
+	if err != nil {
+		t.Fatal(err)
+	}
+	content := strings.Replace(readFile(t, token), `"transfer"`, `"transfex"`, 1)
+	writeFile(t, token, content)
+	if err := os.Chtimes(token, info.ModTime(), info.ModTime()); err != nil {
+		t.Fatal(err)
+	}
+	if got := lookupTransfer(); len(got) != 1 || len(got[0].Contracts) != 2 {
+		t.Errorf("transfer after an unnoticed change = %v, want the cached result", got)
+	}
+
+	// once the modification time changes, the file is parsed again.
+	later := info.ModTime().Add(time.Second)
+	if err := os.Chtimes(token, later, later); err != nil {
+		t.Fatal(err)
+	}
+	want := []ContractRef{{Name: "Vault", File: "out/Vault.sol/Vault.json"}}
+	if got := lookupTransfer(); len(got) != 1 || !reflect.DeepEqual(got[0].Contracts, want) {
+		t.Errorf("transfer after a change = %v, want it only in %v", got, want)
+	}
+
+	// deleted files leave the index.
+	if err := os.Remove(filepath.Join(dir, "out", "Vault.sol", "Vault.json")); err != nil {
+		t.Fatal(err)
+	}
+	if got := lookupTransfer(); len(got) != 0 {
+		t.Errorf("transfer after deleting its files = %v, want no match", got)
+	}
+}
+
+// The IDs in a cached index are trusted once the cache is loaded, so a cache that was edited,
+// or written by another version of hextool, is discarded and the directory indexed again.
+func TestIndexDirDiscardsInvalidCache(t *testing.T) {
+	const otherSelector = "0x42966c68"
+
+	tests := []struct {
+		name   string
+		tamper func(cached string) string
+	}{
+		{
+			name: "edited",
+			tamper: func(cached string) string {
+				return strings.ReplaceAll(cached, `"id":"`+transferSelector+`"`, `"id":"`+otherSelector+`"`)
+			},
+		},
+		{
+			name: "other version",
+			tamper: func(cached string) string {
+				return strings.Replace(cached, fmt.Sprintf(`"version":%d`, indexCacheVersion), `"version":0`, 1)
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			dir := newArtifactDir(t)
+			idx, err := IndexDir(dir)
+			if err != nil {
+				t.Fatal(err)
+			}
+			cachePath := indexCachePath(idx.Dir)
+			cached := readFile(t, cachePath)
+			tampered := tc.tamper(cached)
+			if tampered == cached {
+				t.Fatalf("the cached index of %s was not changed:\n%s", dir, cached)
+			}
+			writeFile(t, cachePath, tampered)
+
+			idx, err = IndexDir(dir)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := idx.Lookup(KindFunction, transferSelector); len(got) != 1 || len(got[0].Contracts) != 2 {
+				t.Errorf("transfer = %v, want it in 2 contracts", got)
+			}
+			if got := idx.Lookup(KindFunction, otherSelector); len(got) != 0 {
+				t.Errorf("%s = %v, want no match", otherSelector, got)
+			}
+		})
+	}
+}
+
+func TestDirSelectors(t *testing.T) {
+	dir := newArtifactDir(t)
+
+	sig, err := MethodSig(transferSelector, dir, "")
+	want := "transfer(address,uint256)\n  defined in HardhatToken (hardhat/Token.json)\n  defined in Vault (out/Vault.sol/Vault.json)"
+	if err != nil || sig != want {
+		t.Errorf("MethodSig(%s) = %q, %v, want %q", transferSelector, sig, err, want)
+	}
+
+	sig, err = EventSig("0xc36b5179cb9c303b200074996eab2b3473eac370fdd7eba3bec636fe35109696", dir, "")
+	want = "Swept(address,uint256)\n  defined in Vault (out/Vault.sol/Vault.json)"
+	if err != nil || sig != want {
+		t.Errorf("EventSig = %q, %v, want %q", sig, err, want)
+	}
+
+	if _, err := ErrorSig("0x12345678", dir, ""); !errors.Is(err, ErrSelectorNotFound) {
+		t.Errorf("ErrorSig of an unknown selector: error = %v, want %v", err, ErrSelectorNotFound)
+	}
+	if _, err := MethodSig("0x1234", dir, ""); !errors.Is(err, encdec.ErrInvalidHex) {
+		t.Errorf("MethodSig of a short selector: error = %v, want %v", err, encdec.ErrInvalidHex)
+	}
+
+	// other commands load every ABI in the directory, merged.
+	if got := NewAbiSource(dir, ""); got != (DirSource{Dir: dir}) {
+		t.Errorf("NewAbiSource(%q) = %#v, want a DirSource", dir, got)
+	}
+	parsed, err := LoadABI(DirSource{Dir: dir})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"transfer", "sweep"} {
+		if _, ok := parsed.Methods[name]; !ok {
+			t.Errorf("LoadABI(DirSource) has no method %s", name)
+		}
+	}
+
+	if _, err := LoadABI(DirSource{Dir: t.TempDir()}); !errors.Is(err, ErrNoAbiSource) {
+		t.Errorf("LoadABI of an empty directory: error = %v, want %v", err, ErrNoAbiSource)
+	}
+}
@@ -0,0 +1,25 @@
+package selector
+
+import "errors"
+
+// Sentinel errors returned by the error-returning API in this package.
+// Callers can match them with errors.Is. Selectors and topic hashes that are not
+// valid hex wrap encdec.ErrInvalidHex.
+var (
+	// ErrSelectorNotFound is returned when no function, error or event in the ABI
+	// matches the given selector or topic hash.
+	ErrSelectorNotFound = errors.New("selector not found")
+
+	// ErrInvalidSignature is returned when a function signature is malformed.
+	ErrInvalidSignature = errors.New("invalid signature")
+
+	// ErrNoAbiSource is returned when neither an ABI file path nor a URL is given.
+	ErrNoAbiSource = errors.New("no ABI source")
+
+	// ErrInvalidAbi is returned when the ABI source cannot be parsed into an ABI.
+	ErrInvalidAbi = errors.New("invalid ABI")
+
+	// ErrSelectorCollision is returned when functions with different signatures
+	// share a selector. See FindCollisions.
+	ErrSelectorCollision = errors.New("selector collision")
+)
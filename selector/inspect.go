@@ -0,0 +1,182 @@
+package selector
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+// The output formats of FormatEntries.
+const (
+	FormatText     = "text"
+	FormatJSON     = "json"
+	FormatMarkdown = "markdown"
+)
+
+// A function, event or error of an ABI.
+type AbiEntry struct {
+	Kind SigKind `json:"kind"`
+	// The function or error selector, or the event's topic0.
+	ID string `json:"id"`
+	// The canonical signature computed by go-ethereum, eg: abi.Method.Sig.
+	Signature string `json:"signature"`
+	// The name go-ethereum gives the entry in the ABI, which is unique: overloads of
+	// a function get a numeric suffix, eg: "safeTransferFrom0".
+	Name string `json:"name"`
+	// Whether other entries of the same kind have the same name, but other inputs.
+	Overloaded bool `json:"overloaded,omitempty"`
+	// "pure", "view", "nonpayable" or "payable", for functions.
+	StateMutability string `json:"stateMutability,omitempty"`
+	// Whether the event is anonymous, in which case topic0 is not its ID.
+	Anonymous bool `json:"anonymous,omitempty"`
+}
+
+// The order of the sections of FormatEntries, and the header of their ID column.
+var entrySections = []struct {
+	kind     SigKind
+	title    string
+	idHeader string
+}{
+	{KindFunction, "Functions", "Selector"},
+	{KindEvent, "Events", "Topic0"},
+	{KindError, "Errors", "Selector"},
+}
+
+// Returns every function, event and error of `parsedAbi`, functions first, then events,
+// then errors, each sorted by signature.
+func AbiEntries(parsedAbi *abi.ABI) []AbiEntry {
+	var entries []AbiEntry
+	for name, method := range parsedAbi.Methods {
+		entries = append(entries, AbiEntry{
+			Kind:            KindFunction,
+			ID:              hexutil.Encode(method.ID),
+			Signature:       method.Sig,
+			Name:            name,
+			StateMutability: method.StateMutability,
+		})
+	}
+	for name, ev := range parsedAbi.Events {
+		entries = append(entries, AbiEntry{
+			Kind:      KindEvent,
+			ID:        ev.ID.Hex(),
+			Signature: ev.Sig,
+			Name:      name,
+			Anonymous: ev.Anonymous,
+		})
+	}
+	for name, abiErr := range parsedAbi.Errors {
+		entries = append(entries, AbiEntry{
+			Kind:      KindError,
+			ID:        hexutil.Encode(abiErr.ID[:4]),
+			Signature: abiErr.Sig,
+			Name:      name,
+		})
+	}
+
+	// Signatures start with the raw name, up to the parenthesis.
+	rawNames := map[string]int{}
+	for _, entry := range entries {
+		rawNames[entry.Kind.String()+" "+rawName(entry.Signature)]++
+	}
+	for idx, entry := range entries {
+		entries[idx].Overloaded = rawNames[entry.Kind.String()+" "+rawName(entry.Signature)] > 1
+	}
+
+	order := map[SigKind]int{}
+	for idx, section := range entrySections {
+		order[section.kind] = idx
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		if entries[i].Kind != entries[j].Kind {
+			return order[entries[i].Kind] < order[entries[j].Kind]
+		}
+		return entries[i].Signature < entries[j].Signature
+	})
+	return entries
+}
+
+func rawName(sig string) string {
+	return sig[:strings.Index(sig, "(")]
+}
+
+// Formats `entries` as one table per kind in the text or markdown format, or as a
+// JSON array.
+func FormatEntries(entries []AbiEntry, format string) (string, error) {
+	switch format {
+	case FormatJSON:
+		if entries == nil {
+			entries = []AbiEntry{}
+		}
+		b, err := json.MarshalIndent(entries, "", "  ")
+		if err != nil {
+			return "", err
+		}
+		return string(b), nil
+	case FormatText, FormatMarkdown:
+	default:
+		return "", fmt.Errorf("unknown output format %q, must be %q, %q or %q", format, FormatText, FormatJSON, FormatMarkdown)
+	}
+
+	var sections []string
+	for _, section := range entrySections {
+		var rows [][]string
+		for _, entry := range entries {
+			if entry.Kind == section.kind {
+				rows = append(rows, []string{entry.ID, entry.Signature, entryNotes(entry), entry.Name})
+			}
+		}
+		if len(rows) == 0 {
+			continue
+		}
+		header := []string{section.idHeader, "Signature", "Notes", "Name"}
+
+		var buf bytes.Buffer
+		if format == FormatMarkdown {
+			fmt.Fprintf(&buf, "## %s\n\n", section.title)
+			writeMarkdownTable(&buf, header, rows)
+		} else {
+			fmt.Fprintf(&buf, "%s\n", strings.ToUpper(section.title))
+			w := tabwriter.NewWriter(&buf, 0, 0, 2, ' ', 0)
+			for _, row := range append([][]string{header}, rows...) {
+				fmt.Fprintln(w, strings.Join(row, "\t"))
+			}
+			w.Flush()
+		}
+		sections = append(sections, strings.TrimRight(buf.String(), "\n"))
+	}
+	return strings.Join(sections, "\n\n"), nil
+}
+
+// Returns the state mutability of a function, and whether an event is anonymous or an
+// entry is overloaded.
+func entryNotes(entry AbiEntry) string {
+	var notes []string
+	if entry.StateMutability != "" {
+		notes = append(notes, entry.StateMutability)
+	}
+	if entry.Anonymous {
+		notes = append(notes, "anonymous")
+	}
+	if entry.Overloaded {
+		notes = append(notes, "overloaded")
+	}
+	if len(notes) == 0 {
+		return "-"
+	}
+	return strings.Join(notes, ", ")
+}
+
+// Writes a markdown table, with the IDs, signatures and names as code.
+func writeMarkdownTable(buf *bytes.Buffer, header []string, rows [][]string) {
+	fmt.Fprintf(buf, "| %s |\n", strings.Join(header, " | "))
+	fmt.Fprintf(buf, "|%s\n", strings.Repeat(" --- |", len(header)))
+	for _, row := range rows {
+		fmt.Fprintf(buf, "| `%s` | `%s` | %s | `%s` |\n", row[0], row[1], row[2], row[3])
+	}
+}
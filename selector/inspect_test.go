@@ -0,0 +1,126 @@
+package selector
+
+import (
+	"encoding/json"
+	"path"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestAbiEntries(t *testing.T) {
+	parsedAbi, err := LoadABI(FileSource{Path: path.Join("testdata", "overloads.abi.json")})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []AbiEntry{
+		{Kind: KindFunction, ID: "0x70a08231", Signature: "balanceOf(address)", Name: "balanceOf", StateMutability: "view"},
+		{Kind: KindFunction, ID: "0x7e0b4201", Signature: "mint((address,uint256)[])", Name: "mint", StateMutability: "payable"},
+		{Kind: KindFunction, ID: "0x42842e0e", Signature: "safeTransferFrom(address,address,uint256)", Name: "safeTransferFrom", Overloaded: true, StateMutability: "nonpayable"},
+		{Kind: KindFunction, ID: "0xb88d4fde", Signature: "safeTransferFrom(address,address,uint256,bytes)", Name: "safeTransferFrom0", Overloaded: true, StateMutability: "nonpayable"},
+		{Kind: KindEvent, ID: "0x8a36f5a234186d446e36a7df36ace663a05a580d9bea2dd899c6dd76a075d5fa", Signature: "Debug(uint256)", Name: "Debug", Anonymous: true},
+		{Kind: KindEvent, ID: "0xddf252ad1be2c89b69c2b068fc378daa952ba7f163c4a11628f55a4df523b3ef", Signature: "Transfer(address,address,uint256)", Name: "Transfer"},
+		{Kind: KindError, ID: "0x245aecd3", Signature: "NotOwner(address)", Name: "NotOwner"},
+	}
+	got := AbiEntries(parsedAbi)
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("AbiEntries() = %+v, want %+v", got, want)
+	}
+
+	// the IDs are the ones every other command computes.
+	for _, entry := range got {
+		sigID, err := Signature{Kind: entry.Kind, Text: entry.Signature}.ID()
+		if err != nil {
+			t.Fatalf("%s: %v", entry.Signature, err)
+		}
+		if entry.ID != sigID {
+			t.Errorf("%s has ID %s, want %s", entry.Signature, entry.ID, sigID)
+		}
+	}
+}
+
+func TestFormatEntries(t *testing.T) {
+	entries := []AbiEntry{
+		{Kind: KindFunction, ID: "0x42842e0e", Signature: "safeTransferFrom(address,address,uint256)", Name: "safeTransferFrom", Overloaded: true, StateMutability: "nonpayable"},
+		{Kind: KindFunction, ID: "0xb88d4fde", Signature: "safeTransferFrom(address,address,uint256,bytes)", Name: "safeTransferFrom0", Overloaded: true, StateMutability: "nonpayable"},
+		{Kind: KindError, ID: "0x245aecd3", Signature: "NotOwner(address)", Name: "NotOwner"},
+	}
+
+	tests := []struct {
+		name    string
+		format  string
+		want    string
+		wantErr string
+	}{
+		{
+			name:   "text",
+			format: FormatText,
+			want: strings.Join([]string{
+				"FUNCTIONS",
+				"Selector    Signature                                        Notes                   Name",
+				"0x42842e0e  safeTransferFrom(address,address,uint256)        nonpayable, overloaded  safeTransferFrom",
+				"0xb88d4fde  safeTransferFrom(address,address,uint256,bytes)  nonpayable, overloaded  safeTransferFrom0",
+				"",
+				"ERRORS",
+				"Selector    Signature          Notes  Name",
+				"0x245aecd3  NotOwner(address)  -      NotOwner",
+			}, "\n"),
+		},
+		{
+			name:   "markdown",
+			format: FormatMarkdown,
+			want: strings.Join([]string{
+				"## Functions",
+				"",
+				"| Selector | Signature | Notes | Name |",
+				"| --- | --- | --- | --- |",
+				"| `0x42842e0e` | `safeTransferFrom(address,address,uint256)` | nonpayable, overloaded | `safeTransferFrom` |",
+				"| `0xb88d4fde` | `safeTransferFrom(address,address,uint256,bytes)` | nonpayable, overloaded | `safeTransferFrom0` |",
+				"",
+				"## Errors",
+				"",
+				"| Selector | Signature | Notes | Name |",
+				"| --- | --- | --- | --- |",
+				"| `0x245aecd3` | `NotOwner(address)` | - | `NotOwner` |",
+			}, "\n"),
+		},
+		{
+			name:    "unknown format",
+			format:  "yaml",
+			wantErr: `unknown output format "yaml"`,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := FormatEntries(entries, tc.format)
+			if tc.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tc.wantErr) {
+					t.Errorf("error = %v, want it to contain %q", err, tc.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tc.want {
+				t.Errorf("FormatEntries() =\n%s\nwant\n%s", got, tc.want)
+			}
+		})
+	}
+
+	t.Run("json", func(t *testing.T) {
+		got, err := FormatEntries(entries, FormatJSON)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var decoded []AbiEntry
+		if err := json.Unmarshal([]byte(got), &decoded); err != nil || !reflect.DeepEqual(decoded, entries) {
+			t.Errorf("FormatEntries() JSON decodes to %+v, %v, want %+v", decoded, err, entries)
+		}
+		if got, _ := FormatEntries(nil, FormatJSON); got != "[]" {
+			t.Errorf("FormatEntries(nil) = %s, want []", got)
+		}
+	})
+}
@@ -0,0 +1,339 @@
+package selector
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// The environment variable that overrides the location of the registry file.
+const RegistryPathEnv = "HEXTOOL_SIGDB"
+
+// A local, user-extensible set of signatures, stored as a JSON file. Unlike the
+// built-in database, each entry records a single source, eg: the contract whose
+// artifact the signature was imported from.
+type Registry struct {
+	path    string
+	entries []indexedSig // the ID of each entry is computed once, when it is added.
+}
+
+// Returns the path of the registry file: $HEXTOOL_SIGDB if set, otherwise
+// `hextool/signatures.json` under the user config dir, eg: ~/.config on Linux.
+func DefaultRegistryPath() (string, error) {
+	if registryPath := os.Getenv(RegistryPathEnv); registryPath != "" {
+		return registryPath, nil
+	}
+	configDir, err := os.UserConfigDir()
+	if err != nil {
+		return "", fmt.Errorf("locating the signature registry: %w", err)
+	}
+	return filepath.Join(configDir, "hextool", "signatures.json"), nil
+}
+
+// Reads the registry file at `registryPath`. A missing file is an empty registry.
+func OpenRegistry(registryPath string) (*Registry, error) {
+	r := &Registry{path: registryPath}
+
+	b, err := os.ReadFile(registryPath)
+	if errors.Is(err, fs.ErrNotExist) {
+		return r, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error reading signature registry: %w", err)
+	}
+	var sigs []Signature
+	if err := json.Unmarshal(b, &sigs); err != nil {
+		return nil, fmt.Errorf("error parsing signature registry at %s: %w", registryPath, err)
+	}
+	if _, err := r.Add(sigs...); err != nil {
+		return nil, fmt.Errorf("error parsing signature registry at %s: %w", registryPath, err)
+	}
+	return r, nil
+}
+
+// Opens the registry at DefaultRegistryPath.
+func OpenDefaultRegistry() (*Registry, error) {
+	registryPath, err := DefaultRegistryPath()
+	if err != nil {
+		return nil, err
+	}
+	return OpenRegistry(registryPath)
+}
+
+func (r *Registry) Path() string {
+	return r.path
+}
+
+// Writes the registry to its file, creating the parent directory if needed.
+func (r *Registry) Save() error {
+	if err := os.MkdirAll(filepath.Dir(r.path), 0o755); err != nil {
+		return fmt.Errorf("error creating signature registry directory: %w", err)
+	}
+	b, err := json.MarshalIndent(r.List(), "", "  ")
+	if err != nil {
+		return fmt.Errorf("error encoding signature registry: %w", err)
+	}
+	if err := os.WriteFile(r.path, append(b, '\n'), 0o644); err != nil {
+		return fmt.Errorf("error writing signature registry: %w", err)
+	}
+	return nil
+}
+
+// Adds `sigs` to the registry, skipping those it already holds from the same
+// source, and returns how many were added. Nothing is added when the text of one
+// of `sigs` is malformed, and the error wraps ErrInvalidSignature.
+func (r *Registry) Add(sigs ...Signature) (int, error) {
+	entries := make([]indexedSig, len(sigs))
+	for idx, sig := range sigs {
+		id, err := sig.ID()
+		if err != nil {
+			return 0, fmt.Errorf("%s %s: %w", sig.Kind, sig.Text, err)
+		}
+		entries[idx] = indexedSig{Signature: sig, ID: id}
+	}
+
+	added := 0
+	for _, entry := range entries {
+		if !r.contains(entry.Signature) {
+			r.entries = append(r.entries, entry)
+			added++
+		}
+	}
+	r.sort()
+	return added, nil
+}
+
+func (r *Registry) contains(sig Signature) bool {
+	for _, entry := range r.entries {
+		if entry.Signature == sig {
+			return true
+		}
+	}
+	return false
+}
+
+// Removes the entries whose signature text is `sig` (when not empty) and whose
+// source is `source` (when not empty), and returns how many were removed.
+func (r *Registry) Remove(sig string, source string) int {
+	if sig == "" && source == "" {
+		return 0
+	}
+	sig = strings.ReplaceAll(sig, " ", "")
+
+	kept := r.entries[:0]
+	for _, entry := range r.entries {
+		if (sig == "" || entry.Text == sig) && (source == "" || entry.Source == source) {
+			continue
+		}
+		kept = append(kept, entry)
+	}
+	removed := len(r.entries) - len(kept)
+	r.entries = kept
+	return removed
+}
+
+// Returns every entry, sorted by kind, text and source.
+func (r *Registry) List() []Signature {
+	sigs := make([]Signature, len(r.entries))
+	for idx, entry := range r.entries {
+		sigs[idx] = entry.Signature
+	}
+	return sigs
+}
+
+// Returns the entries of `kind` whose selector or topic hash is `id`, in the form
+// Signature.ID returns it, sorted by text and source.
+func (r *Registry) Lookup(kind SigKind, id string) []Signature {
+	id = strings.ToLower(id)
+	var matches []Signature
+	for _, entry := range r.entries {
+		if entry.Kind == kind && entry.ID == id {
+			matches = append(matches, entry.Signature)
+		}
+	}
+	return matches
+}
+
+// Returns the entries whose selector or topic hash equals `query`, or whose
+// signature text or source contains it, ignoring case.
+func (r *Registry) Search(query string) []Signature {
+	query = strings.ToLower(strings.TrimSpace(query))
+
+	var matches []Signature
+	for _, entry := range r.entries {
+		if entry.ID == query ||
+			strings.Contains(strings.ToLower(entry.Text), query) ||
+			strings.Contains(strings.ToLower(entry.Source), query) {
+			matches = append(matches, entry.Signature)
+		}
+	}
+	return matches
+}
+
+func (r *Registry) sort() {
+	sort.Slice(r.entries, func(i, j int) bool {
+		a, b := r.entries[i], r.entries[j]
+		if a.Kind != b.Kind {
+			return a.Kind < b.Kind
+		}
+		if a.Text != b.Text {
+			return a.Text < b.Text
+		}
+		return a.Source < b.Source
+	})
+}
+
+// Reads the ABIs from the compiled artifact or ABI file at `artifactPath` and returns
+// the signatures of their functions, events and errors (see contractSignatures).
+// The source of each signature is the name of its contract, see parseContractAbis.
+func SignaturesFromArtifact(artifactPath string) ([]Signature, error) {
+	b, err := os.ReadFile(artifactPath)
+	if err != nil {
+		return nil, fmt.Errorf("error reading artifact: %w", err)
+	}
+
+	contracts, err := parseContractAbis(AbiDocument{Name: artifactPath, Data: b})
+	if err != nil {
+		return nil, err
+	}
+
+	var sigs []Signature
+	for _, contract := range contracts {
+		sigs = append(sigs, contractSignatures(contract)...)
+	}
+	return sigs, nil
+}
+
+// The signatures read from the files matching some patterns, see SignaturesFromArtifacts.
+type ArtifactImport struct {
+	Signatures []Signature
+	// The number of files the signatures were read from.
+	Files int
+	// The files matched by a glob that hold no ABI, eg: Foundry's out/build-info/*.json.
+	Skipped []string
+}
+
+// Reads the signatures from the compiled artifacts or ABI files matching `patterns`, see
+// ExpandGlob and SignaturesFromArtifact. Several patterns are the files the shell expanded
+// an unquoted glob into. A file matched by a glob, or expanded by the shell, that holds no
+// ABI is skipped, so that a glob such as "out/**/*.json" can match Foundry's build-info
+// files next to the artifacts. It fails when no file matches, or no file holds a signature.
+func SignaturesFromArtifacts(patterns []string) (*ArtifactImport, error) {
+	imported := &ArtifactImport{}
+	matched := 0
+	for _, pattern := range patterns {
+		artifactPaths, err := ExpandGlob(pattern)
+		if err != nil {
+			return nil, err
+		}
+		fromGlob := len(patterns) > 1 || strings.ContainsAny(pattern, "*?[")
+		for _, artifactPath := range artifactPaths {
+			matched++
+			sigs, err := SignaturesFromArtifact(artifactPath)
+			if err != nil {
+				if fromGlob && errors.Is(err, ErrInvalidAbi) {
+					imported.Skipped = append(imported.Skipped, artifactPath)
+					continue
+				}
+				return nil, err
+			}
+			imported.Signatures = append(imported.Signatures, sigs...)
+			imported.Files++
+		}
+	}
+
+	if matched == 0 {
+		return nil, fmt.Errorf("no files match %s", strings.Join(patterns, " "))
+	}
+	if len(imported.Signatures) == 0 {
+		return nil, fmt.Errorf("%w: no file matching %s holds a signature, %d files without an ABI were skipped", ErrInvalidAbi, strings.Join(patterns, " "), len(imported.Skipped))
+	}
+	return imported, nil
+}
+
+// Returns the files matching `pattern`. In addition to the syntax of filepath.Match,
+// a `**` path element matches any number of directories, eg: "out/**/*.json".
+func ExpandGlob(pattern string) ([]string, error) {
+	pattern = filepath.Clean(pattern)
+	elems := strings.Split(filepath.ToSlash(pattern), "/")
+
+	starIdx := -1
+	for idx, elem := range elems {
+		if elem == "**" {
+			starIdx = idx
+			break
+		}
+	}
+	if starIdx == -1 {
+		matches, err := filepath.Glob(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid pattern %q: %w", pattern, err)
+		}
+		return matches, nil
+	}
+
+	root := filepath.FromSlash(strings.Join(elems[:starIdx], "/"))
+	if root == "" {
+		root = "."
+		if strings.HasPrefix(pattern, "/") {
+			root = "/"
+		}
+	}
+	rest := elems[starIdx:]
+
+	var matches []string
+	err := filepath.WalkDir(root, func(walkPath string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			return nil
+		}
+		rel, err := filepath.Rel(root, walkPath)
+		if err != nil {
+			return err
+		}
+		ok, err := matchElems(rest, strings.Split(filepath.ToSlash(rel), "/"))
+		if err != nil {
+			return fmt.Errorf("invalid pattern %q: %w", pattern, err)
+		}
+		if ok {
+			matches = append(matches, walkPath)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return matches, nil
+}
+
+// Reports whether the path elements `names` match the pattern elements `pattern`,
+// where a "**" element matches zero or more path elements.
+func matchElems(pattern []string, names []string) (bool, error) {
+	if len(pattern) == 0 {
+		return len(names) == 0, nil
+	}
+	if pattern[0] == "**" {
+		for skip := 0; skip <= len(names); skip++ {
+			ok, err := matchElems(pattern[1:], names[skip:])
+			if ok || err != nil {
+				return ok, err
+			}
+		}
+		return false, nil
+	}
+	if len(names) == 0 {
+		return false, nil
+	}
+	ok, err := filepath.Match(pattern[0], names[0])
+	if !ok || err != nil {
+		return false, err
+	}
+	return matchElems(pattern[1:], names[1:])
+}
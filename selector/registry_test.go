@@ -0,0 +1,340 @@
+package selector
+
+import (
+	"errors"
+	"os"
+	"path"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// Points the signature registry at a file that does not exist, so that lookups
+// without an ABI only see the built-in signatures, whatever the user has registered.
+func TestMain(m *testing.M) {
+	dir, err := os.MkdirTemp("", "hextool-selector-test")
+	if err != nil {
+		panic(err)
+	}
+	os.Setenv(RegistryPathEnv, filepath.Join(dir, "signatures.json"))
+	os.Setenv(CacheDirEnv, filepath.Join(dir, "cache"))
+	code := m.Run()
+	os.RemoveAll(dir)
+	os.Exit(code)
+}
+
+var (
+	vaultArtifact = path.Join("testdata", "artifacts", "out", "Vault.sol", "Vault.json")
+	tokenArtifact = path.Join("testdata", "artifacts", "hardhat", "Token.json")
+)
+
+func TestSignaturesFromArtifact(t *testing.T) {
+	tests := []struct {
+		name     string
+		artifact string
+		want     []Signature
+		wantErr  error
+	}{
+		{
+			name:     "foundry artifact - source from file name, anonymous events skipped",
+			artifact: vaultArtifact,
+			want: []Signature{
+				{Kind: KindFunction, Text: "sweep(address,uint256[])", Source: "Vault"},
+				{Kind: KindFunction, Text: "transfer(address,uint256)", Source: "Vault"},
+				{Kind: KindError, Text: "VaultLocked(uint64)", Source: "Vault"},
+				{Kind: KindEvent, Text: "Swept(address,uint256)", Source: "Vault"},
+			},
+		},
+		{
+			name:     "hardhat artifact - source from contractName",
+			artifact: tokenArtifact,
+			want: []Signature{
+				{Kind: KindFunction, Text: "transfer(address,uint256)", Source: "HardhatToken"},
+			},
+		},
+		{
+			name:     "abi array",
+			artifact: path.Join("testdata", "erc20.abi-array.json"),
+		},
+		{
+			name:     "not an artifact",
+			artifact: path.Join("testdata", "bad-abi.json"),
+			wantErr:  ErrInvalidAbi,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := SignaturesFromArtifact(tc.artifact)
+			if tc.wantErr != nil {
+				if !errors.Is(err, tc.wantErr) {
+					t.Errorf("error = %v, want %v", err, tc.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if tc.want == nil {
+				if len(got) == 0 {
+					t.Error("no signatures")
+				}
+				for _, sig := range got {
+					if sig.Source != "erc20" {
+						t.Errorf("source of %s = %q, want %q", sig.Text, sig.Source, "erc20")
+					}
+				}
+				return
+			}
+
+			r, _ := OpenRegistry(filepath.Join(t.TempDir(), "signatures.json"))
+			r.Add(got...)
+			if !reflect.DeepEqual(r.List(), tc.want) {
+				t.Errorf("signatures = %v, want %v", r.List(), tc.want)
+			}
+		})
+	}
+}
+
+// Foundry writes build-info files without an ABI next to the artifacts, which a glob such
+// as "out/**/*.json" matches too.
+func TestSignaturesFromArtifacts(t *testing.T) {
+	buildInfo := path.Join("testdata", "foundry", "out", "build-info", "6b0b7b3e8cbd32b3.json")
+	erc20 := path.Join("testdata", "foundry", "out", "ERC20.sol", "ERC20.json")
+	want := []Signature{
+		{Kind: KindFunction, Text: "approve(address,uint256)", Source: "ERC20"},
+		{Kind: KindEvent, Text: "Approval(address,address,uint256)", Source: "ERC20"},
+	}
+
+	tests := []struct {
+		name        string
+		patterns    []string
+		wantSkipped []string
+		wantErr     error
+	}{
+		{
+			name:        "glob skips build-info",
+			patterns:    []string{"testdata/foundry/out/**/*.json"},
+			wantSkipped: []string{buildInfo},
+		},
+		{
+			name:        "glob expanded by the shell",
+			patterns:    []string{buildInfo, erc20},
+			wantSkipped: []string{buildInfo},
+		},
+		{
+			name:     "plain path without an ABI",
+			patterns: []string{buildInfo},
+			wantErr:  ErrInvalidAbi,
+		},
+		{
+			name:     "glob matching no ABI",
+			patterns: []string{"testdata/foundry/out/build-info/*.json"},
+			wantErr:  ErrInvalidAbi,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := SignaturesFromArtifacts(tc.patterns)
+			if tc.wantErr != nil {
+				if !errors.Is(err, tc.wantErr) {
+					t.Errorf("error = %v, want %v", err, tc.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got.Files != 1 || !reflect.DeepEqual(got.Skipped, tc.wantSkipped) {
+				t.Errorf("files = %d, skipped = %v, want 1 and %v", got.Files, got.Skipped, tc.wantSkipped)
+			}
+			r, _ := OpenRegistry(filepath.Join(t.TempDir(), "signatures.json"))
+			r.Add(got.Signatures...)
+			if !reflect.DeepEqual(r.List(), want) {
+				t.Errorf("signatures = %v, want %v", r.List(), want)
+			}
+		})
+	}
+
+	if _, err := SignaturesFromArtifacts([]string{"testdata/foundry/**/*.sol"}); err == nil {
+		t.Error("SignaturesFromArtifacts() of a pattern matching no files: expected an error")
+	}
+}
+
+func TestExpandGlob(t *testing.T) {
+	tests := []struct {
+		name    string
+		pattern string
+		want    []string
+	}{
+		{
+			name:    "double star",
+			pattern: "testdata/artifacts/**/*.json",
+			want:    []string{tokenArtifact, vaultArtifact},
+		},
+		{
+			name:    "double star matches no directories",
+			pattern: "testdata/artifacts/hardhat/**/*.json",
+			want:    []string{tokenArtifact},
+		},
+		{
+			name:    "double star in the middle",
+			pattern: "testdata/**/Vault.sol/*.json",
+			want:    []string{vaultArtifact},
+		},
+		{
+			name:    "single star",
+			pattern: "testdata/artifacts/*/*.json",
+			want:    []string{tokenArtifact},
+		},
+		{
+			name:    "plain path",
+			pattern: tokenArtifact,
+			want:    []string{tokenArtifact},
+		},
+		{
+			name:    "no match",
+			pattern: "testdata/artifacts/**/*.sol",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := ExpandGlob(tc.pattern)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if len(got) != len(tc.want) || (len(got) > 0 && !reflect.DeepEqual(got, tc.want)) {
+				t.Errorf("ExpandGlob(%q) = %v, want %v", tc.pattern, got, tc.want)
+			}
+		})
+	}
+}
+
+func TestRegistry(t *testing.T) {
+	registryPath := filepath.Join(t.TempDir(), "nested", "signatures.json")
+	t.Setenv(RegistryPathEnv, registryPath)
+
+	r, err := OpenDefaultRegistry()
+	if err != nil {
+		t.Fatalf("opening a missing registry: %v", err)
+	}
+	if len(r.List()) != 0 {
+		t.Fatalf("new registry has entries: %v", r.List())
+	}
+
+	for _, artifact := range []string{vaultArtifact, tokenArtifact, vaultArtifact} {
+		sigs, err := SignaturesFromArtifact(artifact)
+		if err != nil {
+			t.Fatal(err)
+		}
+		r.Add(sigs...)
+	}
+	if got := len(r.List()); got != 5 {
+		t.Errorf("registry has %d entries after adding the same artifact twice, want 5", got)
+	}
+	if err := r.Save(); err != nil {
+		t.Fatal(err)
+	}
+
+	// all selector lookups without an ABI consult the saved registry.
+	lookups := []struct {
+		name   string
+		lookup func() (string, error)
+		want   string
+	}{
+		{
+			name:   "MethodSig",
+			lookup: func() (string, error) { return MethodSig("0x62caf2b9", "", "") },
+			want:   "sweep(address,uint256[])",
+		},
+		{
+			name:   "MethodSig - also built-in",
+			lookup: func() (string, error) { return MethodSig("0xa9059cbb", "", "") },
+			want:   "transfer(address,uint256)",
+		},
+		{
+			name:   "ErrorSig",
+			lookup: func() (string, error) { return ErrorSig("0x07711d3e", "", "") },
+			want:   "VaultLocked(uint64)",
+		},
+		{
+			name: "EventSig",
+			lookup: func() (string, error) {
+				return EventSig("0xc36b5179cb9c303b200074996eab2b3473eac370fdd7eba3bec636fe35109696", "", "")
+			},
+			want: "Swept(address,uint256)",
+		},
+	}
+	for _, tc := range lookups {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := tc.lookup()
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got != tc.want {
+				t.Errorf("got %s, want %s", got, tc.want)
+			}
+		})
+	}
+
+	reopened, err := OpenRegistry(registryPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	searches := []struct {
+		query string
+		want  int
+	}{
+		{query: "0x62CAF2B9", want: 1},
+		{query: "transfer", want: 2},
+		{query: "vault", want: 4},
+		{query: "nothing", want: 0},
+	}
+	for _, tc := range searches {
+		if got := reopened.Search(tc.query); len(got) != tc.want {
+			t.Errorf("Search(%q) = %v, want %d matches", tc.query, got, tc.want)
+		}
+	}
+
+	if got := reopened.Lookup(KindFunction, "0x62CAF2B9"); len(got) != 1 || got[0].Text != "sweep(address,uint256[])" {
+		t.Errorf("Lookup = %v, want sweep(address,uint256[])", got)
+	}
+
+	if got := reopened.Remove("transfer(address, uint256)", "HardhatToken"); got != 1 {
+		t.Errorf("Remove(sig, source) removed %d, want 1", got)
+	}
+	if got := reopened.Remove("", "Vault"); got != 4 {
+		t.Errorf("Remove(source) removed %d, want 4", got)
+	}
+	if got := reopened.Remove("", ""); got != 0 {
+		t.Errorf("Remove with no filter removed %d, want 0", got)
+	}
+	if len(reopened.List()) != 0 {
+		t.Errorf("registry has entries after removing all of them: %v", reopened.List())
+	}
+}
+
+// The selectors and topic hashes of the entries are computed when they are added, so a
+// malformed signature is rejected there, whether it is added or read from the file.
+func TestRegistryRejectsInvalidSignatures(t *testing.T) {
+	registryPath := filepath.Join(t.TempDir(), "signatures.json")
+	r, err := OpenRegistry(registryPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	valid := Signature{Kind: KindFunction, Text: "sweep(address,uint256[])", Source: "Vault"}
+	invalid := Signature{Kind: KindFunction, Text: "sweep(address", Source: "Vault"}
+	if _, err := r.Add(valid, invalid); !errors.Is(err, ErrInvalidSignature) {
+		t.Errorf("Add error = %v, want %v", err, ErrInvalidSignature)
+	}
+	if len(r.List()) != 0 {
+		t.Errorf("registry has entries after a failed Add: %v", r.List())
+	}
+
+	writeFile(t, registryPath, `[{"kind": "function", "signature": "sweep(address", "source": "Vault"}]`)
+	if _, err := OpenRegistry(registryPath); !errors.Is(err, ErrInvalidSignature) {
+		t.Errorf("OpenRegistry error = %v, want %v", err, ErrInvalidSignature)
+	}
+}
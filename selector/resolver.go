@@ -0,0 +1,227 @@
+package selector
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// A SignatureResolver looks up the signatures that a selector (for functions
+// and errors) or topic hash (for events) could have been computed from.
+type SignatureResolver interface {
+	Resolve(kind SigKind, id string) ([]Signature, error)
+}
+
+// The response formats understood by HTTPResolver.
+const (
+	// The openchain.xyz signature database API:
+	// GET <base>/signature-database/v1/lookup?function=0x...&filter=true
+	FormatOpenchain = "openchain"
+	// The 4byte.directory API:
+	// GET <base>/api/v1/signatures/?hex_signature=0x...
+	Format4byte = "4byte"
+)
+
+// The public endpoints of each format.
+var defaultResolverURLs = map[string]string{
+	FormatOpenchain: "https://api.openchain.xyz",
+	Format4byte:     "https://www.4byte.directory",
+}
+
+const defaultResolverTimeout = 10 * time.Second
+
+// An HTTPResolver looks up signatures in a signature directory such as 4byte.directory or
+// openchain.xyz, or a server that mimics one of them. Successful lookups are cached on disk.
+type HTTPResolver struct {
+	BaseURL  string
+	Format   string // FormatOpenchain or Format4byte.
+	CacheDir string // where to cache responses. Caching is disabled when empty.
+	Client   *http.Client
+}
+
+// Returns an HTTPResolver for `baseURL` that caches responses under the user
+// cache dir and gives up on requests after `timeout`. `baseURL` may also be just
+// the name of a format, eg: "4byte", to use its public endpoint.
+func NewHTTPResolver(baseURL string, format string, timeout time.Duration) (*HTTPResolver, error) {
+	if publicURL, ok := defaultResolverURLs[baseURL]; ok {
+		format, baseURL = baseURL, publicURL
+	}
+	if format == "" {
+		format = FormatOpenchain
+	}
+	if _, ok := defaultResolverURLs[format]; !ok {
+		return nil, fmt.Errorf("unknown resolver format %q, must be %q or %q", format, FormatOpenchain, Format4byte)
+	}
+	if u, err := url.Parse(baseURL); err != nil || u.Scheme == "" || u.Host == "" {
+		return nil, fmt.Errorf("invalid resolver URL %q", baseURL)
+	}
+	if timeout <= 0 {
+		timeout = defaultResolverTimeout
+	}
+
+	resolverCacheDir := ""
+	if dir := cacheDir(); dir != "" {
+		resolverCacheDir = filepath.Join(dir, "resolver")
+	}
+
+	return &HTTPResolver{
+		BaseURL:  strings.TrimSuffix(baseURL, "/"),
+		Format:   format,
+		CacheDir: resolverCacheDir,
+		Client:   &http.Client{Timeout: timeout},
+	}, nil
+}
+
+// Returns the signatures the directory holds for `id`. Signatures whose selector or topic
+// hash does not actually match `id` are dropped, so a bad response cannot mislead.
+func (r *HTTPResolver) Resolve(kind SigKind, id string) ([]Signature, error) {
+	id, err := normalizeID(kind, id)
+	if err != nil {
+		return nil, err
+	}
+
+	texts, ok := r.readCache(kind, id)
+	if !ok {
+		if texts, err = r.fetch(kind, id); err != nil {
+			return nil, err
+		}
+		if len(texts) > 0 {
+			r.writeCache(kind, id, texts)
+		}
+	}
+
+	var sigs []Signature
+	for _, text := range texts {
+		sig := Signature{Kind: kind, Text: text, Source: r.BaseURL}
+		if sigID, err := sig.ID(); err == nil && sigID == id {
+			sigs = append(sigs, sig)
+		}
+	}
+	return sigs, nil
+}
+
+func (r *HTTPResolver) fetch(kind SigKind, id string) ([]string, error) {
+	var endpoint string
+	switch r.Format {
+	case Format4byte:
+		endpoint = "/api/v1/signatures/"
+		if kind == KindEvent {
+			endpoint = "/api/v1/event-signatures/"
+		}
+		endpoint += "?hex_signature=" + url.QueryEscape(id)
+	default:
+		// openchain keeps errors with functions, as both use 4 byte selectors.
+		param := "function"
+		if kind == KindEvent {
+			param = "event"
+		}
+		endpoint = "/signature-database/v1/lookup?filter=true&" + param + "=" + url.QueryEscape(id)
+	}
+
+	client := r.Client
+	if client == nil {
+		client = &http.Client{Timeout: defaultResolverTimeout}
+	}
+	resp, err := client.Get(r.BaseURL + endpoint)
+	if err != nil {
+		return nil, fmt.Errorf("error querying signature resolver: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("error reading signature resolver response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("signature resolver at %s returned %s", r.BaseURL, resp.Status)
+	}
+
+	if r.Format == Format4byte {
+		return parse4byteResponse(body)
+	}
+	return parseOpenchainResponse(body, kind, id)
+}
+
+func parse4byteResponse(body []byte) ([]string, error) {
+	var resp struct {
+		Results []struct {
+			TextSignature string `json:"text_signature"`
+		} `json:"results"`
+	}
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return nil, fmt.Errorf("error parsing 4byte response: %w", err)
+	}
+	texts := make([]string, len(resp.Results))
+	for idx, result := range resp.Results {
+		texts[idx] = result.TextSignature
+	}
+	return texts, nil
+}
+
+func parseOpenchainResponse(body []byte, kind SigKind, id string) ([]string, error) {
+	var resp struct {
+		Ok     bool   `json:"ok"`
+		Error  string `json:"error"`
+		Result map[string]map[string][]struct {
+			Name string `json:"name"`
+		} `json:"result"`
+	}
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return nil, fmt.Errorf("error parsing openchain response: %w", err)
+	}
+	if !resp.Ok {
+		return nil, fmt.Errorf("signature resolver returned an error: %s", resp.Error)
+	}
+
+	section := "function"
+	if kind == KindEvent {
+		section = "event"
+	}
+	var texts []string
+	for _, result := range resp.Result[section][id] {
+		texts = append(texts, result.Name)
+	}
+	return texts, nil
+}
+
+// Returns the path of the cache file for a lookup. Each base URL gets its own directory,
+// so that a local stand-in server does not share a cache with the public service.
+func (r *HTTPResolver) cachePath(kind SigKind, id string) string {
+	urlHash := fmt.Sprintf("%x", sha256.Sum256([]byte(r.Format+" "+r.BaseURL)))
+	return filepath.Join(r.CacheDir, urlHash[:16], kind.String(), id+".json")
+}
+
+func (r *HTTPResolver) readCache(kind SigKind, id string) ([]string, bool) {
+	if r.CacheDir == "" {
+		return nil, false
+	}
+	b, err := os.ReadFile(r.cachePath(kind, id))
+	if err != nil {
+		return nil, false
+	}
+	var texts []string
+	if json.Unmarshal(b, &texts) != nil {
+		return nil, false
+	}
+	return texts, true
+}
+
+// Caches `texts`. Failing to write the cache does not fail the lookup.
+func (r *HTTPResolver) writeCache(kind SigKind, id string, texts []string) {
+	if r.CacheDir == "" {
+		return
+	}
+	cachePath := r.cachePath(kind, id)
+	b, err := json.Marshal(texts)
+	if err != nil || os.MkdirAll(filepath.Dir(cachePath), 0o755) != nil {
+		return
+	}
+	_ = os.WriteFile(cachePath, b, 0o644)
+}
@@ -0,0 +1,353 @@
+package selector
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/zeuslawyer/hextool/encdec"
+)
+
+const (
+	sweepSelector = "0x62caf2b9" // sweep(address,uint256[]), which is not built-in.
+	sweptTopic    = "0xc36b5179cb9c303b200074996eab2b3473eac370fdd7eba3bec636fe35109696"
+)
+
+// Starts a server that mimics the openchain and 4byte APIs, and counts the requests it serves.
+func newDirectoryServer(t *testing.T, delay time.Duration) (*httptest.Server, *int32) {
+	var requests int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		time.Sleep(delay)
+
+		query := req.URL.Query()
+		switch req.URL.Path {
+		case "/signature-database/v1/lookup":
+			if query.Get("function") == sweepSelector {
+				// "sweep()" does not hash to the selector, so it must be dropped.
+				fmt.Fprintf(w, `{"ok":true,"result":{"event":{},"function":{"%s":[{"name":"sweep(address,uint256[])","filtered":false},{"name":"sweep()","filtered":false}]}}}`, sweepSelector)
+				return
+			}
+			if query.Get("event") == sweptTopic {
+				fmt.Fprintf(w, `{"ok":true,"result":{"function":{},"event":{"%s":[{"name":"Swept(address,uint256)","filtered":false}]}}}`, sweptTopic)
+				return
+			}
+			fmt.Fprint(w, `{"ok":true,"result":{"event":{},"function":{}}}`)
+		case "/api/v1/signatures/":
+			if query.Get("hex_signature") == sweepSelector {
+				fmt.Fprintf(w, `{"count":1,"results":[{"id":1,"text_signature":"sweep(address,uint256[])","hex_signature":"%s"}]}`, sweepSelector)
+				return
+			}
+			fmt.Fprint(w, `{"count":0,"results":[]}`)
+		case "/api/v1/event-signatures/":
+			if query.Get("hex_signature") == sweptTopic {
+				fmt.Fprintf(w, `{"count":1,"results":[{"id":1,"text_signature":"Swept(address,uint256)","hex_signature":"%s"}]}`, sweptTopic)
+				return
+			}
+			fmt.Fprint(w, `{"count":0,"results":[]}`)
+		default:
+			http.Error(w, "internal error", http.StatusInternalServerError)
+		}
+	}))
+	t.Cleanup(server.Close)
+	return server, &requests
+}
+
+func newTestResolver(t *testing.T, baseURL string, format string, timeout time.Duration) *HTTPResolver {
+	resolver, err := NewHTTPResolver(baseURL, format, timeout)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resolver.CacheDir = t.TempDir()
+	return resolver
+}
+
+func TestHTTPResolver(t *testing.T) {
+	server, _ := newDirectoryServer(t, 0)
+
+	tests := []struct {
+		name      string
+		format    string
+		baseURL   string
+		kind      SigKind
+		id        string
+		want      []string
+		wantErrIs error
+		wantErr   string
+	}{
+		{
+			name:   "openchain function, mismatched signatures dropped",
+			format: FormatOpenchain,
+			kind:   KindFunction,
+			id:     sweepSelector,
+			want:   []string{"sweep(address,uint256[])"},
+		},
+		{
+			name:   "openchain function from full calldata",
+			format: FormatOpenchain,
+			kind:   KindFunction,
+			id:     sweepSelector + "000000000000000000000000208aa722aca42399eac5192ee778e4d42f4e5de3",
+			want:   []string{"sweep(address,uint256[])"},
+		},
+		{
+			name:   "openchain error",
+			format: FormatOpenchain,
+			kind:   KindError,
+			id:     "0x" + strings.ToUpper(sweepSelector[2:]),
+			want:   []string{"sweep(address,uint256[])"},
+		},
+		{
+			name:   "openchain event",
+			format: FormatOpenchain,
+			kind:   KindEvent,
+			id:     sweptTopic,
+			want:   []string{"Swept(address,uint256)"},
+		},
+		{
+			name:   "openchain not found",
+			format: FormatOpenchain,
+			kind:   KindFunction,
+			id:     "0xa3063fba",
+		},
+		{
+			name:   "4byte function",
+			format: Format4byte,
+			kind:   KindFunction,
+			id:     sweepSelector,
+			want:   []string{"sweep(address,uint256[])"},
+		},
+		{
+			name:   "4byte event",
+			format: Format4byte,
+			kind:   KindEvent,
+			id:     sweptTopic,
+			want:   []string{"Swept(address,uint256)"},
+		},
+		{
+			name:      "invalid selector",
+			format:    Format4byte,
+			kind:      KindFunction,
+			id:        "0xa905",
+			wantErrIs: encdec.ErrInvalidHex,
+		},
+		{
+			name:    "error status",
+			format:  FormatOpenchain,
+			baseURL: server.URL + "/missing",
+			kind:    KindFunction,
+			id:      sweepSelector,
+			wantErr: "500 Internal Server Error",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			baseURL := tc.baseURL
+			if baseURL == "" {
+				baseURL = server.URL
+			}
+			got, err := newTestResolver(t, baseURL, tc.format, time.Second).Resolve(tc.kind, tc.id)
+			if tc.wantErrIs != nil || tc.wantErr != "" {
+				if tc.wantErrIs != nil && !errors.Is(err, tc.wantErrIs) {
+					t.Errorf("error = %v, want %v", err, tc.wantErrIs)
+				}
+				if err == nil || !strings.Contains(err.Error(), tc.wantErr) {
+					t.Errorf("error = %v, want it to contain %q", err, tc.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if len(got) != len(tc.want) {
+				t.Fatalf("Resolve(%s, %s) = %v, want %v", tc.kind, tc.id, got, tc.want)
+			}
+			for idx, sig := range got {
+				if sig.Text != tc.want[idx] || sig.Kind != tc.kind || sig.Source != server.URL {
+					t.Errorf("Resolve(%s, %s)[%d] = %+v, want %s from %s", tc.kind, tc.id, idx, sig, tc.want[idx], server.URL)
+				}
+			}
+		})
+	}
+}
+
+func TestHTTPResolverCache(t *testing.T) {
+	server, requests := newDirectoryServer(t, 0)
+	resolver := newTestResolver(t, server.URL, FormatOpenchain, time.Second)
+
+	for i := 0; i < 2; i++ {
+		if _, err := resolver.Resolve(KindFunction, sweepSelector); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if got := atomic.LoadInt32(requests); got != 1 {
+		t.Errorf("server got %d requests for the same selector, want 1", got)
+	}
+
+	// lookups that found nothing are not cached, as the signature may be added later.
+	for i := 0; i < 2; i++ {
+		if _, err := resolver.Resolve(KindFunction, "0xa3063fba"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if got := atomic.LoadInt32(requests); got != 3 {
+		t.Errorf("server got %d requests, want 3", got)
+	}
+
+	// another format at the same URL does not share the cache.
+	other := newTestResolver(t, server.URL, Format4byte, time.Second)
+	other.CacheDir = resolver.CacheDir
+	if _, err := other.Resolve(KindFunction, sweepSelector); err != nil {
+		t.Fatal(err)
+	}
+	if got := atomic.LoadInt32(requests); got != 4 {
+		t.Errorf("server got %d requests, want 4", got)
+	}
+}
+
+func TestHTTPResolverTimeout(t *testing.T) {
+	server, _ := newDirectoryServer(t, 200*time.Millisecond)
+	resolver := newTestResolver(t, server.URL, FormatOpenchain, 20*time.Millisecond)
+
+	_, err := resolver.Resolve(KindFunction, sweepSelector)
+	if err == nil || !strings.Contains(err.Error(), "error querying signature resolver") {
+		t.Errorf("error = %v, want a timeout", err)
+	}
+}
+
+func TestNewHTTPResolver(t *testing.T) {
+	tests := []struct {
+		name       string
+		baseURL    string
+		format     string
+		wantURL    string
+		wantFormat string
+		wantErr    string
+	}{
+		{
+			name:       "default format",
+			baseURL:    "http://localhost:8080/",
+			wantURL:    "http://localhost:8080",
+			wantFormat: FormatOpenchain,
+		},
+		{
+			name:       "public endpoint by name",
+			baseURL:    "4byte",
+			format:     FormatOpenchain,
+			wantURL:    "https://www.4byte.directory",
+			wantFormat: Format4byte,
+		},
+		{
+			name:    "unknown format",
+			baseURL: "http://localhost:8080",
+			format:  "etherscan",
+			wantErr: `unknown resolver format "etherscan"`,
+		},
+		{
+			name:    "not a URL",
+			baseURL: "localhost",
+			wantErr: `invalid resolver URL "localhost"`,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			resolver, err := NewHTTPResolver(tc.baseURL, tc.format, 0)
+			if tc.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tc.wantErr) {
+					t.Errorf("error = %v, want it to contain %q", err, tc.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if resolver.BaseURL != tc.wantURL || resolver.Format != tc.wantFormat {
+				t.Errorf("resolver = %s (%s), want %s (%s)", resolver.BaseURL, resolver.Format, tc.wantURL, tc.wantFormat)
+			}
+			if resolver.Client.Timeout != defaultResolverTimeout {
+				t.Errorf("timeout = %v, want %v", resolver.Client.Timeout, defaultResolverTimeout)
+			}
+		})
+	}
+}
+
+func TestResolveSig(t *testing.T) {
+	server, requests := newDirectoryServer(t, 0)
+	resolver := newTestResolver(t, server.URL, FormatOpenchain, time.Second)
+
+	tests := []struct {
+		name         string
+		kind         SigKind
+		id           string
+		resolver     SignatureResolver
+		want         string
+		wantErr      error
+		wantRequests int32
+	}{
+		{
+			name:     "built-in signatures are not resolved",
+			kind:     KindFunction,
+			id:       "0xa9059cbb",
+			resolver: resolver,
+			want:     "transfer(address,uint256)",
+		},
+		{
+			name:         "resolved",
+			kind:         KindFunction,
+			id:           sweepSelector,
+			resolver:     resolver,
+			want:         "sweep(address,uint256[])",
+			wantRequests: 1,
+		},
+		{
+			name:         "resolved event",
+			kind:         KindEvent,
+			id:           sweptTopic,
+			resolver:     resolver,
+			want:         "Swept(address,uint256)",
+			wantRequests: 1,
+		},
+		{
+			name:         "not found anywhere",
+			kind:         KindFunction,
+			id:           "0xa3063fba",
+			resolver:     resolver,
+			wantErr:      ErrSelectorNotFound,
+			wantRequests: 1,
+		},
+		{
+			name:    "no resolver",
+			kind:    KindFunction,
+			id:      sweepSelector,
+			wantErr: ErrSelectorNotFound,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			before := atomic.LoadInt32(requests)
+			got, err := ResolveSig(tc.kind, tc.id, tc.resolver)
+			if requests := atomic.LoadInt32(requests) - before; requests != tc.wantRequests {
+				t.Errorf("resolver got %d requests, want %d", requests, tc.wantRequests)
+			}
+			if tc.wantErr != nil {
+				if !errors.Is(err, tc.wantErr) {
+					t.Errorf("error = %v, want %v", err, tc.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got != tc.want {
+				t.Errorf("got %s, want %s", got, tc.want)
+			}
+		})
+	}
+}
@@ -2,11 +2,6 @@ package selector
 
 import (
 	"fmt"
-	"io"
-	"net/http"
-	"os"
-	"path/filepath"
-	"regexp"
 	"strings"
 
 	"encoding/json"
@@ -15,6 +10,7 @@ import (
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/common/hexutil"
 	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/zeuslawyer/hextool/encdec"
 )
 
 // Calculates the function selector given function signature `funcSig`.
@@ -25,233 +21,232 @@ func SelectorFromSig(funcSig string) string {
 		fmt.Println("Error: function signature cannot be empty. Pass in the '--sig' flag with the function signature.")
 		return ""
 	}
-	funcSig = strings.ReplaceAll(funcSig, " ", "")
-	validateInput := func(sig string) error {
-		re := regexp.MustCompile(`^(\w+)`) // match the first word in a given string
-		matches := re.FindStringSubmatch(sig)
 
-		if len(matches) < 2 {
-			return fmt.Errorf("unable to extract function name from signature: %s", sig)
-		}
+	selector, err := FromSig(funcSig)
+	if err != nil {
+		panic(err)
+	}
+	return selector
+}
 
-		// validate signature format
-		signatureRegex := regexp.MustCompile(`^\w+\([^\)]*\)$`)
-		if !signatureRegex.MatchString(sig) {
-			return fmt.Errorf("\n%q is not a valid function signature", sig)
-		}
+// Same as SelectorFromSig, but returns an error wrapping ErrInvalidSignature
+// instead of panicking when `funcSig` is empty or malformed. `funcSig` may also be a
+// Solidity declaration, eg: "function transfer(address to, uint amount) external returns (bool)",
+// which is canonicalized first, see ParseSig.
+func FromSig(funcSig string) (string, error) {
+	funcSigHash, err := sigHash(funcSig, KindFunction)
+	if err != nil {
+		return "", err
+	}
+	return funcSigHash.String()[:10], nil // first 4 bytes ==8 characters, plus "0x"
+}
 
-		return nil
+// Calculates the 32 byte topic hash, or topic0, of the event signature `eventSig`, eg:
+// "Transfer(address,address,uint256)" or "event Transfer(address indexed from, address indexed to, uint value)".
+// The error wraps ErrInvalidSignature when `eventSig` is empty or malformed.
+func TopicFromSig(eventSig string) (string, error) {
+	eventSigHash, err := sigHash(eventSig, KindEvent)
+	if err != nil {
+		return "", err
 	}
+	return eventSigHash.Hex(), nil
+}
 
-	if err := validateInput(funcSig); err != nil {
-		panic(err)
+// Calculates the 4 byte selector of the custom error signature `errorSig`, eg:
+// "InsufficientBalance(uint256,uint256)" or "error InsufficientBalance(uint available, uint required)".
+// The error wraps ErrInvalidSignature when `errorSig` is empty or malformed.
+func ErrorSelectorFromSig(errorSig string) (string, error) {
+	errorSigHash, err := sigHash(errorSig, KindError)
+	if err != nil {
+		return "", err
 	}
+	return errorSigHash.String()[:10], nil
+}
 
-	funcSigHash := crypto.Keccak256Hash([]byte(funcSig))
-	selector := funcSigHash.String()[:10] // first 4 bytes ==8 characters, plus "0x"
-	return selector
+// Canonicalizes `sig`, a signature of `kind`, and returns its keccak256 hash. Tuple
+// parameters are expanded, eg: "(address,uint256)[]", as the ABI spec hashes them.
+func sigHash(sig string, kind SigKind) (common.Hash, error) {
+	if strings.TrimSpace(sig) == "" {
+		return common.Hash{}, fmt.Errorf("%w: %s signature cannot be empty", ErrInvalidSignature, kind)
+	}
+	decl, err := parseSigOfKind(sig, kind)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	return crypto.Keccak256Hash([]byte(decl.Signature())), nil
 }
 
 // Given a function selector, returns the function signature from provided ABI file and path
 // or from a URL.  If both are provided it will default to using the file path.
+// If neither is provided, the signature is looked up in the built-in signature database and
+// the user's signature registry (see Registry), and every matching signature is returned, one per line, when the selector collides.
 func SigFromSelector(selector string, _abiPath string, abiUrl string) string {
+	sig, err := MethodSig(selector, _abiPath, abiUrl)
+	if err != nil {
+		panic(err)
+	}
+	return sig
+}
+
+// Same as SigFromSelector, but returns an error instead of panicking.
+// The error wraps ErrSelectorNotFound when the ABI has no method with the given selector.
+func MethodSig(selector string, _abiPath string, abiUrl string) (string, error) {
 	return fromSelector(false, selector, _abiPath, abiUrl)
 }
 
 func ErrorSigFromSelector(selector string, _abiPath string, abiUrl string) string {
+	sig, err := ErrorSig(selector, _abiPath, abiUrl)
+	if err != nil {
+		panic(err)
+	}
+	return sig
+}
+
+// Same as ErrorSigFromSelector, but returns an error instead of panicking.
+// The error wraps ErrSelectorNotFound when the ABI has no custom error with the given selector.
+func ErrorSig(selector string, _abiPath string, abiUrl string) (string, error) {
 	return fromSelector(true, selector, _abiPath, abiUrl)
 }
 
 // Given an Events Topic Hash (32 bytes), returns the event's signature from provided ABI file and path
 // or from a URL.  If both are provided it will default to using the file path.
+// If neither is provided, the signature is looked up in the built-in signature database and
+// the user's signature registry.
 func EventFromTopicHash(topicHex string, _abiPath string, abiUrl string) string {
-	if _abiPath == "" && abiUrl == "" {
-		panic(fmt.Errorf("abiPath and url cannot both be empty"))
+	sig, err := EventSig(topicHex, _abiPath, abiUrl)
+	if err != nil {
+		panic(err)
 	}
+	return sig
+}
 
-	var abiJsonStr string
-	var abiPath string
-	if _abiPath != "" {
-		err := validateUriExtension(_abiPath)
-		if err != nil {
-			panic(err)
-		}
-
-		abiPath = _abiPath
-		fileBytes, err := os.ReadFile(abiPath)
-		if err != nil {
-			fmt.Println("Error reading file")
-			panic(err)
-		}
-
-		abiJsonStr = bytesToJsonString(fileBytes, abiPath)
-
-	} else { // reading from URL instead of file
-		err := validateUriExtension(abiUrl)
-		if err != nil {
-			panic(err)
-		}
-
-		abiPath = abiUrl
-		resp, err := http.Get(abiPath)
-		if err != nil {
-			fmt.Printf("Error fetching ABI file from url %s", abiPath)
-			panic(err)
-		}
-		defer resp.Body.Close()
-
-		respBytes, err := io.ReadAll(resp.Body)
-		if err != nil {
-			fmt.Println("Error reading file from http response")
-			panic(err)
-		}
-
-		abiJsonStr = bytesToJsonString(respBytes, abiPath)
+// Same as EventFromTopicHash, but returns an error instead of panicking.
+// The error wraps ErrSelectorNotFound when the ABI has no event with the given topic hash.
+func EventSig(topicHex string, _abiPath string, abiUrl string) (string, error) {
+	if _abiPath == "" && abiUrl == "" {
+		return ResolveSig(KindEvent, topicHex, nil)
+	}
+	if isDir(_abiPath) {
+		return dirSig(KindEvent, topicHex, _abiPath)
 	}
 
-	topicBytes := hexutil.MustDecode(topicHex)
-	topicHash := common.BytesToHash(topicBytes)
+	parsedAbi, err := LoadABI(NewAbiSource(_abiPath, abiUrl))
+	if err != nil {
+		return "", err
+	}
 
-	parsedAbi, err := abi.JSON(strings.NewReader(abiJsonStr))
-	if err != nil { // @zeuslawyer TODO check if this is the correct way to check for this error
-		fmt.Printf("Error parsing ABI from : %s. \nABI provided must be an array.\n", abiPath)
-		panic(err)
+	topicBytes, err := hexutil.Decode(topicHex)
+	if err != nil {
+		return "", fmt.Errorf("%w: topic hash %q: %v", encdec.ErrInvalidHex, topicHex, err)
 	}
+	topicHash := common.BytesToHash(topicBytes)
 
 	ev, err := parsedAbi.EventByID(topicHash)
 	if err != nil {
-		fmt.Println("Error looking up event by its topics hash")
-		panic(err)
-	}
-	if ev == nil {
-		return fmt.Sprintf("Method not found in file at %s", _abiPath)
+		return "", fmt.Errorf("%w: %v", ErrSelectorNotFound, err)
 	}
 
-	return ev.Sig
+	return ev.Sig, nil
 }
 
-func validateUriExtension(uri string) error {
-	ext := filepath.Ext(uri)
-	if ext != ".json" {
-		return fmt.Errorf("invalid file/url extension: %s, must be .json", ext)
+// Returns the ABI array held by `b` as a JSON string. See findAbis for the supported
+// artifact formats. The ABIs of documents with several contracts are merged.
+func bytesToJsonString(b []byte, abiSourceUri string) (string, error) {
+	abis, err := unmarshalAbis(b, abiSourceUri)
+	if err != nil {
+		return "", err
+	}
+
+	abiData, err := mergeAbiArrays(abis)
+	if err != nil {
+		return "", fmt.Errorf("%w: marshalling ABI data to JSON bytes: %s", ErrInvalidAbi, err)
+	}
+	jsonBytes, err := json.Marshal(abiData)
+	if err != nil {
+		return "", fmt.Errorf("%w: marshalling ABI data to JSON bytes: %s", ErrInvalidAbi, err)
 	}
-	return nil
+
+	return string(jsonBytes), nil
 }
 
-func bytesToJsonString(b []byte, abiSourceUri string) string {
+func unmarshalAbis(b []byte, abiSourceUri string) ([]contractAbi, error) {
 	var data any
-
 	if err := json.Unmarshal(b, &data); err != nil {
-		panic(fmt.Errorf("error parsing JSON from file at %s : %s", abiSourceUri, err))
+		return nil, fmt.Errorf("%w: error parsing JSON from file at %s : %s", ErrInvalidAbi, abiSourceUri, err)
 	}
+	return findAbis(data, abiSourceUri)
+}
 
-	// Check if the data is a slice (array) or a map (object)
-	var abiData any
-	switch v := data.(type) {
-	case []any:
-		fmt.Println("Data is an array")
-		// You can work with v as a []interface{}
-		abiData = v
-	case map[string]interface{}:
-		fmt.Println("Data is an object")
-		d, ok := v["abi"]
-		if !ok {
-			panic(fmt.Errorf("Property 'abi' not found in unmarshalled JSON data. Check the file at %s", abiSourceUri))
-		}
-
-		// check that the "abi" property is an array
-		_, ok = d.([]any)
-		if !ok {
-			fmt.Printf("Value of property 'abi' in supplied file is not an array")
-		}
-		abiData = d
-	default:
-		panic(fmt.Errorf("Data in file at %s is neither an array nor an object", abiSourceUri))
+// Given a function selector, returns the abi.Method it identifies in the provided ABI file and path
+// or URL.  If both are provided it will default to using the file path.
+func MethodFromSelector(selector string, _abiPath string, abiUrl string) (*abi.Method, error) {
+	parsedAbi, err := LoadABI(NewAbiSource(_abiPath, abiUrl))
+	if err != nil {
+		return nil, err
 	}
 
-	jsonBytes, err := json.Marshal(abiData)
+	selectorBytes, err := selectorToBytes(selector)
 	if err != nil {
-		fmt.Printf("Error marshalling ABI data to JSON bytes: %s", err)
-		return ""
+		return nil, err
 	}
 
-	return string(jsonBytes)
+	method, err := parsedAbi.MethodById(selectorBytes[:])
+	if err != nil {
+		return nil, fmt.Errorf("%w: Error looking up method signature by its selector: %s", ErrSelectorNotFound, err)
+	}
+	return method, nil
 }
 
-func fromSelector(isErrorSelector bool, selector string, _abiPath string, abiUrl string) string {
+func fromSelector(isErrorSelector bool, selector string, _abiPath string, abiUrl string) (string, error) {
+	kind := KindFunction
+	if isErrorSelector {
+		kind = KindError
+	}
 	if _abiPath == "" && abiUrl == "" {
-		panic(fmt.Errorf("abiPath and url cannot both be empty"))
+		return ResolveSig(kind, selector, nil)
+	}
+	if isDir(_abiPath) {
+		return dirSig(kind, selector, _abiPath)
 	}
-	var abiJsonStr string
-	var abiPath string
-
-	if _abiPath != "" {
-		err := validateUriExtension(_abiPath)
-		if err != nil {
-			panic(err)
-		}
-
-		abiPath = _abiPath
-		fileBytes, err := os.ReadFile(abiPath)
-		if err != nil {
-			fmt.Println("Error reading file")
-			panic(err)
-		}
-
-		abiJsonStr = bytesToJsonString(fileBytes, abiPath)
-	} else { // reading from URL instead of file
-		err := validateUriExtension(abiUrl)
-		if err != nil {
-			panic(err)
-		}
 
-		abiPath = abiUrl
-		resp, err := http.Get(abiPath)
+	if !isErrorSelector {
+		method, err := MethodFromSelector(selector, _abiPath, abiUrl)
 		if err != nil {
-			fmt.Printf("Error fetching ABI file from url %s", abiPath)
-			panic(err)
+			return "", err
 		}
-		defer resp.Body.Close()
+		return method.Sig, nil
+	}
 
-		b, err := io.ReadAll(resp.Body)
-		if err != nil {
-			fmt.Println("Error reading file from http response")
-			panic(err)
-		}
+	parsedAbi, err := LoadABI(NewAbiSource(_abiPath, abiUrl))
+	if err != nil {
+		return "", err
+	}
 
-		abiJsonStr = bytesToJsonString(b, abiPath)
+	selectorBytes, err := selectorToBytes(selector)
+	if err != nil {
+		return "", err
 	}
 
-	parsedAbi, err := abi.JSON(strings.NewReader(abiJsonStr))
-	if err != nil { // @zeuslawyer TODO check if this is the correct way to check for this error
-		fmt.Printf("Error parsing ABI from : %s. \nABI provided must be an array.\n", abiPath)
-		panic(err)
+	errorSig, err := parsedAbi.ErrorByID(selectorBytes)
+	if err != nil {
+		return "", fmt.Errorf("%w: Error looking up error signature by its selector: %s", ErrSelectorNotFound, err)
 	}
+	return errorSig.Sig, nil
+}
 
-	selectorBytes := hexutil.MustDecode(selector)
+// Decodes the first 4 bytes of the hex string `selector`. Any bytes after the
+// first 4, eg: the arguments in a full calldata string, are ignored.
+func selectorToBytes(selector string) ([4]byte, error) {
+	var first4Bytes [4]byte
 
-	var sig string
-	if isErrorSelector {
-		var first4Bytes [4]byte
-		copy(first4Bytes[:], selectorBytes[:4])
-		errorSig, e := parsedAbi.ErrorByID(first4Bytes)
-		if e != nil {
-			panic(fmt.Errorf("Error looking up error signature by its selector: %s", e))
-		}
-		if errorSig == nil {
-			panic(fmt.Errorf("Error signature for selector %s not found in file at %s", selector, _abiPath))
-		}
-		sig = errorSig.Sig
-	} else {
-		methodSig, e := parsedAbi.MethodById(selectorBytes)
-		if e != nil {
-			panic(fmt.Errorf("Error looking up method signature by its selector: %s", e))
-		}
-		if methodSig == nil {
-			panic(fmt.Errorf("Method signature for selector %s not found in file at %s", selector, _abiPath))
-		}
-		sig = methodSig.Sig
+	selectorBytes, err := hexutil.Decode(selector)
+	if err != nil {
+		return first4Bytes, fmt.Errorf("%w: selector %q: %v", encdec.ErrInvalidHex, selector, err)
+	}
+	if len(selectorBytes) < 4 {
+		return first4Bytes, fmt.Errorf("%w: selector %q must be at least 4 bytes", encdec.ErrInvalidHex, selector)
 	}
 
-	return sig
+	copy(first4Bytes[:], selectorBytes[:4])
+	return first4Bytes, nil
 }
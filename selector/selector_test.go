@@ -1,17 +1,22 @@
 package selector
 
 import (
+	"errors"
 	"fmt"
+	"net/http"
+	"net/http/httptest"
 	"path"
 	"strings"
 	"testing"
+
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/zeuslawyer/hextool/encdec"
 )
 
 const (
 	// "Raw" Github Gists
 	goodJsonUrl_arrayAbi = "https://gist.githubusercontent.com/zeuslawyer/ecec03ff3f50311e510c201de4c076d5/raw/54b14fbfb686e5605e79a4a950031ecaff279d4a/bad-data-erc20.json"
 	goodJsonUrl          = "https://gist.githubusercontent.com/zeuslawyer/ecec03ff3f50311e510c201de4c076d5/raw/f096531942e922cb3f1d5daa2132f0e476356ced/good-data-erc20.json"
-	badUrlExt            = "https://gist.githubusercontent.com/zeuslawyer/ecec03ff3f50311e510c201de4c076d5/raw/54b14fbfb686e5605e79a4a950031ecaff279d4a/bad-data-erc20.NotJson"
 )
 
 func TestSelectorFromSig(t *testing.T) {
@@ -31,6 +36,22 @@ func TestSelectorFromSig(t *testing.T) {
 			functionSig: "transfer(address,uint256)",
 			want:        "0xa9059cbb", // https://www.evm-function-selector.click/
 		},
+		{
+			name:        "tuple parameter",
+			functionSig: "exactInput((bytes,address,uint256,uint256,uint256))",
+			want:        "0xc04b8d59",
+		},
+		{
+			name:        "solidity declaration",
+			functionSig: "function transfer(address to, uint amount) external returns (bool)",
+			want:        "0xa9059cbb",
+		},
+		{
+			name:        "unbalanced parentheses",
+			functionSig: "foo(()",
+			panics:      true,
+			want:        "not a valid function signature",
+		},
 		{
 			name:        "bad function",
 			functionSig: "gibberish",
@@ -68,7 +89,98 @@ func TestSelectorFromSig(t *testing.T) {
 	}
 }
 
+func TestTopicAndErrorSelectorFromSig(t *testing.T) {
+	tests := []struct {
+		name    string
+		hash    func(string) (string, error)
+		sig     string
+		want    string
+		wantErr string
+	}{
+		{
+			name: "topic",
+			hash: TopicFromSig,
+			sig:  "Transfer(address,address,uint256)",
+			want: "0xddf252ad1be2c89b69c2b068fc378daa952ba7f163c4a11628f55a4df523b3ef",
+		},
+		{
+			name: "topic of a declaration",
+			hash: TopicFromSig,
+			sig:  "event Transfer(address indexed from, address indexed to, uint value)",
+			want: "0xddf252ad1be2c89b69c2b068fc378daa952ba7f163c4a11628f55a4df523b3ef",
+		},
+		{
+			name: "topic with tuple parameters",
+			hash: TopicFromSig,
+			sig:  "event Swapped(tuple(address pool, uint fee)[] legs, address indexed to)",
+			want: crypto.Keccak256Hash([]byte("Swapped((address,uint256)[],address)")).Hex(),
+		},
+		{
+			name: "topic with a struct parameter",
+			hash: TopicFromSig,
+			sig:  "struct Leg { address pool; uint fee; }; event Swapped(Leg[] legs, address indexed to)",
+			want: crypto.Keccak256Hash([]byte("Swapped((address,uint256)[],address)")).Hex(),
+		},
+		{
+			name: "error selector",
+			hash: ErrorSelectorFromSig,
+			sig:  "VaultLocked(uint64)",
+			want: "0x07711d3e",
+		},
+		{
+			name: "error selector of a declaration",
+			hash: ErrorSelectorFromSig,
+			sig:  "error VaultLocked(uint64 until)",
+			want: "0x07711d3e",
+		},
+		{
+			name:    "topic of a function",
+			hash:    TopicFromSig,
+			sig:     "function transfer(address to, uint amount)",
+			wantErr: "declares a function, not an event",
+		},
+		{
+			name:    "error selector of an event",
+			hash:    ErrorSelectorFromSig,
+			sig:     "event Transfer(address,address,uint256)",
+			wantErr: "declares an event, not an error",
+		},
+		{
+			name:    "empty",
+			hash:    TopicFromSig,
+			wantErr: "event signature cannot be empty",
+		},
+		{
+			name:    "malformed",
+			hash:    ErrorSelectorFromSig,
+			sig:     "VaultLocked(uint64",
+			wantErr: "is not a valid error signature",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := tc.hash(tc.sig)
+			if tc.wantErr != "" {
+				if !errors.Is(err, ErrInvalidSignature) || !strings.Contains(err.Error(), tc.wantErr) {
+					t.Errorf("error = %v, want %v containing %q", err, ErrInvalidSignature, tc.wantErr)
+				}
+				return
+			}
+			if err != nil || got != tc.want {
+				t.Errorf("hash(%q) = %s, %v, want %s", tc.sig, got, err, tc.want)
+			}
+		})
+	}
+}
+
 func TestFuncFromSelector(t *testing.T) {
+	// serves the ABI from an API-style endpoint, whose URL does not end in ".json".
+	apiServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		http.ServeFile(w, req, path.Join("testdata", "erc20.abi.json"))
+	}))
+	defer apiServer.Close()
+
 	tests := []struct {
 		name     string
 		selector string
@@ -119,16 +231,21 @@ func TestFuncFromSelector(t *testing.T) {
 		{
 			name:     "file - not .json extension",
 			selector: "0xa9059cbb",
-			path:     path.Join("testdata", "erc20.abi.NotJson"),
+			path:     path.Join("testdata", "erc20.abi.txt"),
+			want:     "transfer(address,uint256)",
+		},
+		{
+			name:     "file - not JSON content",
+			selector: "0xa9059cbb",
+			path:     path.Join("testdata", "not-json.html"),
 			panics:   true,
-			want:     "invalid file/url extension",
+			want:     "does not contain JSON",
 		},
 		{
 			name:     "URL - not .json file extension",
 			selector: "0xa9059cbb",
-			url:      badUrlExt,
-			panics:   true,
-			want:     "invalid file/url extension",
+			url:      apiServer.URL + "/api?module=contract&action=getabi",
+			want:     "transfer(address,uint256)",
 		},
 		{
 			name:     "URL - HTTP GET error",
@@ -152,9 +269,20 @@ func TestFuncFromSelector(t *testing.T) {
 			want:     "no such file or directory",
 		},
 		{
-			name:   "empty path, empty url",
-			panics: true,
-			want:   "abiPath and url cannot both be empty",
+			name:     "empty path, empty url - built-in signatures",
+			selector: "0xa9059cbb",
+			want:     "transfer(address,uint256)",
+		},
+		{
+			name:     "empty path, empty url - colliding built-in signatures",
+			selector: "0x42966c68",
+			want:     "burn(uint256)\ncollate_propagate_storage(bytes16)",
+		},
+		{
+			name:     "empty path, empty url - not a built-in signature",
+			selector: "0xa3063fba",
+			panics:   true,
+			want:     "no built-in or registered function signature matches 0xa3063fba",
 		},
 	}
 
@@ -296,3 +424,115 @@ func TestEventFromTopicHash(t *testing.T) {
 		})
 	}
 }
+
+func TestSelectorErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		lookup  func() (string, error)
+		want    string
+		wantErr error
+	}{
+		{
+			name:   "FromSig",
+			lookup: func() (string, error) { return FromSig("transfer(address,uint256)") },
+			want:   "0xa9059cbb",
+		},
+		{
+			name:    "FromSig - bad function",
+			lookup:  func() (string, error) { return FromSig("gibberish") },
+			wantErr: ErrInvalidSignature,
+		},
+		{
+			name:    "FromSig - empty",
+			lookup:  func() (string, error) { return FromSig("") },
+			wantErr: ErrInvalidSignature,
+		},
+		{
+			name: "MethodSig",
+			lookup: func() (string, error) {
+				return MethodSig("0xa9059cbb", path.Join("testdata", "erc20.abi.json"), "")
+			},
+			want: "transfer(address,uint256)",
+		},
+		{
+			name: "MethodSig - non existent selector",
+			lookup: func() (string, error) {
+				return MethodSig("0xa3063fba", path.Join("testdata", "erc20.abi.json"), "")
+			},
+			wantErr: ErrSelectorNotFound,
+		},
+		{
+			name: "MethodSig - invalid hex selector",
+			lookup: func() (string, error) {
+				return MethodSig("0xhextool", path.Join("testdata", "erc20.abi.json"), "")
+			},
+			wantErr: encdec.ErrInvalidHex,
+		},
+		{
+			name:    "MethodSig - not a built-in signature",
+			lookup:  func() (string, error) { return MethodSig("0xa3063fba", "", "") },
+			wantErr: ErrSelectorNotFound,
+		},
+		{
+			name:    "MethodSig - built-in signature, invalid hex selector",
+			lookup:  func() (string, error) { return MethodSig("0xa905", "", "") },
+			wantErr: encdec.ErrInvalidHex,
+		},
+		{
+			name:   "ErrorSig - built-in signature",
+			lookup: func() (string, error) { return ErrorSig("0xe450d38c", "", "") },
+			want:   "ERC20InsufficientBalance(address,uint256,uint256)",
+		},
+		{
+			name:    "ErrorSig - function selectors are not built-in errors",
+			lookup:  func() (string, error) { return ErrorSig("0xa9059cbb", "", "") },
+			wantErr: ErrSelectorNotFound,
+		},
+		{
+			name: "EventSig - built-in signature",
+			lookup: func() (string, error) {
+				return EventSig("0xddf252ad1be2c89b69c2b068fc378daa952ba7f163c4a11628f55a4df523b3ef", "", "")
+			},
+			want: "Transfer(address,address,uint256)",
+		},
+		{
+			name: "MethodSig - object missing abi property",
+			lookup: func() (string, error) {
+				return MethodSig("0xa9059cbb", path.Join("testdata", "bad-abi.json"), "")
+			},
+			wantErr: ErrInvalidAbi,
+		},
+		{
+			name: "ErrorSig - not in abi",
+			lookup: func() (string, error) {
+				return ErrorSig("0x1841b4e1", path.Join("testdata", "errors.abi.json"), "")
+			},
+			wantErr: ErrSelectorNotFound,
+		},
+		{
+			name: "EventSig - not in abi",
+			lookup: func() (string, error) {
+				return EventSig("0x0000000000000000000000000000000000000000000000000000000000000001", path.Join("testdata", "erc20.abi.json"), "")
+			},
+			wantErr: ErrSelectorNotFound,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := tc.lookup()
+			if tc.wantErr != nil {
+				if !errors.Is(err, tc.wantErr) {
+					t.Errorf("error = %v, want %v", err, tc.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got != tc.want {
+				t.Errorf("got %s, want %s", got, tc.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,293 @@
+package selector
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+)
+
+// An AbiSource provides one or more JSON documents, each holding an ABI or a
+// compiled artifact with an ABI in it.
+type AbiSource interface {
+	// Documents reads the source and returns its documents.
+	Documents() ([]AbiDocument, error)
+	// String describes the source for error messages, eg: the file path or URL.
+	String() string
+}
+
+// A JSON document read from an AbiSource.
+type AbiDocument struct {
+	Name string // where the document was read from, eg: a file path.
+	Data []byte
+}
+
+// Reads a single file.
+type FileSource struct {
+	Path string
+}
+
+func (s FileSource) Documents() ([]AbiDocument, error) {
+	b, err := os.ReadFile(s.Path)
+	if err != nil {
+		return nil, fmt.Errorf("error reading ABI file: %w", err)
+	}
+	return []AbiDocument{{Name: s.Path, Data: b}}, nil
+}
+
+func (s FileSource) String() string { return s.Path }
+
+// Fetches a URL with a GET request.
+type HTTPSource struct {
+	URL    string
+	Client *http.Client // defaults to a client that times out after 30 seconds.
+}
+
+func (s HTTPSource) Documents() ([]AbiDocument, error) {
+	client := s.Client
+	if client == nil {
+		client = &http.Client{Timeout: 30 * time.Second}
+	}
+	resp, err := client.Get(s.URL)
+	if err != nil {
+		return nil, fmt.Errorf("error fetching ABI file from url: %w", err)
+	}
+	defer resp.Body.Close()
+
+	respBytes, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("error reading ABI file from http response: %w", err)
+	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return nil, fmt.Errorf("error fetching ABI file from url %s: %s", s.URL, resp.Status)
+	}
+	// Servers often send JSON as text/plain, so only content types that are
+	// certainly not JSON are rejected here. The body is sniffed by LoadABI.
+	if mediaType, _, err := mime.ParseMediaType(resp.Header.Get("Content-Type")); err == nil &&
+		(mediaType == "text/html" || strings.HasPrefix(mediaType, "image/")) {
+		return nil, fmt.Errorf("%w: %s returned %s content, not JSON", ErrInvalidAbi, s.URL, mediaType)
+	}
+	return []AbiDocument{{Name: s.URL, Data: respBytes}}, nil
+}
+
+func (s HTTPSource) String() string { return s.URL }
+
+// Reads everything from a reader, usually os.Stdin.
+type StdinSource struct {
+	Reader io.Reader
+}
+
+func (s StdinSource) Documents() ([]AbiDocument, error) {
+	b, err := io.ReadAll(s.Reader)
+	if err != nil {
+		return nil, fmt.Errorf("error reading ABI from stdin: %w", err)
+	}
+	return []AbiDocument{{Name: "stdin", Data: b}}, nil
+}
+
+func (s StdinSource) String() string { return "stdin" }
+
+// Reads every file matching a glob pattern, see ExpandGlob.
+type GlobSource struct {
+	Pattern string
+}
+
+func (s GlobSource) Documents() ([]AbiDocument, error) {
+	paths, err := ExpandGlob(s.Pattern)
+	if err != nil {
+		return nil, err
+	}
+	if len(paths) == 0 {
+		return nil, fmt.Errorf("%w: no files match %s", ErrNoAbiSource, s.Pattern)
+	}
+
+	docs := make([]AbiDocument, len(paths))
+	for idx, filePath := range paths {
+		fileDocs, err := FileSource{Path: filePath}.Documents()
+		if err != nil {
+			return nil, err
+		}
+		docs[idx] = fileDocs[0]
+	}
+	return docs, nil
+}
+
+func (s GlobSource) String() string { return s.Pattern }
+
+// Holds the JSON itself, eg: an ABI passed on the command line.
+type InlineSource struct {
+	JSON string
+}
+
+func (s InlineSource) Documents() ([]AbiDocument, error) {
+	return []AbiDocument{{Name: s.String(), Data: []byte(s.JSON)}}, nil
+}
+
+func (s InlineSource) String() string { return "inline JSON" }
+
+// Returns the source for the --path and --url flags of the CLI. If both are given the
+// path is used, and if neither is, nil is returned. The path may be:
+//   - "-", to read from stdin.
+//   - the JSON itself, when it starts with '[' or '{'.
+//   - a glob pattern, when it contains '*', '?' or '['. See ExpandGlob.
+//   - a directory, which is searched recursively. See IndexDir.
+//   - otherwise, the path of a file.
+func NewAbiSource(abiPath string, abiUrl string) AbiSource {
+	trimmed := strings.TrimSpace(abiPath)
+	switch {
+	case abiPath == "" && abiUrl == "":
+		return nil
+	case abiPath == "":
+		return HTTPSource{URL: abiUrl}
+	case abiPath == "-":
+		return StdinSource{Reader: os.Stdin}
+	case strings.HasPrefix(trimmed, "[") || strings.HasPrefix(trimmed, "{"):
+		return InlineSource{JSON: abiPath}
+	case strings.ContainsAny(abiPath, "*?["):
+		return GlobSource{Pattern: abiPath}
+	case isDir(abiPath):
+		return DirSource{Dir: abiPath}
+	default:
+		return FileSource{Path: abiPath}
+	}
+}
+
+// Reads and parses the ABI from `src`. Each document must contain either an ABI array or
+// an object with an `abi` property. The ABIs of several documents are merged into one.
+// The documents of a GlobSource that hold no ABI are skipped, so that eg: "out/**/*.json"
+// can match Foundry's build-info files next to the artifacts, and only when none of them
+// holds an ABI is it an error. The error wraps ErrNoAbiSource when `src` is nil.
+func LoadABI(src AbiSource) (*abi.ABI, error) {
+	if src == nil {
+		return nil, fmt.Errorf("%w: abiPath and url cannot both be empty", ErrNoAbiSource)
+	}
+
+	docs, err := src.Documents()
+	if err != nil {
+		return nil, err
+	}
+	_, fromGlob := src.(GlobSource)
+
+	var merged *abi.ABI
+	skipped := 0
+	for _, doc := range docs {
+		parsedAbi, err := parseAbiDocument(doc)
+		if fromGlob && errors.Is(err, ErrInvalidAbi) {
+			skipped++
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		if merged == nil {
+			merged = parsedAbi
+		} else {
+			mergeABI(merged, parsedAbi)
+		}
+	}
+	if merged == nil && fromGlob {
+		return nil, fmt.Errorf("%w: no file matching %s holds an ABI, %d files were skipped", ErrInvalidAbi, src, skipped)
+	}
+	if merged == nil {
+		return nil, fmt.Errorf("%w: %s holds no ABI", ErrInvalidAbi, src)
+	}
+	return merged, nil
+}
+
+func parseAbiDocument(doc AbiDocument) (*abi.ABI, error) {
+	if err := sniffJSON(doc); err != nil {
+		return nil, err
+	}
+
+	abiJsonStr, err := bytesToJsonString(doc.Data, doc.Name)
+	if err != nil {
+		return nil, err
+	}
+
+	parsedAbi, err := abi.JSON(strings.NewReader(abiJsonStr))
+	if err != nil {
+		return nil, fmt.Errorf("%w: parsing ABI from %s: %v", ErrInvalidAbi, doc.Name, err)
+	}
+	return &parsedAbi, nil
+}
+
+// Checks that the document looks like a JSON array or object, whatever its file extension
+// or URL, so that eg: an HTML error page gets a clearer error than a JSON syntax error.
+func sniffJSON(doc AbiDocument) error {
+	data := bytes.TrimLeft(bytes.TrimPrefix(doc.Data, []byte("\xef\xbb\xbf")), " \t\r\n")
+	if len(data) == 0 {
+		return fmt.Errorf("%w: %s is empty", ErrInvalidAbi, doc.Name)
+	}
+	if data[0] != '[' && data[0] != '{' {
+		start := data
+		if len(start) > 20 {
+			start = start[:20]
+		}
+		return fmt.Errorf("%w: %s does not contain JSON (it starts with %q, detected as %s)",
+			ErrInvalidAbi, doc.Name, start, http.DetectContentType(data))
+	}
+	return nil
+}
+
+// Adds the functions, events and errors of `src` to `dst`. Entries with a signature
+// `dst` already has are skipped, and other entries whose name is taken get a numeric
+// suffix, as go-ethereum does for overloaded functions.
+func mergeABI(dst *abi.ABI, src *abi.ABI) {
+	methodSigs := map[string]bool{}
+	for _, method := range dst.Methods {
+		methodSigs[method.Sig] = true
+	}
+	for name, method := range src.Methods {
+		if !methodSigs[method.Sig] {
+			dst.Methods[uniqueName(name, func(n string) bool { _, ok := dst.Methods[n]; return ok })] = method
+			methodSigs[method.Sig] = true
+		}
+	}
+
+	eventSigs := map[string]bool{}
+	for _, ev := range dst.Events {
+		eventSigs[ev.Sig] = true
+	}
+	for name, ev := range src.Events {
+		if !eventSigs[ev.Sig] {
+			dst.Events[uniqueName(name, func(n string) bool { _, ok := dst.Events[n]; return ok })] = ev
+			eventSigs[ev.Sig] = true
+		}
+	}
+
+	errorSigs := map[string]bool{}
+	for _, abiErr := range dst.Errors {
+		errorSigs[abiErr.Sig] = true
+	}
+	for name, abiErr := range src.Errors {
+		if !errorSigs[abiErr.Sig] {
+			dst.Errors[uniqueName(name, func(n string) bool { _, ok := dst.Errors[n]; return ok })] = abiErr
+			errorSigs[abiErr.Sig] = true
+		}
+	}
+
+	if dst.Constructor.Type == abi.Constructor && len(dst.Constructor.Inputs) == 0 {
+		dst.Constructor = src.Constructor
+	}
+	if dst.Fallback.Type != abi.Fallback {
+		dst.Fallback = src.Fallback
+	}
+	if dst.Receive.Type != abi.Receive {
+		dst.Receive = src.Receive
+	}
+}
+
+func uniqueName(name string, taken func(string) bool) string {
+	unique := name
+	for idx := 0; taken(unique); idx++ {
+		unique = fmt.Sprintf("%s%d", name, idx)
+	}
+	return unique
+}
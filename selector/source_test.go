@@ -0,0 +1,190 @@
+package selector
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestNewAbiSource(t *testing.T) {
+	tests := []struct {
+		name string
+		path string
+		url  string
+		want AbiSource
+	}{
+		{name: "neither", want: nil},
+		{name: "file", path: "erc20.json", want: FileSource{Path: "erc20.json"}},
+		{name: "path over url", path: "erc20.json", url: "http://localhost/abi", want: FileSource{Path: "erc20.json"}},
+		{name: "url", url: "http://localhost/abi?address=0x1", want: HTTPSource{URL: "http://localhost/abi?address=0x1"}},
+		{name: "stdin", path: "-", want: StdinSource{Reader: os.Stdin}},
+		{name: "inline array", path: ` [{"type":"fallback"}]`, want: InlineSource{JSON: ` [{"type":"fallback"}]`}},
+		{name: "inline object", path: `{"abi":[]}`, want: InlineSource{JSON: `{"abi":[]}`}},
+		{name: "glob", path: "out/**/*.json", want: GlobSource{Pattern: "out/**/*.json"}},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := NewAbiSource(tc.path, tc.url); !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("NewAbiSource(%q, %q) = %#v, want %#v", tc.path, tc.url, got, tc.want)
+			}
+		})
+	}
+}
+
+func TestLoadABI(t *testing.T) {
+	erc20, err := os.ReadFile(path.Join("testdata", "erc20.abi.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		switch req.URL.Path {
+		case "/api":
+			w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+			w.Write(erc20)
+		case "/login":
+			w.Header().Set("Content-Type", "text/html")
+			w.Write([]byte(`{"looks": "like json"}`))
+		default:
+			http.NotFound(w, req)
+		}
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		name        string
+		src         AbiSource
+		wantMethods []string // signatures that must be in the ABI.
+		wantEvents  []string
+		wantErrors  []string
+		wantErr     error
+		wantErrText string
+	}{
+		{
+			name:       "file",
+			src:        FileSource{Path: path.Join("testdata", "errors.abi.json")},
+			wantErrors: []string{"UnsupportedDestinationChain(uint64)"},
+		},
+		{
+			name:        "file with any extension",
+			src:         FileSource{Path: path.Join("testdata", "erc20.abi.txt")},
+			wantMethods: []string{"transfer(address,uint256)"},
+		},
+		{
+			name:        "http with a query string",
+			src:         HTTPSource{URL: server.URL + "/api?module=contract&action=getabi"},
+			wantMethods: []string{"transfer(address,uint256)"},
+		},
+		{
+			name:        "http error status",
+			src:         HTTPSource{URL: server.URL + "/missing.json"},
+			wantErrText: "404 Not Found",
+		},
+		{
+			name:    "http html content type",
+			src:     HTTPSource{URL: server.URL + "/login"},
+			wantErr: ErrInvalidAbi,
+		},
+		{
+			name:        "stdin",
+			src:         StdinSource{Reader: strings.NewReader(string(erc20))},
+			wantMethods: []string{"transfer(address,uint256)"},
+		},
+		{
+			name:        "inline",
+			src:         InlineSource{JSON: `[{"type":"function","name":"pause","inputs":[],"outputs":[],"stateMutability":"nonpayable"}]`},
+			wantMethods: []string{"pause()"},
+		},
+		{
+			name:        "glob merges every file, skipping duplicate signatures",
+			src:         GlobSource{Pattern: "testdata/artifacts/**/*.json"},
+			wantMethods: []string{"sweep(address,uint256[])", "transfer(address,uint256)"},
+			wantEvents:  []string{"Swept(address,uint256)", "Debug(uint256)"},
+			wantErrors:  []string{"VaultLocked(uint64)"},
+		},
+		{
+			name:        "glob skips files without an ABI",
+			src:         GlobSource{Pattern: "testdata/foundry/out/**/*.json"},
+			wantMethods: []string{"approve(address,uint256)"},
+			wantEvents:  []string{"Approval(address,address,uint256)"},
+		},
+		{
+			name:        "glob where no file holds an ABI",
+			src:         GlobSource{Pattern: "testdata/foundry/out/build-info/*.json"},
+			wantErr:     ErrInvalidAbi,
+			wantErrText: "1 files were skipped",
+		},
+		{
+			name:    "glob without matches",
+			src:     GlobSource{Pattern: "testdata/**/*.sol"},
+			wantErr: ErrNoAbiSource,
+		},
+		{
+			name:    "no source",
+			wantErr: ErrNoAbiSource,
+		},
+		{
+			name:        "not JSON",
+			src:         FileSource{Path: path.Join("testdata", "not-json.html")},
+			wantErr:     ErrInvalidAbi,
+			wantErrText: "text/html",
+		},
+		{
+			name:    "empty",
+			src:     InlineSource{JSON: " \n"},
+			wantErr: ErrInvalidAbi,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := LoadABI(tc.src)
+			if tc.wantErr != nil || tc.wantErrText != "" {
+				if tc.wantErr != nil && !errors.Is(err, tc.wantErr) {
+					t.Errorf("error = %v, want %v", err, tc.wantErr)
+				}
+				if err == nil || !strings.Contains(err.Error(), tc.wantErrText) {
+					t.Errorf("error = %v, want it to contain %q", err, tc.wantErrText)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			var methods, events, abiErrors []string
+			for _, method := range got.Methods {
+				methods = append(methods, method.Sig)
+			}
+			for _, ev := range got.Events {
+				events = append(events, ev.Sig)
+			}
+			for _, abiErr := range got.Errors {
+				abiErrors = append(abiErrors, abiErr.Sig)
+			}
+			assertContainsOnce(t, "methods", methods, tc.wantMethods)
+			assertContainsOnce(t, "events", events, tc.wantEvents)
+			assertContainsOnce(t, "errors", abiErrors, tc.wantErrors)
+		})
+	}
+}
+
+func assertContainsOnce(t *testing.T, what string, got []string, want []string) {
+	t.Helper()
+	for _, w := range want {
+		count := 0
+		for _, g := range got {
+			if g == w {
+				count++
+			}
+		}
+		if count != 1 {
+			t.Errorf("%s = %v, want %s exactly once", what, got, w)
+		}
+	}
+}
@@ -0,0 +1,19 @@
+package sig
+
+import "errors"
+
+// Sentinel errors returned by this package. Callers can match them with errors.Is.
+// Hex input that is not valid hex wraps encdec.ErrInvalidHex.
+var (
+	// ErrInvalidSignature is returned when a signature is not 64 or 65 bytes long, has a v
+	// other than 0, 1, 27 or 28, has r or s out of range, or does not recover to a public key,
+	// and when a signature is asked for in a form it has none, eg: a compact high-s one.
+	ErrInvalidSignature = errors.New("invalid ECDSA signature")
+
+	// ErrInvalidDigest is returned when the digest a signature is recovered from is not 32 bytes long.
+	ErrInvalidDigest = errors.New("invalid digest")
+
+	// ErrSignerMismatch is returned when a signature recovers to another address than the
+	// expected one.
+	ErrSignerMismatch = errors.New("signer mismatch")
+)
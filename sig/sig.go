@@ -0,0 +1,166 @@
+// Package sig recovers, verifies and converts the secp256k1 ECDSA signatures Ethereum
+// uses, eg: those of personal_sign, EIP-712 and permits.
+package sig
+
+import (
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/zeuslawyer/hextool/encdec"
+)
+
+var (
+	// The order of the secp256k1 curve, and half of it: the largest s of a low-s signature.
+	secp256k1N     = crypto.S256().Params().N
+	secp256k1HalfN = new(big.Int).Rsh(secp256k1N, 1)
+)
+
+// A signature: r, s and the parity of the y coordinate of the point r is the x of, which
+// recovery needs. The parity is encoded as v = 27 + yParity in 65-byte signatures, or as
+// v = yParity by go-ethereum's crypto.Sign, and as the top bit of s in EIP-2098 compact
+// signatures.
+type Signature struct {
+	R       *big.Int
+	S       *big.Int
+	YParity uint8
+}
+
+// Parses the hex string `sig`: either 65 bytes r || s || v, with v 0, 1, 27 or 28, or
+// 64 bytes r || yParityAndS, the EIP-2098 compact form.
+func Parse(sig string) (*Signature, error) {
+	b, err := encdec.HexToBytes(sig)
+	if err != nil {
+		return nil, err
+	}
+	return ParseBytes(b)
+}
+
+// Parses a 65-byte or EIP-2098 compact 64-byte signature, as Parse does.
+func ParseBytes(b []byte) (*Signature, error) {
+	s := &Signature{}
+	switch len(b) {
+	case 65:
+		switch v := b[64]; v {
+		case 0, 1:
+			s.YParity = v
+		case 27, 28:
+			s.YParity = v - 27
+		default:
+			return nil, fmt.Errorf("%w: v is %d, want 0, 1, 27 or 28", ErrInvalidSignature, v)
+		}
+		s.R, s.S = new(big.Int).SetBytes(b[:32]), new(big.Int).SetBytes(b[32:64])
+	case 64:
+		yParityAndS := new(big.Int).SetBytes(b[32:])
+		s.YParity = uint8(yParityAndS.Bit(255))
+		s.R, s.S = new(big.Int).SetBytes(b[:32]), yParityAndS.SetBit(yParityAndS, 255, 0)
+	default:
+		return nil, fmt.Errorf("%w: signature is %d bytes long, want 65, or 64 in the EIP-2098 compact form", ErrInvalidSignature, len(b))
+	}
+
+	if !crypto.ValidateSignatureValues(s.YParity, s.R, s.S, false) {
+		return nil, fmt.Errorf("%w: r and s must be between 1 and the order of secp256k1", ErrInvalidSignature)
+	}
+	return s, nil
+}
+
+// Reports whether s is in the upper half of the curve order. The ecrecover precompile
+// accepts such signatures, but transactions, OpenZeppelin's ECDSA and EIP-2098 do not.
+func (s *Signature) IsHighS() bool {
+	return s.S.Cmp(secp256k1HalfN) > 0
+}
+
+// Returns the low-s signature equivalent to s: s itself if its s is low, or one with s
+// replaced by n - s and the parity flipped, which recovers to the same signer.
+func (s *Signature) Normalize() *Signature {
+	if !s.IsHighS() {
+		return &Signature{R: s.R, S: s.S, YParity: s.YParity}
+	}
+	return &Signature{R: s.R, S: new(big.Int).Sub(secp256k1N, s.S), YParity: s.YParity ^ 1}
+}
+
+// Returns the 65-byte signature r || s || v with v 27 or 28, as personal_sign, wallets and
+// the ecrecover precompile use.
+func (s *Signature) Bytes() []byte {
+	b := s.RecoveryBytes()
+	b[64] += 27
+	return b
+}
+
+// Returns the 65-byte signature r || s || v with v 0 or 1, as go-ethereum's crypto.Sign
+// and crypto.Ecrecover use.
+func (s *Signature) RecoveryBytes() []byte {
+	b := make([]byte, 65)
+	s.R.FillBytes(b[:32])
+	s.S.FillBytes(b[32:64])
+	b[64] = s.YParity
+	return b
+}
+
+// Returns the EIP-2098 compact signature r || yParityAndS, where the top bit of s holds
+// the parity. Only low-s signatures have a compact form, see Normalize.
+func (s *Signature) CompactBytes() ([]byte, error) {
+	if s.IsHighS() {
+		return nil, fmt.Errorf("%w: s is high, EIP-2098 only encodes low-s signatures, normalize it first", ErrInvalidSignature)
+	}
+	yParityAndS := new(big.Int).Set(s.S)
+	if s.YParity == 1 {
+		yParityAndS.SetBit(yParityAndS, 255, 1)
+	}
+	b := make([]byte, 64)
+	s.R.FillBytes(b[:32])
+	yParityAndS.FillBytes(b[32:])
+	return b, nil
+}
+
+// Recovers the address that signed the 32-byte `digest`. High-s signatures recover as the
+// ecrecover precompile recovers them.
+func (s *Signature) Recover(digest []byte) (common.Address, error) {
+	if len(digest) != common.HashLength {
+		return common.Address{}, fmt.Errorf("%w: it is %d bytes long, want 32", ErrInvalidDigest, len(digest))
+	}
+	pub, err := crypto.SigToPub(digest, s.RecoveryBytes())
+	if err != nil {
+		return common.Address{}, fmt.Errorf("%w: %v", ErrInvalidSignature, err)
+	}
+	return crypto.PubkeyToAddress(*pub), nil
+}
+
+// Recovers the address that signed the 32-byte `digest`, and checks it is `expected`.
+// The recovered address is returned either way.
+func (s *Signature) Verify(digest []byte, expected common.Address) (common.Address, error) {
+	signer, err := s.Recover(digest)
+	if err != nil {
+		return common.Address{}, err
+	}
+	if signer != expected {
+		return signer, fmt.Errorf("%w: signed by %s, not %s", ErrSignerMismatch, signer.Hex(), expected.Hex())
+	}
+	return signer, nil
+}
+
+// Formats the signature as one `name: value` line per part, eg:
+//
+//	r: 0x...
+//	s: 0x...
+//	v: 28
+//	yParity: 1
+//	highS: false
+//	signature: 0x...1c
+//	compact: 0x...
+func (s *Signature) String() string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "r: %s\n", hexutil.Encode(s.R.FillBytes(make([]byte, 32))))
+	fmt.Fprintf(&sb, "s: %s\n", hexutil.Encode(s.S.FillBytes(make([]byte, 32))))
+	fmt.Fprintf(&sb, "v: %d\nyParity: %d\nhighS: %t\n", 27+s.YParity, s.YParity, s.IsHighS())
+	fmt.Fprintf(&sb, "signature: %s\n", hexutil.Encode(s.Bytes()))
+	if compact, err := s.CompactBytes(); err == nil {
+		fmt.Fprintf(&sb, "compact: %s", hexutil.Encode(compact))
+	} else {
+		sb.WriteString("compact: none, s is high")
+	}
+	return sb.String()
+}
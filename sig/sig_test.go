@@ -0,0 +1,135 @@
+package sig
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/zeuslawyer/hextool/encdec"
+	"github.com/zeuslawyer/hextool/hashing"
+)
+
+// The examples of EIP-2098: personal_sign signatures of the key
+// 0x1234567890123456789012345678901234567890123456789012345678901234.
+func TestEIP2098Examples(t *testing.T) {
+	key, _ := crypto.HexToECDSA("1234567890123456789012345678901234567890123456789012345678901234")
+	signer := crypto.PubkeyToAddress(key.PublicKey)
+
+	tests := []struct {
+		message string
+		sig     string
+		compact string
+	}{
+		{
+			message: "Hello World",
+			sig:     "0x68a020a209d3d56c46f38cc50a33f704f4a9a10a59377f8dd762ac66910e9b907e865ad05c4035ab5792787d4a0297a43617ae897930a6fe4d822b8faea520641b",
+			compact: "0x68a020a209d3d56c46f38cc50a33f704f4a9a10a59377f8dd762ac66910e9b907e865ad05c4035ab5792787d4a0297a43617ae897930a6fe4d822b8faea52064",
+		},
+		{
+			message: "It's a small(er) world",
+			sig:     "0x9328da16089fcba9bececa81663203989f2df5fe1faa6291a45381c81bd17f76139c6d6b623b42da56557e5e734a43dc83345ddfadec52cbe24d0cc64f5507931c",
+			compact: "0x9328da16089fcba9bececa81663203989f2df5fe1faa6291a45381c81bd17f76939c6d6b623b42da56557e5e734a43dc83345ddfadec52cbe24d0cc64f550793",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.message, func(t *testing.T) {
+			digest, _ := hashing.Sum(hashing.EIP191, []byte(tc.message))
+			for _, input := range []string{tc.sig, tc.compact} {
+				s, err := Parse(input)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if got, err := s.Verify(digest, signer); err != nil || got != signer {
+					t.Errorf("Verify(%s) = %s, %v, want %s", input, got.Hex(), err, signer.Hex())
+				}
+				if got := hexutil.Encode(s.Bytes()); got != tc.sig {
+					t.Errorf("Bytes() = %s, want %s", got, tc.sig)
+				}
+				if compact, err := s.CompactBytes(); err != nil || hexutil.Encode(compact) != tc.compact {
+					t.Errorf("CompactBytes() = %x, %v, want %s", compact, err, tc.compact)
+				}
+			}
+		})
+	}
+}
+
+func TestNormalize(t *testing.T) {
+	key, _ := crypto.HexToECDSA("1234567890123456789012345678901234567890123456789012345678901234")
+	signer := crypto.PubkeyToAddress(key.PublicKey)
+	digest := crypto.Keccak256([]byte("digest"))
+	raw, _ := crypto.Sign(digest, key)
+
+	// v 0/1 and 27/28 parse to the same signature.
+	low, err := ParseBytes(raw)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := hexutil.Encode(low.RecoveryBytes()); got != hexutil.Encode(raw) {
+		t.Errorf("RecoveryBytes() = %s, want %x", got, raw)
+	}
+	with27, err := ParseBytes(low.Bytes())
+	if err != nil || with27.YParity != low.YParity || with27.Bytes()[64] != 27+low.YParity {
+		t.Errorf("ParseBytes(Bytes()) = %v, %v, want yParity %d", with27, err, low.YParity)
+	}
+
+	// the malleable twin of a signature has s' = n - s and the other parity, and recovers
+	// to the same signer.
+	high := &Signature{R: low.R, S: new(big.Int).Sub(secp256k1N, low.S), YParity: low.YParity ^ 1}
+	if !high.IsHighS() || low.IsHighS() {
+		t.Fatalf("IsHighS() = %t for the high twin, %t for the low signature", high.IsHighS(), low.IsHighS())
+	}
+	if got, err := high.Recover(digest); err != nil || got != signer {
+		t.Errorf("Recover() of the high twin = %s, %v, want %s", got.Hex(), err, signer.Hex())
+	}
+	if _, err := high.CompactBytes(); !errors.Is(err, ErrInvalidSignature) {
+		t.Errorf("CompactBytes() of a high-s signature: error = %v, want %v", err, ErrInvalidSignature)
+	}
+	normalized := high.Normalize()
+	if normalized.S.Cmp(low.S) != 0 || normalized.YParity != low.YParity {
+		t.Errorf("Normalize() = %v, want %v", normalized, low)
+	}
+	if normalized := low.Normalize(); normalized.S.Cmp(low.S) != 0 || normalized.YParity != low.YParity {
+		t.Errorf("Normalize() of a low-s signature = %v, want it unchanged", normalized)
+	}
+}
+
+func TestSignatureErrors(t *testing.T) {
+	valid := "0x68a020a209d3d56c46f38cc50a33f704f4a9a10a59377f8dd762ac66910e9b907e865ad05c4035ab5792787d4a0297a43617ae897930a6fe4d822b8faea52064"
+	n := common.BigToHash(secp256k1N).Hex()[2:]
+
+	tests := []struct {
+		name string
+		sig  string
+		err  error
+	}{
+		{"not hex", "0xzz", encdec.ErrInvalidHex},
+		{"too short", valid[:128], ErrInvalidSignature},
+		{"v of EIP-155", valid + "25", ErrInvalidSignature},
+		{"zero r", "0x" + common.Hash{}.Hex()[2:] + valid[66:] + "1b", ErrInvalidSignature},
+		{"s of n", valid[:66] + n + "1b", ErrInvalidSignature},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if _, err := Parse(tc.sig); !errors.Is(err, tc.err) {
+				t.Errorf("Parse() error = %v, want %v", err, tc.err)
+			}
+		})
+	}
+
+	s, err := Parse(valid)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.Recover([]byte{1}); !errors.Is(err, ErrInvalidDigest) {
+		t.Errorf("Recover() of a 1-byte digest: error = %v, want %v", err, ErrInvalidDigest)
+	}
+	digest, _ := hashing.Sum(hashing.EIP191, []byte("Hello World"))
+	if _, err := s.Verify(digest, common.HexToAddress("0x01")); !errors.Is(err, ErrSignerMismatch) {
+		t.Errorf("Verify() with another address: error = %v, want %v", err, ErrSignerMismatch)
+	}
+}
@@ -0,0 +1,291 @@
+package tx
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"os"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/accounts/keystore"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/zeuslawyer/hextool/encdec"
+)
+
+// An integer in a Spec: a JSON number, or a string holding a decimal or 0x-prefixed hex number.
+type Quantity struct {
+	*big.Int
+}
+
+// Parses a decimal or 0x-prefixed hex number, eg: "21000" or "0x5208".
+func ParseQuantity(s string) (Quantity, error) {
+	s = strings.TrimSpace(s)
+	if strings.HasPrefix(s, "0x") {
+		n, err := encdec.HexToBigInt(s)
+		if err != nil {
+			return Quantity{}, err
+		}
+		return Quantity{n}, nil
+	}
+	n, ok := new(big.Int).SetString(s, 10)
+	if !ok {
+		return Quantity{}, fmt.Errorf("%w: %q is not a decimal or 0x-prefixed hex number", ErrInvalidTx, s)
+	}
+	return Quantity{n}, nil
+}
+
+// Parses a JSON number, or a string that ParseQuantity parses.
+func (q *Quantity) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		s = string(data)
+	}
+	parsed, err := ParseQuantity(s)
+	if err != nil {
+		return err
+	}
+	*q = parsed
+	return nil
+}
+
+// The fields of a transaction to build, named as in the JSON-RPC API, eg:
+//
+//	{"type": 2, "chainId": 1, "nonce": 0, "gas": 21000, "maxFeePerGas": "30000000000",
+//	 "maxPriorityFeePerGas": "1000000000", "to": "0x...", "value": "0xde0b6b3a7640000"}
+//
+// Numbers can be JSON numbers or strings, in decimal or hex. `data` is accepted for `input`.
+type Spec struct {
+	Type                 *Quantity           `json:"type"`
+	ChainID              *Quantity           `json:"chainId"`
+	Nonce                *Quantity           `json:"nonce"`
+	Gas                  *Quantity           `json:"gas"`
+	GasPrice             *Quantity           `json:"gasPrice"`
+	MaxPriorityFeePerGas *Quantity           `json:"maxPriorityFeePerGas"`
+	MaxFeePerGas         *Quantity           `json:"maxFeePerGas"`
+	MaxFeePerBlobGas     *Quantity           `json:"maxFeePerBlobGas"`
+	To                   *common.Address     `json:"to"`
+	Value                *Quantity           `json:"value"`
+	Input                *hexutil.Bytes      `json:"input"`
+	Data                 *hexutil.Bytes      `json:"data"`
+	AccessList           []AccessTupleSpec   `json:"accessList"`
+	BlobVersionedHashes  []common.Hash       `json:"blobVersionedHashes"`
+	AuthorizationList    []AuthorizationSpec `json:"authorizationList"`
+}
+
+// An entry of the access list of a Spec.
+type AccessTupleSpec struct {
+	Address     common.Address `json:"address"`
+	StorageKeys []common.Hash  `json:"storageKeys"`
+}
+
+// A signed EIP-7702 authorization in a Spec.
+type AuthorizationSpec struct {
+	ChainID Quantity       `json:"chainId"`
+	Address common.Address `json:"address"`
+	Nonce   Quantity       `json:"nonce"`
+	YParity Quantity       `json:"yParity"`
+	R       Quantity       `json:"r"`
+	S       Quantity       `json:"s"`
+}
+
+// Reads the JSON spec of a transaction from the file at `path`.
+func LoadSpec(path string) (*Spec, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	decoder := json.NewDecoder(bytes.NewReader(b))
+	decoder.DisallowUnknownFields()
+	var spec Spec
+	if err := decoder.Decode(&spec); err != nil {
+		return nil, fmt.Errorf("%w: %s: %v", ErrInvalidTx, path, err)
+	}
+	return &spec, nil
+}
+
+// Builds the unsigned transaction of `spec`. The type defaults to EIP-1559. The nonce and gas
+// are required, as are the chain id of typed transactions and the fees of the type. Value and
+// input default to zero and empty, and a missing `to` creates a contract, which blob and
+// EIP-7702 transactions cannot do.
+func Build(spec *Spec) (*Tx, error) {
+	tx := &Tx{Type: DynamicFeeTxType, Value: new(big.Int)}
+	if spec.Type != nil {
+		if !spec.Type.IsUint64() || spec.Type.Uint64() > SetCodeTxType {
+			return nil, fmt.Errorf("%w: unknown transaction type %s", ErrInvalidTx, spec.Type)
+		}
+		tx.Type = uint8(spec.Type.Uint64())
+	}
+	fields := txFields[tx.Type]
+
+	// every field the spec sets must be one of the type, and every field of the type but the
+	// optional ones must be set.
+	optional := map[string]bool{"to": true, "value": true, "input": true, "accessList": true, "chainId": tx.Type == LegacyTxType}
+	set := map[string]bool{}
+	for name, isSet := range map[string]bool{
+		"chainId":              spec.ChainID != nil,
+		"nonce":                spec.Nonce != nil,
+		"gas":                  spec.Gas != nil,
+		"gasPrice":             spec.GasPrice != nil,
+		"maxPriorityFeePerGas": spec.MaxPriorityFeePerGas != nil,
+		"maxFeePerGas":         spec.MaxFeePerGas != nil,
+		"maxFeePerBlobGas":     spec.MaxFeePerBlobGas != nil,
+		"to":                   spec.To != nil,
+		"value":                spec.Value != nil,
+		"input":                spec.Input != nil || spec.Data != nil,
+		"accessList":           spec.AccessList != nil,
+		"blobVersionedHashes":  spec.BlobVersionedHashes != nil,
+		"authorizationList":    spec.AuthorizationList != nil,
+	} {
+		// legacy transactions take their chain id from v, with EIP-155.
+		if isSet && !hasName(fields, name) && !(name == "chainId" && tx.Type == LegacyTxType) {
+			return nil, fmt.Errorf("%w: %s transactions have no %s", ErrInvalidTx, typeNames[tx.Type], name)
+		}
+		set[name] = isSet
+	}
+	for _, name := range fields {
+		if !set[name] && !optional[name] {
+			return nil, fmt.Errorf("%w: %s is required for %s transactions", ErrInvalidTx, name, typeNames[tx.Type])
+		}
+	}
+	if spec.Input != nil && spec.Data != nil && !bytes.Equal(*spec.Input, *spec.Data) {
+		return nil, fmt.Errorf("%w: input and data are both set, and differ", ErrInvalidTx)
+	}
+
+	var err error
+	if spec.ChainID != nil {
+		tx.ChainID = spec.ChainID.Int
+	}
+	if tx.Nonce, err = quantityUint64(spec.Nonce, "nonce"); err != nil {
+		return nil, err
+	}
+	if tx.Gas, err = quantityUint64(spec.Gas, "gas"); err != nil {
+		return nil, err
+	}
+	tx.GasPrice = quantityBig(spec.GasPrice)
+	tx.MaxPriorityFeePerGas = quantityBig(spec.MaxPriorityFeePerGas)
+	tx.MaxFeePerGas = quantityBig(spec.MaxFeePerGas)
+	tx.MaxFeePerBlobGas = quantityBig(spec.MaxFeePerBlobGas)
+	if spec.Value != nil {
+		tx.Value = spec.Value.Int
+	}
+	tx.To = spec.To
+	if tx.To == nil && (tx.Type == BlobTxType || tx.Type == SetCodeTxType) {
+		return nil, fmt.Errorf("%w: %s transactions cannot create contracts, to is required", ErrInvalidTx, typeNames[tx.Type])
+	}
+	if spec.Input != nil {
+		tx.Input = *spec.Input
+	} else if spec.Data != nil {
+		tx.Input = *spec.Data
+	}
+	for _, tuple := range spec.AccessList {
+		tx.AccessList = append(tx.AccessList, AccessTuple{Address: tuple.Address, StorageKeys: tuple.StorageKeys})
+	}
+	tx.BlobVersionedHashes = spec.BlobVersionedHashes
+	for idx, auth := range spec.AuthorizationList {
+		name := fmt.Sprintf("authorizationList[%d]", idx)
+		if auth.ChainID.Int == nil || auth.Nonce.Int == nil || auth.YParity.Int == nil || auth.R.Int == nil || auth.S.Int == nil {
+			return nil, fmt.Errorf("%w: %s needs a chainId, address, nonce, yParity, r and s", ErrInvalidTx, name)
+		}
+		nonce, err := quantityUint64(&auth.Nonce, name+".nonce")
+		if err != nil {
+			return nil, err
+		}
+		yParity, err := quantityUint64(&auth.YParity, name+".yParity")
+		if err != nil || yParity > 1 {
+			return nil, fmt.Errorf("%w: %s.yParity must be 0 or 1", ErrInvalidTxSignature, name)
+		}
+		tx.AuthorizationList = append(tx.AuthorizationList, Authorization{
+			ChainID: auth.ChainID.Int, Address: auth.Address, Nonce: nonce, YParity: uint8(yParity), R: auth.R.Int, S: auth.S.Int,
+		})
+	}
+
+	for _, value := range []*big.Int{tx.ChainID, tx.GasPrice, tx.MaxPriorityFeePerGas, tx.MaxFeePerGas, tx.MaxFeePerBlobGas, tx.Value} {
+		if value != nil && (value.Sign() < 0 || value.BitLen() > 256) {
+			return nil, fmt.Errorf("%w: %s is not an unsigned 256-bit integer", ErrInvalidTx, value)
+		}
+	}
+	return tx, nil
+}
+
+// Signs the transaction with `key`. Legacy transactions with a chain id are signed with
+// EIP-155 replay protection.
+func (tx *Tx) Sign(key *ecdsa.PrivateKey) error {
+	hash := tx.SigningHash()
+	sig, err := crypto.Sign(hash[:], key)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidTxSignature, err)
+	}
+	tx.R = new(big.Int).SetBytes(sig[:32])
+	tx.S = new(big.Int).SetBytes(sig[32:64])
+	tx.V = big.NewInt(int64(sig[64]))
+	if tx.Type == LegacyTxType {
+		if tx.ChainID != nil {
+			tx.V.Add(tx.V, new(big.Int).Add(new(big.Int).Lsh(tx.ChainID, 1), big.NewInt(35)))
+		} else {
+			tx.V.Add(tx.V, big.NewInt(27))
+		}
+	}
+	return nil
+}
+
+// Reads the private key in a file, as hex with or without the 0x prefix. Whitespace around
+// it, eg: a trailing newline, is ignored.
+func LoadKeyFile(path string) (*ecdsa.PrivateKey, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	key, err := crypto.HexToECDSA(strings.TrimPrefix(strings.TrimSpace(string(b)), "0x"))
+	if err != nil {
+		return nil, fmt.Errorf("%s does not hold a hex private key: %v", path, err)
+	}
+	return key, nil
+}
+
+// Decrypts the private key in an encrypted JSON keystore file, as geth and clef write them,
+// with the password in `passwordPath`. A trailing newline in the password file is ignored.
+func LoadKeystore(path string, passwordPath string) (*ecdsa.PrivateKey, error) {
+	keyJSON, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	password, err := os.ReadFile(passwordPath)
+	if err != nil {
+		return nil, err
+	}
+	key, err := keystore.DecryptKey(keyJSON, strings.TrimRight(string(password), "\r\n"))
+	if err != nil {
+		return nil, fmt.Errorf("decrypting keystore %s: %v", path, err)
+	}
+	return key.PrivateKey, nil
+}
+
+func quantityBig(q *Quantity) *big.Int {
+	if q == nil {
+		return nil
+	}
+	return q.Int
+}
+
+func quantityUint64(q *Quantity, name string) (uint64, error) {
+	if q == nil || q.Int == nil {
+		return 0, nil
+	}
+	if !q.IsUint64() {
+		return 0, fmt.Errorf("%w: %s %s does not fit in 64 bits", ErrInvalidTx, name, q)
+	}
+	return q.Uint64(), nil
+}
+
+func hasName(names []string, name string) bool {
+	for _, n := range names {
+		if n == name {
+			return true
+		}
+	}
+	return false
+}
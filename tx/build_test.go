@@ -0,0 +1,196 @@
+package tx
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/accounts/keystore"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// Signing is deterministic, so the transactions of TestDecode built and signed again must
+// encode to the same raw transactions.
+func TestBuildAndSign(t *testing.T) {
+	key, _ := crypto.HexToECDSA(testKey)
+	to := common.HexToAddress("0x208AA722Aca42399eaC5192EE778e4D42f4E5De3")
+	quantity := func(s string) *Quantity {
+		q, err := ParseQuantity(s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return &q
+	}
+	input := hexutil.Bytes(common.FromHex("0x6080"))
+
+	spec1559, err := LoadSpec("testdata/eip1559.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name string
+		spec *Spec
+		raw  string
+	}{
+		{
+			name: "legacy, EIP-155",
+			spec: &Spec{
+				Type: quantity("0"), ChainID: quantity("1"), Nonce: quantity("7"), Gas: quantity("0x5208"),
+				GasPrice: quantity("20000000000"), To: &to, Value: quantity("1000000000000000000"),
+			},
+			raw: "0xf86c078504a817c80082520894208aa722aca42399eac5192ee778e4d42f4e5de3880de0b6b3a76400008026a0b17c1bafce3ab8327330d7cf73a3f248c24a2f41fa6fa61daa1c2c18664f7f7da05c90f7e21032a4bdceed6a6b81066714971b1e3bbd6e7ab7a33229a7d3bf878c",
+		},
+		{
+			name: "legacy contract creation, without EIP-155",
+			spec: &Spec{Type: quantity("0"), Nonce: quantity("0"), Gas: quantity("100000"), GasPrice: quantity("1000000000"), Input: &input},
+			raw:  "0xf85280843b9aca00830186a080808260801ca09531448ac68bd15aa29a5e78fe1aafc174eadaa4829a0167a54b4b0485431646a02850530b08b44255d7e6dab159a823be1d7b868625ea28cde22f5ba3d2fbd439",
+		},
+		{
+			name: "EIP-1559, from a JSON spec",
+			spec: spec1559,
+			raw:  "0x02f9010c010284773594008509502f900082ea6094208aa722aca42399eac5192ee778e4d42f4e5de380b844a9059cbb000000000000000000000000208aa722aca42399eac5192ee778e4d42f4e5de300000000000000000000000000000000000000000000000000000000000003e8f85bf85994208aa722aca42399eac5192ee778e4d42f4e5de3f842a00000000000000000000000000000000000000000000000000000000000000001a0000000000000000000000000000000000000000000000000000000000000000280a0e774517a7e1c446edde6022a08f8bc10ef600cec4c173500d0ef1332aa59be9da0298d83840907f4749025a0339c890d1852b434780646430126fc14650b179740",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			tx, err := Build(tc.spec)
+			if err != nil {
+				t.Fatal(err)
+			}
+			want, err := Decode(tc.raw)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if tx.SigningHash() != want.SigningHash() {
+				t.Errorf("SigningHash() = %s, want %s", tx.SigningHash(), want.SigningHash())
+			}
+
+			// the unsigned transaction decodes back with its chain id, legacy ones included.
+			unsigned, err := DecodeBytes(tx.Encode())
+			if err != nil {
+				t.Fatalf("decoding the unsigned transaction: %v", err)
+			}
+			if unsigned.V != nil || (unsigned.ChainID == nil) != (want.ChainID == nil) ||
+				(want.ChainID != nil && unsigned.ChainID.Cmp(want.ChainID) != 0) {
+				t.Errorf("unsigned transaction decodes with chain id %v and v %v, want chain id %v and no signature", unsigned.ChainID, unsigned.V, want.ChainID)
+			}
+			if unsigned.SigningHash() != want.SigningHash() {
+				t.Errorf("SigningHash() of the unsigned transaction = %s, want %s", unsigned.SigningHash(), want.SigningHash())
+			}
+
+			if err := tx.Sign(key); err != nil {
+				t.Fatal(err)
+			}
+			if got := hexutil.Encode(tx.Encode()); got != tc.raw {
+				t.Errorf("Encode() = %s, want %s", got, tc.raw)
+			}
+			if sender, err := tx.Sender(); err != nil || sender.Hex() != testSender {
+				t.Errorf("Sender() = %s, %v, want %s", sender.Hex(), err, testSender)
+			}
+		})
+	}
+}
+
+func TestBuildErrors(t *testing.T) {
+	quantity := func(s string) *Quantity {
+		q, err := ParseQuantity(s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return &q
+	}
+	to := common.HexToAddress("0x208AA722Aca42399eaC5192EE778e4D42f4E5De3")
+	data := hexutil.Bytes{1}
+	base := func() *Spec {
+		return &Spec{ChainID: quantity("1"), Nonce: quantity("0"), Gas: quantity("21000"), MaxFeePerGas: quantity("2"), MaxPriorityFeePerGas: quantity("1"), To: &to}
+	}
+
+	tests := []struct {
+		name   string
+		modify func(spec *Spec)
+		err    error
+	}{
+		{"unknown type", func(spec *Spec) { spec.Type = quantity("5") }, ErrInvalidTx},
+		{"missing nonce", func(spec *Spec) { spec.Nonce = nil }, ErrInvalidTx},
+		{"missing chain id", func(spec *Spec) { spec.ChainID = nil }, ErrInvalidTx},
+		{"missing fee", func(spec *Spec) { spec.MaxFeePerGas = nil }, ErrInvalidTx},
+		{"field of another type", func(spec *Spec) { spec.GasPrice = quantity("1") }, ErrInvalidTx},
+		{"input and data differ", func(spec *Spec) { spec.Input, spec.Data = &data, &hexutil.Bytes{2} }, ErrInvalidTx},
+		{"gas over 64 bits", func(spec *Spec) { spec.Gas = quantity("0x10000000000000000") }, ErrInvalidTx},
+		{"negative value", func(spec *Spec) { spec.Value = quantity("-1") }, ErrInvalidTx},
+		{"blob transaction creating a contract", func(spec *Spec) {
+			spec.Type, spec.To, spec.MaxFeePerBlobGas, spec.BlobVersionedHashes = quantity("3"), nil, quantity("1"), []common.Hash{{1}}
+		}, ErrInvalidTx},
+		{"blob transaction without blob hashes", func(spec *Spec) { spec.Type, spec.MaxFeePerBlobGas = quantity("3"), quantity("1") }, ErrInvalidTx},
+		{"incomplete authorization", func(spec *Spec) {
+			spec.Type, spec.AuthorizationList = quantity("4"), []AuthorizationSpec{{ChainID: *quantity("1"), Nonce: *quantity("0")}}
+		}, ErrInvalidTx},
+		{"authorization y parity", func(spec *Spec) {
+			spec.Type = quantity("4")
+			spec.AuthorizationList = []AuthorizationSpec{{ChainID: *quantity("1"), Nonce: *quantity("0"), YParity: *quantity("27"), R: *quantity("1"), S: *quantity("1")}}
+		}, ErrInvalidTxSignature},
+	}
+
+	if _, err := Build(base()); err != nil {
+		t.Fatalf("Build() of the base spec: %v", err)
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			spec := base()
+			tc.modify(spec)
+			if _, err := Build(spec); !errors.Is(err, tc.err) {
+				t.Errorf("Build() error = %v, want %v", err, tc.err)
+			}
+		})
+	}
+
+	path := filepath.Join(t.TempDir(), "spec.json")
+	if err := os.WriteFile(path, []byte(`{"nonce": 0, "gasLimit": 21000}`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadSpec(path); !errors.Is(err, ErrInvalidTx) {
+		t.Errorf("LoadSpec() of a spec with an unknown field: error = %v, want %v", err, ErrInvalidTx)
+	}
+}
+
+func TestLoadKeys(t *testing.T) {
+	dir := t.TempDir()
+	key, _ := crypto.HexToECDSA(testKey)
+
+	keyPath := filepath.Join(dir, "key")
+	if err := os.WriteFile(keyPath, []byte("0x"+testKey+"\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := LoadKeyFile(keyPath)
+	if err != nil || !loaded.Equal(key) {
+		t.Errorf("LoadKeyFile() = %v, %v", loaded, err)
+	}
+
+	keyJSON, err := keystore.EncryptKey(&keystore.Key{Address: crypto.PubkeyToAddress(key.PublicKey), PrivateKey: key}, "secret", keystore.LightScryptN, keystore.LightScryptP)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keystorePath, passwordPath := filepath.Join(dir, "keystore.json"), filepath.Join(dir, "password")
+	if err := os.WriteFile(keystorePath, keyJSON, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(passwordPath, []byte("secret\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	loaded, err = LoadKeystore(keystorePath, passwordPath)
+	if err != nil || !loaded.Equal(key) {
+		t.Errorf("LoadKeystore() = %v, %v", loaded, err)
+	}
+
+	if err := os.WriteFile(passwordPath, []byte("wrong"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadKeystore(keystorePath, passwordPath); err == nil {
+		t.Error("LoadKeystore() with a wrong password: expected an error")
+	}
+}
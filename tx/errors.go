@@ -0,0 +1,16 @@
+package tx
+
+import "errors"
+
+// Sentinel errors returned by this package. Callers can match them with errors.Is.
+// Hex input that is not valid hex wraps encdec.ErrInvalidHex, and RLP that is not
+// canonical wraps rlp.ErrInvalidRLP.
+var (
+	// ErrInvalidTx is returned when a transaction has an unknown type, or fields that do
+	// not match its type.
+	ErrInvalidTx = errors.New("invalid transaction")
+
+	// ErrInvalidTxSignature is returned when the signer of a transaction or an authorization
+	// cannot be recovered from its signature.
+	ErrInvalidTxSignature = errors.New("invalid transaction signature")
+)
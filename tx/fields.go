@@ -0,0 +1,157 @@
+package tx
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/zeuslawyer/hextool/rlp"
+)
+
+// Returns the bytes of an RLP string. `name` names the field, for use in error messages.
+func decodeString(item rlp.Item, name string) ([]byte, error) {
+	if item.IsList {
+		return nil, fmt.Errorf("%w: %s must be a byte string, got a list", ErrInvalidTx, name)
+	}
+	return item.Bytes, nil
+}
+
+// Decodes an integer of up to 256 bits, in big-endian bytes with no leading zeros.
+func decodeBig(item rlp.Item, name string) (*big.Int, error) {
+	b, err := decodeString(item, name)
+	if err != nil {
+		return nil, err
+	}
+	if len(b) > 32 {
+		return nil, fmt.Errorf("%w: %s is %d bytes long, integers have at most 32", ErrInvalidTx, name, len(b))
+	}
+	if len(b) > 0 && b[0] == 0 {
+		return nil, fmt.Errorf("%w: %s has leading zero bytes", ErrInvalidTx, name)
+	}
+	return new(big.Int).SetBytes(b), nil
+}
+
+func decodeUint64(item rlp.Item, name string) (uint64, error) {
+	n, err := decodeBig(item, name)
+	if err != nil {
+		return 0, err
+	}
+	if !n.IsUint64() {
+		return 0, fmt.Errorf("%w: %s %s does not fit in 64 bits", ErrInvalidTx, name, n)
+	}
+	return n.Uint64(), nil
+}
+
+func decodeAddress(item rlp.Item, name string) (common.Address, error) {
+	b, err := decodeString(item, name)
+	if err != nil {
+		return common.Address{}, err
+	}
+	if len(b) != common.AddressLength {
+		return common.Address{}, fmt.Errorf("%w: %s is %d bytes long, addresses have 20", ErrInvalidTx, name, len(b))
+	}
+	return common.BytesToAddress(b), nil
+}
+
+func decodeHashes(item rlp.Item, name string) ([]common.Hash, error) {
+	if !item.IsList {
+		return nil, fmt.Errorf("%w: %s must be a list", ErrInvalidTx, name)
+	}
+	hashes := make([]common.Hash, len(item.Items))
+	for idx, elem := range item.Items {
+		b, err := decodeString(elem, name)
+		if err != nil {
+			return nil, err
+		}
+		if len(b) != common.HashLength {
+			return nil, fmt.Errorf("%w: %s[%d] is %d bytes long, want 32", ErrInvalidTx, name, idx, len(b))
+		}
+		hashes[idx] = common.BytesToHash(b)
+	}
+	return hashes, nil
+}
+
+// Decodes an access list: a list of [address, [storageKey, ...]] lists.
+func decodeAccessList(item rlp.Item) ([]AccessTuple, error) {
+	if !item.IsList {
+		return nil, fmt.Errorf("%w: accessList must be a list", ErrInvalidTx)
+	}
+	accessList := make([]AccessTuple, len(item.Items))
+	for idx, elem := range item.Items {
+		name := fmt.Sprintf("accessList[%d]", idx)
+		if !elem.IsList || len(elem.Items) != 2 {
+			return nil, fmt.Errorf("%w: %s must be a list of an address and storage keys", ErrInvalidTx, name)
+		}
+		address, err := decodeAddress(elem.Items[0], name+".address")
+		if err != nil {
+			return nil, err
+		}
+		keys, err := decodeHashes(elem.Items[1], name+".storageKeys")
+		if err != nil {
+			return nil, err
+		}
+		accessList[idx] = AccessTuple{Address: address, StorageKeys: keys}
+	}
+	return accessList, nil
+}
+
+// Decodes an authorization list: a list of [chainId, address, nonce, yParity, r, s] lists.
+func decodeAuthorizations(item rlp.Item) ([]Authorization, error) {
+	if !item.IsList {
+		return nil, fmt.Errorf("%w: authorizationList must be a list", ErrInvalidTx)
+	}
+	auths := make([]Authorization, len(item.Items))
+	for idx, elem := range item.Items {
+		name := fmt.Sprintf("authorizationList[%d]", idx)
+		if !elem.IsList || len(elem.Items) != 6 {
+			return nil, fmt.Errorf("%w: %s must be a list of chainId, address, nonce, yParity, r and s", ErrInvalidTx, name)
+		}
+		auth := &auths[idx]
+		var err error
+		if auth.ChainID, err = decodeBig(elem.Items[0], name+".chainId"); err != nil {
+			return nil, err
+		}
+		if auth.Address, err = decodeAddress(elem.Items[1], name+".address"); err != nil {
+			return nil, err
+		}
+		if auth.Nonce, err = decodeUint64(elem.Items[2], name+".nonce"); err != nil {
+			return nil, err
+		}
+		yParity, err := decodeUint64(elem.Items[3], name+".yParity")
+		if err != nil {
+			return nil, err
+		}
+		if yParity > 1 {
+			return nil, fmt.Errorf("%w: %s.yParity must be 0 or 1, got %d", ErrInvalidTxSignature, name, yParity)
+		}
+		auth.YParity = uint8(yParity)
+		if auth.R, err = decodeBig(elem.Items[4], name+".r"); err != nil {
+			return nil, err
+		}
+		if auth.S, err = decodeBig(elem.Items[5], name+".s"); err != nil {
+			return nil, err
+		}
+	}
+	return auths, nil
+}
+
+// Returns the RLP string of an integer: its big-endian bytes with no leading zeros, and the
+// empty string for 0 and nil.
+func bigItem(n *big.Int) rlp.Item {
+	if n == nil {
+		return rlp.Item{}
+	}
+	return rlp.Item{Bytes: n.Bytes()}
+}
+
+func uintItem(n uint64) rlp.Item {
+	return bigItem(new(big.Int).SetUint64(n))
+}
+
+func hashesItem(hashes []common.Hash) rlp.Item {
+	list := rlp.Item{IsList: true, Items: []rlp.Item{}}
+	for _, hash := range hashes {
+		list.Items = append(list.Items, rlp.Item{Bytes: hash.Bytes()})
+	}
+	return list
+}
@@ -0,0 +1,464 @@
+// Package tx decodes, encodes and signs raw Ethereum transactions: legacy ones, and the
+// EIP-2718 typed envelopes of EIP-2930, EIP-1559, EIP-4844 and EIP-7702.
+package tx
+
+import (
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/zeuslawyer/hextool/encdec"
+	"github.com/zeuslawyer/hextool/rlp"
+)
+
+// The transaction types, which typed transactions start with.
+const (
+	LegacyTxType     = 0x00
+	AccessListTxType = 0x01 // EIP-2930
+	DynamicFeeTxType = 0x02 // EIP-1559
+	BlobTxType       = 0x03 // EIP-4844
+	SetCodeTxType    = 0x04 // EIP-7702
+)
+
+// The EIP that introduced each transaction type.
+var typeNames = map[uint8]string{
+	LegacyTxType:     "legacy",
+	AccessListTxType: "EIP-2930",
+	DynamicFeeTxType: "EIP-1559",
+	BlobTxType:       "EIP-4844",
+	SetCodeTxType:    "EIP-7702",
+}
+
+// The RLP fields of each transaction type, in order and without the signature, named as
+// in the JSON-RPC API.
+var txFields = map[uint8][]string{
+	LegacyTxType:     {"nonce", "gasPrice", "gas", "to", "value", "input"},
+	AccessListTxType: {"chainId", "nonce", "gasPrice", "gas", "to", "value", "input", "accessList"},
+	DynamicFeeTxType: {"chainId", "nonce", "maxPriorityFeePerGas", "maxFeePerGas", "gas", "to", "value", "input", "accessList"},
+	BlobTxType: {
+		"chainId", "nonce", "maxPriorityFeePerGas", "maxFeePerGas", "gas", "to", "value", "input", "accessList",
+		"maxFeePerBlobGas", "blobVersionedHashes",
+	},
+	SetCodeTxType: {
+		"chainId", "nonce", "maxPriorityFeePerGas", "maxFeePerGas", "gas", "to", "value", "input", "accessList",
+		"authorizationList",
+	},
+}
+
+// The magic byte that authorizations of EIP-7702 are signed with.
+const authorizationMagic = 0x05
+
+// A transaction of any type. The fields its type does not have are nil or empty.
+type Tx struct {
+	Type uint8
+	// nil for legacy transactions signed without EIP-155 replay protection. The chain id of
+	// other legacy transactions is derived from V.
+	ChainID              *big.Int
+	Nonce                uint64
+	GasPrice             *big.Int
+	MaxPriorityFeePerGas *big.Int
+	MaxFeePerGas         *big.Int
+	Gas                  uint64
+	To                   *common.Address // nil for contract creations.
+	Value                *big.Int
+	Input                []byte
+	AccessList           []AccessTuple
+	MaxFeePerBlobGas     *big.Int
+	BlobVersionedHashes  []common.Hash
+	AuthorizationList    []Authorization
+	// The signature. V is the y parity of typed transactions, and 27 or 28, or
+	// chainId*2+35 or chainId*2+36, for legacy ones. nil when the transaction is unsigned.
+	V, R, S *big.Int
+}
+
+// An address and the storage slots of it that a transaction pre-warms.
+type AccessTuple struct {
+	Address     common.Address
+	StorageKeys []common.Hash
+}
+
+// An EIP-7702 authorization to set the code of its signer, the authority, to delegate to
+// Address.
+type Authorization struct {
+	ChainID *big.Int
+	Address common.Address
+	Nonce   uint64
+	YParity uint8
+	R, S    *big.Int
+}
+
+// Decodes the 0x-prefixed hex of a raw transaction: the RLP list of a legacy transaction,
+// or the type byte of a typed one followed by the RLP list of its fields. Blob transactions
+// may be in their network form, with the blobs, commitments and proofs, which are dropped.
+// Transactions without a signature, eg: the unsigned transactions Encode returns, decode with
+// a nil V, R and S. An unsigned legacy transaction may end with its chain id, 0 and 0, as
+// EIP-155 signs it, which decodes to its chain id.
+func Decode(hexInput string) (*Tx, error) {
+	raw, err := encdec.HexToBytes(hexInput)
+	if err != nil {
+		return nil, err
+	}
+	return DecodeBytes(raw)
+}
+
+// Decodes a raw transaction, as Decode does.
+func DecodeBytes(raw []byte) (*Tx, error) {
+	if len(raw) == 0 {
+		return nil, fmt.Errorf("%w: empty input", ErrInvalidTx)
+	}
+
+	tx := &Tx{}
+	payload := raw
+	if raw[0] < 0xc0 {
+		if raw[0] > 0x7f {
+			return nil, fmt.Errorf("%w: 0x%02x is neither a transaction type nor the start of an RLP list", ErrInvalidTx, raw[0])
+		}
+		tx.Type, payload = raw[0], raw[1:]
+		if _, ok := txFields[tx.Type]; !ok || tx.Type == LegacyTxType {
+			return nil, fmt.Errorf("%w: unknown transaction type 0x%02x", ErrInvalidTx, tx.Type)
+		}
+	}
+
+	item, err := rlp.DecodeBytes(payload)
+	if err != nil {
+		return nil, err
+	}
+	if !item.IsList {
+		return nil, fmt.Errorf("%w: the fields of a transaction must be an RLP list", ErrInvalidTx)
+	}
+	items := item.Items
+	if tx.Type == BlobTxType && len(items) == 4 && items[0].IsList {
+		// the network form: [tx_payload_body, blobs, commitments, proofs].
+		items = items[0].Items
+	}
+
+	fields := txFields[tx.Type]
+	if len(items) != len(fields) && len(items) != len(fields)+3 {
+		return nil, fmt.Errorf("%w: %s transactions have %d fields and a signature, got %d fields", ErrInvalidTx, typeNames[tx.Type], len(fields), len(items))
+	}
+	for idx, name := range fields {
+		if err := tx.decodeField(name, items[idx]); err != nil {
+			return nil, err
+		}
+	}
+	if len(items) == len(fields) {
+		return tx, nil
+	}
+
+	sig := items[len(fields):]
+	for idx, value := range []**big.Int{&tx.V, &tx.R, &tx.S} {
+		if *value, err = decodeBig(sig[idx], []string{"v", "r", "s"}[idx]); err != nil {
+			return nil, err
+		}
+	}
+	if tx.Type == LegacyTxType && tx.R.Sign() == 0 && tx.S.Sign() == 0 {
+		// an unsigned EIP-155 transaction: [..., chainId, 0, 0].
+		tx.ChainID, tx.V, tx.R, tx.S = tx.V, nil, nil, nil
+		return tx, nil
+	}
+	if tx.Type == LegacyTxType {
+		// EIP-155: v = chainId*2 + 35 + yParity.
+		if v := tx.V.Uint64(); tx.V.IsUint64() && v >= 35 {
+			tx.ChainID = new(big.Int).Sub(tx.V, big.NewInt(35))
+			tx.ChainID.Rsh(tx.ChainID, 1)
+		} else if !tx.V.IsUint64() || (v != 27 && v != 28) {
+			return nil, fmt.Errorf("%w: v of a legacy transaction must be 27, 28, or at least 35, got %s", ErrInvalidTxSignature, tx.V)
+		}
+	}
+	return tx, nil
+}
+
+// Decodes the RLP field `name` of the transaction.
+func (tx *Tx) decodeField(name string, item rlp.Item) error {
+	var err error
+	switch name {
+	case "chainId":
+		tx.ChainID, err = decodeBig(item, name)
+	case "nonce":
+		tx.Nonce, err = decodeUint64(item, name)
+	case "gasPrice":
+		tx.GasPrice, err = decodeBig(item, name)
+	case "maxPriorityFeePerGas":
+		tx.MaxPriorityFeePerGas, err = decodeBig(item, name)
+	case "maxFeePerGas":
+		tx.MaxFeePerGas, err = decodeBig(item, name)
+	case "gas":
+		tx.Gas, err = decodeUint64(item, name)
+	case "to":
+		if !item.IsList && len(item.Bytes) == 0 {
+			if tx.Type == BlobTxType || tx.Type == SetCodeTxType {
+				return fmt.Errorf("%w: %s transactions cannot create contracts, to must be an address", ErrInvalidTx, typeNames[tx.Type])
+			}
+			return nil
+		}
+		var to common.Address
+		to, err = decodeAddress(item, name)
+		tx.To = &to
+	case "value":
+		tx.Value, err = decodeBig(item, name)
+	case "input":
+		tx.Input, err = decodeString(item, name)
+	case "accessList":
+		tx.AccessList, err = decodeAccessList(item)
+	case "maxFeePerBlobGas":
+		tx.MaxFeePerBlobGas, err = decodeBig(item, name)
+	case "blobVersionedHashes":
+		tx.BlobVersionedHashes, err = decodeHashes(item, name)
+	case "authorizationList":
+		tx.AuthorizationList, err = decodeAuthorizations(item)
+	}
+	return err
+}
+
+// Returns the RLP field `name` of the transaction.
+func (tx *Tx) encodeField(name string) rlp.Item {
+	switch name {
+	case "chainId":
+		return bigItem(tx.ChainID)
+	case "nonce":
+		return uintItem(tx.Nonce)
+	case "gasPrice":
+		return bigItem(tx.GasPrice)
+	case "maxPriorityFeePerGas":
+		return bigItem(tx.MaxPriorityFeePerGas)
+	case "maxFeePerGas":
+		return bigItem(tx.MaxFeePerGas)
+	case "gas":
+		return uintItem(tx.Gas)
+	case "to":
+		if tx.To == nil {
+			return rlp.Item{}
+		}
+		return rlp.Item{Bytes: tx.To.Bytes()}
+	case "value":
+		return bigItem(tx.Value)
+	case "input":
+		return rlp.Item{Bytes: tx.Input}
+	case "accessList":
+		list := rlp.Item{IsList: true, Items: []rlp.Item{}}
+		for _, tuple := range tx.AccessList {
+			list.Items = append(list.Items, rlp.Item{IsList: true, Items: []rlp.Item{{Bytes: tuple.Address.Bytes()}, hashesItem(tuple.StorageKeys)}})
+		}
+		return list
+	case "maxFeePerBlobGas":
+		return bigItem(tx.MaxFeePerBlobGas)
+	case "blobVersionedHashes":
+		return hashesItem(tx.BlobVersionedHashes)
+	case "authorizationList":
+		list := rlp.Item{IsList: true, Items: []rlp.Item{}}
+		for _, auth := range tx.AuthorizationList {
+			item := auth.unsignedItem()
+			item.Items = append(item.Items, uintItem(uint64(auth.YParity)), bigItem(auth.R), bigItem(auth.S))
+			list.Items = append(list.Items, item)
+		}
+		return list
+	}
+	panic(fmt.Sprintf("unknown transaction field %q", name))
+}
+
+// Returns the RLP list of the fields of the transaction, with its signature when it is signed.
+func (tx *Tx) item(signed bool) rlp.Item {
+	list := rlp.Item{IsList: true}
+	for _, name := range txFields[tx.Type] {
+		list.Items = append(list.Items, tx.encodeField(name))
+	}
+	if signed {
+		list.Items = append(list.Items, bigItem(tx.V), bigItem(tx.R), bigItem(tx.S))
+	}
+	return list
+}
+
+// Returns the RLP list of the fields of the unsigned transaction, which legacy transactions
+// with a chain id end with the chain id, 0 and 0, as EIP-155 defines it.
+func (tx *Tx) unsignedItem() rlp.Item {
+	list := tx.item(false)
+	if tx.Type == LegacyTxType && tx.ChainID != nil {
+		list.Items = append(list.Items, bigItem(tx.ChainID), rlp.Item{}, rlp.Item{})
+	}
+	return list
+}
+
+// Returns the raw transaction: the RLP list of its fields, preceded by its type when it is a
+// typed transaction. It is signed when V, R and S are set. An unsigned legacy transaction
+// with a chain id is encoded as EIP-155 signs it, so that the chain id is not lost.
+func (tx *Tx) Encode() []byte {
+	list := tx.unsignedItem()
+	if tx.V != nil && tx.R != nil && tx.S != nil {
+		list = tx.item(true)
+	}
+	encoded := list.Encode()
+	if tx.Type == LegacyTxType {
+		return encoded
+	}
+	return append([]byte{tx.Type}, encoded...)
+}
+
+// Returns the hash of the raw transaction, which identifies it once it is signed.
+func (tx *Tx) Hash() common.Hash {
+	return crypto.Keccak256Hash(tx.Encode())
+}
+
+// Returns the hash that the sender signs: the keccak256 of the type and the RLP of the
+// unsigned fields, or for legacy transactions, of their RLP followed by the chain id, 0 and 0
+// as EIP-155 defines it.
+func (tx *Tx) SigningHash() common.Hash {
+	list := tx.unsignedItem()
+	if tx.Type == LegacyTxType {
+		return crypto.Keccak256Hash(list.Encode())
+	}
+	return crypto.Keccak256Hash([]byte{tx.Type}, list.Encode())
+}
+
+// Returns the calldata of the function call the transaction makes, which is its input. It
+// reports false if the transaction creates a contract, as its input is then init code.
+func (tx *Tx) Calldata() ([]byte, bool) {
+	if tx.To == nil {
+		return nil, false
+	}
+	return tx.Input, true
+}
+
+// Recovers the address that signed the transaction.
+func (tx *Tx) Sender() (common.Address, error) {
+	if tx.V == nil {
+		return common.Address{}, fmt.Errorf("%w: the transaction is not signed", ErrInvalidTxSignature)
+	}
+	yParity := tx.V
+	if tx.Type == LegacyTxType {
+		yParity = new(big.Int).Sub(tx.V, big.NewInt(27))
+		if tx.ChainID != nil {
+			yParity.Sub(tx.V, new(big.Int).Add(new(big.Int).Lsh(tx.ChainID, 1), big.NewInt(35)))
+		}
+	}
+	if !yParity.IsUint64() || yParity.Uint64() > 1 {
+		return common.Address{}, fmt.Errorf("%w: y parity must be 0 or 1, got %s", ErrInvalidTxSignature, yParity)
+	}
+	return recoverAddress(tx.SigningHash(), uint8(yParity.Uint64()), tx.R, tx.S)
+}
+
+// Returns the hash that the authority signs: the keccak256 of 0x05 and the RLP list of the
+// chain id, address and nonce.
+func (auth *Authorization) SigningHash() common.Hash {
+	return crypto.Keccak256Hash([]byte{authorizationMagic}, auth.unsignedItem().Encode())
+}
+
+// Recovers the address that signed the authorization, whose code it delegates.
+func (auth *Authorization) Authority() (common.Address, error) {
+	if auth.YParity > 1 {
+		return common.Address{}, fmt.Errorf("%w: y parity must be 0 or 1, got %d", ErrInvalidTxSignature, auth.YParity)
+	}
+	return recoverAddress(auth.SigningHash(), auth.YParity, auth.R, auth.S)
+}
+
+func (auth *Authorization) unsignedItem() rlp.Item {
+	return rlp.Item{IsList: true, Items: []rlp.Item{bigItem(auth.ChainID), {Bytes: auth.Address.Bytes()}, uintItem(auth.Nonce)}}
+}
+
+// Recovers the address of the key that signed `hash`. Signatures with an s in the upper
+// half of the curve order are rejected, as they are since the Homestead fork.
+func recoverAddress(hash common.Hash, yParity uint8, r, s *big.Int) (common.Address, error) {
+	if r == nil || s == nil || !crypto.ValidateSignatureValues(yParity, r, s, true) {
+		return common.Address{}, fmt.Errorf("%w: r and s must be between 1 and the curve order, and s at most half of it", ErrInvalidTxSignature)
+	}
+	sig := make([]byte, crypto.SignatureLength)
+	r.FillBytes(sig[:32])
+	s.FillBytes(sig[32:64])
+	sig[64] = yParity
+	pub, err := crypto.SigToPub(hash[:], sig)
+	if err != nil {
+		return common.Address{}, fmt.Errorf("%w: %v", ErrInvalidTxSignature, err)
+	}
+	return crypto.PubkeyToAddress(*pub), nil
+}
+
+// Formats the transaction as its type, hash and sender, followed by one `name: value` line
+// per field of its type and its signature. Access lists, blob hashes and authorizations
+// follow their field, one per line, eg:
+//
+//	type: 2 (EIP-1559)
+//	hash: 0x...
+//	from: 0x...
+//	chainId: 1
+//	...
+func (tx *Tx) String() string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "type: %d (%s)\n", tx.Type, typeNames[tx.Type])
+	fmt.Fprintf(&sb, "hash: %s\n", tx.Hash().Hex())
+	if sender, err := tx.Sender(); err != nil {
+		fmt.Fprintf(&sb, "from: unknown, %v\n", err)
+	} else {
+		fmt.Fprintf(&sb, "from: %s\n", sender.Hex())
+	}
+	if tx.Type == LegacyTxType && tx.ChainID != nil && tx.V == nil {
+		fmt.Fprintf(&sb, "chainId: %s (EIP-155)\n", tx.ChainID)
+	} else if tx.Type == LegacyTxType && tx.ChainID != nil {
+		fmt.Fprintf(&sb, "chainId: %s (from v, EIP-155)\n", tx.ChainID)
+	}
+
+	for _, name := range txFields[tx.Type] {
+		tx.formatField(&sb, name)
+	}
+
+	if tx.V != nil {
+		vName := "yParity"
+		if tx.Type == LegacyTxType {
+			vName = "v"
+		}
+		fmt.Fprintf(&sb, "%s: %s\nr: %s\ns: %s", vName, tx.V, hexutil.EncodeBig(tx.R), hexutil.EncodeBig(tx.S))
+	} else {
+		sb.WriteString("signature: none, the transaction is unsigned")
+	}
+	return sb.String()
+}
+
+func (tx *Tx) formatField(sb *strings.Builder, name string) {
+	switch name {
+	case "to":
+		if tx.To == nil {
+			fmt.Fprintf(sb, "to: none, the transaction creates a contract\n")
+		} else {
+			fmt.Fprintf(sb, "to: %s\n", tx.To.Hex())
+		}
+	case "input":
+		fmt.Fprintf(sb, "input: %s\n", hexutil.Encode(tx.Input))
+	case "accessList":
+		fmt.Fprintf(sb, "accessList: %s\n", count(len(tx.AccessList), "entry", "entries"))
+		for _, tuple := range tx.AccessList {
+			fmt.Fprintf(sb, "  %s\n", tuple.Address.Hex())
+			for _, key := range tuple.StorageKeys {
+				fmt.Fprintf(sb, "    %s\n", key.Hex())
+			}
+		}
+	case "blobVersionedHashes":
+		fmt.Fprintf(sb, "blobVersionedHashes: %s\n", count(len(tx.BlobVersionedHashes), "hash", "hashes"))
+		for _, hash := range tx.BlobVersionedHashes {
+			fmt.Fprintf(sb, "  %s\n", hash.Hex())
+		}
+	case "authorizationList":
+		fmt.Fprintf(sb, "authorizationList: %s\n", count(len(tx.AuthorizationList), "authorization", "authorizations"))
+		for idx, auth := range tx.AuthorizationList {
+			authority := "unknown"
+			if address, err := auth.Authority(); err != nil {
+				authority += ", " + err.Error()
+			} else {
+				authority = address.Hex()
+			}
+			fmt.Fprintf(sb, "  [%d] address: %s\n      chainId: %s\n      nonce: %d\n      authority: %s\n      yParity: %d\n      r: %s\n      s: %s\n",
+				idx, auth.Address.Hex(), auth.ChainID, auth.Nonce, authority, auth.YParity, hexutil.EncodeBig(auth.R), hexutil.EncodeBig(auth.S))
+		}
+	default:
+		// the remaining fields are integers, which decode to at most 32 bytes.
+		value, _ := tx.encodeField(name).Format(rlp.AsInt)
+		fmt.Fprintf(sb, "%s: %s\n", name, value)
+	}
+}
+
+func count(n int, singular string, plural string) string {
+	if n == 1 {
+		return "1 " + singular
+	}
+	return fmt.Sprintf("%d %s", n, plural)
+}
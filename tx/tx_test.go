@@ -0,0 +1,264 @@
+package tx
+
+import (
+	"errors"
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/zeuslawyer/hextool/encdec"
+	"github.com/zeuslawyer/hextool/rlp"
+)
+
+// The key that signed the test transactions, and its address.
+const (
+	testKey    = "4c0883a69102937d6231471b5dbb6204fe5129617082792ae468d01a3f362318"
+	testSender = "0x2c7536E3605D9C16a7a3D7b1898e529396a65c23"
+)
+
+// Transactions signed by go-ethereum's core/types, and their hashes.
+func TestDecode(t *testing.T) {
+	to := common.HexToAddress("0x208AA722Aca42399eaC5192EE778e4D42f4E5De3")
+	transfer := common.FromHex("0xa9059cbb000000000000000000000000208aa722aca42399eac5192ee778e4d42f4e5de300000000000000000000000000000000000000000000000000000000000003e8")
+	accessList := []AccessTuple{{Address: to, StorageKeys: []common.Hash{common.HexToHash("0x01"), common.HexToHash("0x02")}}}
+
+	tests := []struct {
+		name  string
+		raw   string
+		hash  string
+		check func(t *testing.T, tx *Tx)
+	}{
+		{
+			name: "legacy, EIP-155",
+			raw:  "0xf86c078504a817c80082520894208aa722aca42399eac5192ee778e4d42f4e5de3880de0b6b3a76400008026a0b17c1bafce3ab8327330d7cf73a3f248c24a2f41fa6fa61daa1c2c18664f7f7da05c90f7e21032a4bdceed6a6b81066714971b1e3bbd6e7ab7a33229a7d3bf878c",
+			hash: "0x96ca73be7a92ce221709636c520e0ff52aabdc39e2647ffec096cec360987392",
+			check: func(t *testing.T, tx *Tx) {
+				if tx.ChainID.Int64() != 1 || tx.V.Int64() != 38 || tx.Nonce != 7 || tx.GasPrice.Int64() != 20e9 || *tx.To != to || tx.Value.String() != "1000000000000000000" {
+					t.Errorf("fields = %+v", tx)
+				}
+			},
+		},
+		{
+			name: "legacy contract creation, without EIP-155",
+			raw:  "0xf85280843b9aca00830186a080808260801ca09531448ac68bd15aa29a5e78fe1aafc174eadaa4829a0167a54b4b0485431646a02850530b08b44255d7e6dab159a823be1d7b868625ea28cde22f5ba3d2fbd439",
+			hash: "0x665d444467140717746a075339dc19d16432440048a7572be15b0df0e174bde2",
+			check: func(t *testing.T, tx *Tx) {
+				if tx.ChainID != nil || tx.To != nil || tx.V.Int64() != 28 || common.Bytes2Hex(tx.Input) != "6080" {
+					t.Errorf("fields = %+v", tx)
+				}
+			},
+		},
+		{
+			name: "EIP-2930",
+			raw:  "0x01f9010701018506fc23ac0082ea6094208aa722aca42399eac5192ee778e4d42f4e5de380b844a9059cbb000000000000000000000000208aa722aca42399eac5192ee778e4d42f4e5de300000000000000000000000000000000000000000000000000000000000003e8f85bf85994208aa722aca42399eac5192ee778e4d42f4e5de3f842a00000000000000000000000000000000000000000000000000000000000000001a0000000000000000000000000000000000000000000000000000000000000000280a057f4c3a6251ebf8a7a92bbf15daef6768f11bd9a401e51f2fd0156cc0af89623a0655e73f43ab6363322360a65c52577eb40949d421087eee19cda6e941129711a",
+			hash: "0xa2b7585fd3ae56d8ec6aec528b4c1d3150a32eea4a1bf83f3489d3bca0cc98b6",
+			check: func(t *testing.T, tx *Tx) {
+				if tx.Type != AccessListTxType || tx.GasPrice.Int64() != 30e9 || tx.Gas != 60000 || !equalAccessLists(tx.AccessList, accessList) || string(tx.Input) != string(transfer) {
+					t.Errorf("fields = %+v", tx)
+				}
+			},
+		},
+		{
+			name: "EIP-1559",
+			raw:  "0x02f9010c010284773594008509502f900082ea6094208aa722aca42399eac5192ee778e4d42f4e5de380b844a9059cbb000000000000000000000000208aa722aca42399eac5192ee778e4d42f4e5de300000000000000000000000000000000000000000000000000000000000003e8f85bf85994208aa722aca42399eac5192ee778e4d42f4e5de3f842a00000000000000000000000000000000000000000000000000000000000000001a0000000000000000000000000000000000000000000000000000000000000000280a0e774517a7e1c446edde6022a08f8bc10ef600cec4c173500d0ef1332aa59be9da0298d83840907f4749025a0339c890d1852b434780646430126fc14650b179740",
+			hash: "0xaa3f3244667def6fb413b6dbcf4bd322bf42fd85dc0135861b6fe2c03f3b1fd0",
+			check: func(t *testing.T, tx *Tx) {
+				if tx.Type != DynamicFeeTxType || tx.MaxPriorityFeePerGas.Int64() != 2e9 || tx.MaxFeePerGas.Int64() != 40e9 || tx.GasPrice != nil || !equalAccessLists(tx.AccessList, accessList) {
+					t.Errorf("fields = %+v", tx)
+				}
+			},
+		},
+		{
+			name: "EIP-4844",
+			raw:  "0x03f8920103843b9aca00850ba43b740082520894208aa722aca42399eac5192ee778e4d42f4e5de38080c084b2d05e00e1a001a915e4d060149eb4365960e6a7a45f334393093061116b197e3240065ff2d880a0f5f1293c3fb5ae90fca3b745e1b78fa0ba08019958d4577a73229fc64e439ea6a027ef6e3d073c315cdf49eb65ad707bb26ea97c68aa0a4083076cca646e4e8741",
+			hash: "0x4016f45f4d5b4a3a837712f094c15626d1b5a738837c0b79dd68c92ba0819a98",
+			check: func(t *testing.T, tx *Tx) {
+				wantHashes := []common.Hash{common.HexToHash("0x01a915e4d060149eb4365960e6a7a45f334393093061116b197e3240065ff2d8")}
+				if tx.Type != BlobTxType || tx.MaxFeePerBlobGas.Int64() != 3e9 || len(tx.BlobVersionedHashes) != 1 || tx.BlobVersionedHashes[0] != wantHashes[0] {
+					t.Errorf("fields = %+v", tx)
+				}
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			tx, err := Decode(tc.raw)
+			if err != nil {
+				t.Fatal(err)
+			}
+			tc.check(t, tx)
+			if got := tx.Hash().Hex(); got != tc.hash {
+				t.Errorf("Hash() = %s, want %s", got, tc.hash)
+			}
+			if sender, err := tx.Sender(); err != nil || sender.Hex() != testSender {
+				t.Errorf("Sender() = %s, %v, want %s", sender.Hex(), err, testSender)
+			}
+			// the input of a contract creation is init code, not calldata.
+			if calldata, isCall := tx.Calldata(); isCall != (tx.To != nil) || (isCall && string(calldata) != string(tx.Input)) {
+				t.Errorf("Calldata() = %x, %t, want the input only for calls", calldata, isCall)
+			}
+		})
+	}
+}
+
+// go-ethereum v1.13 has no EIP-7702 transactions, so this one is signed with Tx itself, and
+// decoded back.
+func TestSetCodeTx(t *testing.T) {
+	key, _ := crypto.HexToECDSA(testKey)
+	authKey, _ := crypto.GenerateKey()
+	to := common.HexToAddress("0x63c0c19a282a1B52b07dD5a65b58948A07DAE32B")
+
+	auth := Authorization{ChainID: big.NewInt(1), Address: common.HexToAddress("0x000000009B1D0aF20D8C6d0A44e162d11F9b8f00"), Nonce: 4}
+	hash := auth.SigningHash()
+	wantHash := crypto.Keccak256Hash(common.FromHex(encodeJSON(t, authorizationMagic, `[1, "0x000000009B1D0aF20D8C6d0A44e162d11F9b8f00", 4]`)))
+	if hash != wantHash {
+		t.Errorf("Authorization.SigningHash() = %s, want %s", hash, wantHash)
+	}
+	sig, _ := crypto.Sign(hash[:], authKey)
+	auth.R, auth.S, auth.YParity = new(big.Int).SetBytes(sig[:32]), new(big.Int).SetBytes(sig[32:64]), sig[64]
+
+	signed := &Tx{
+		Type: SetCodeTxType, ChainID: big.NewInt(1), Nonce: 9, MaxPriorityFeePerGas: big.NewInt(1e9), MaxFeePerGas: big.NewInt(30e9),
+		Gas: 80000, To: &to, Value: big.NewInt(0), AuthorizationList: []Authorization{auth},
+	}
+	hash = signed.SigningHash()
+	sig, _ = crypto.Sign(hash[:], key)
+	signed.V, signed.R, signed.S = big.NewInt(int64(sig[64])), new(big.Int).SetBytes(sig[:32]), new(big.Int).SetBytes(sig[32:64])
+
+	tx, err := DecodeBytes(signed.Encode())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tx.Hash() != signed.Hash() || len(tx.AuthorizationList) != 1 || *tx.To != to {
+		t.Errorf("decoded %+v, want %+v", tx, signed)
+	}
+	if sender, err := tx.Sender(); err != nil || sender.Hex() != testSender {
+		t.Errorf("Sender() = %s, %v, want %s", sender.Hex(), err, testSender)
+	}
+	authority, err := tx.AuthorizationList[0].Authority()
+	if want := crypto.PubkeyToAddress(authKey.PublicKey); err != nil || authority != want {
+		t.Errorf("Authority() = %s, %v, want %s", authority, err, want)
+	}
+	if !strings.Contains(tx.String(), "authority: "+authority.Hex()) {
+		t.Errorf("String() does not show the authority:\n%s", tx)
+	}
+
+	// unsigned transactions decode without a signature, and have no sender.
+	signed.V, signed.R, signed.S = nil, nil, nil
+	unsigned, err := DecodeBytes(signed.Encode())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := unsigned.Sender(); !errors.Is(err, ErrInvalidTxSignature) {
+		t.Errorf("Sender() of an unsigned transaction: error = %v, want %v", err, ErrInvalidTxSignature)
+	}
+	if unsigned.SigningHash() != hash {
+		t.Errorf("SigningHash() = %s, want %s", unsigned.SigningHash(), hash)
+	}
+}
+
+// The network form of blob transactions wraps the transaction with its blobs, commitments
+// and proofs, which do not change its hash.
+func TestDecodeBlobTxNetworkForm(t *testing.T) {
+	tx, err := Decode("0x03f8920103843b9aca00850ba43b740082520894208aa722aca42399eac5192ee778e4d42f4e5de38080c084b2d05e00e1a001a915e4d060149eb4365960e6a7a45f334393093061116b197e3240065ff2d880a0f5f1293c3fb5ae90fca3b745e1b78fa0ba08019958d4577a73229fc64e439ea6a027ef6e3d073c315cdf49eb65ad707bb26ea97c68aa0a4083076cca646e4e8741")
+	if err != nil {
+		t.Fatal(err)
+	}
+	wrapped := rlp.Item{IsList: true, Items: []rlp.Item{tx.item(true), {IsList: true}, {IsList: true}, {IsList: true}}}
+	decoded, err := DecodeBytes(append([]byte{BlobTxType}, wrapped.Encode()...))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if decoded.Hash() != tx.Hash() {
+		t.Errorf("Hash() = %s, want %s", decoded.Hash(), tx.Hash())
+	}
+}
+
+func TestDecodeErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		raw     string
+		wantErr error
+		wantMsg string
+	}{
+		{name: "empty", raw: "0x", wantErr: ErrInvalidTx, wantMsg: "empty input"},
+		{name: "invalid hex", raw: "0x02zz", wantErr: encdec.ErrInvalidHex},
+		{name: "unknown type", raw: "0x05c0", wantErr: ErrInvalidTx, wantMsg: "unknown transaction type 0x05"},
+		{name: "not a type or list", raw: "0x80", wantErr: ErrInvalidTx, wantMsg: "neither a transaction type nor the start of an RLP list"},
+		{name: "wrong number of fields", raw: "0x02c3010203", wantErr: ErrInvalidTx, wantMsg: "EIP-1559 transactions have 9 fields and a signature, got 3 fields"},
+		{name: "malformed RLP", raw: "0x02c5010203", wantErr: rlp.ErrInvalidRLP},
+		{
+			name:    "blob transaction creating a contract",
+			raw:     encodeJSON(t, BlobTxType, `[1, 1, 1, 1, 1, "", 0, "", [], 1, [], 1, 1, 1]`),
+			wantErr: ErrInvalidTx,
+			wantMsg: "EIP-4844 transactions cannot create contracts",
+		},
+		{
+			name:    "integer with leading zeros",
+			raw:     encodeJSON(t, AccessListTxType, `[1, "0x0001", 1, 1, "", 0, "", [], 1, 1, 1]`),
+			wantErr: ErrInvalidTx,
+			wantMsg: "nonce has leading zero bytes",
+		},
+		{
+			name:    "short address",
+			raw:     encodeJSON(t, DynamicFeeTxType, `[1, 1, 1, 1, 1, "0x0102", 0, "", [], 1, 1, 1]`),
+			wantErr: ErrInvalidTx,
+			wantMsg: "to is 2 bytes long",
+		},
+		{
+			name:    "legacy with an invalid v",
+			raw:     encodeJSON(t, LegacyTxType, `[1, 1, 1, "", 0, "", 29, 1, 1]`),
+			wantErr: ErrInvalidTxSignature,
+			wantMsg: "must be 27, 28, or at least 35, got 29",
+		},
+		{
+			name:    "authorization with an invalid y parity",
+			raw:     encodeJSON(t, SetCodeTxType, `[1, 1, 1, 1, 1, "0x63c0c19a282a1B52b07dD5a65b58948A07DAE32B", 0, "", [], [[1, "0x63c0c19a282a1B52b07dD5a65b58948A07DAE32B", 0, 2, 1, 1]], 1, 1, 1]`),
+			wantErr: ErrInvalidTxSignature,
+			wantMsg: "authorizationList[0].yParity must be 0 or 1, got 2",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := Decode(tc.raw)
+			if !errors.Is(err, tc.wantErr) || !strings.Contains(err.Error(), tc.wantMsg) {
+				t.Errorf("Decode(%s) error = %v, want %v containing %q", tc.raw, err, tc.wantErr, tc.wantMsg)
+			}
+		})
+	}
+}
+
+// Returns the hex of `typ` followed by the RLP of the JSON `fields`, see rlp.ParseJSON, or of
+// the fields alone for legacy transactions.
+func encodeJSON(t *testing.T, typ uint8, fields string) string {
+	item, err := rlp.ParseJSON(fields)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if typ == LegacyTxType {
+		return hexutil.Encode(item.Encode())
+	}
+	return hexutil.Encode(append([]byte{typ}, item.Encode()...))
+}
+
+func equalAccessLists(a, b []AccessTuple) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for idx := range a {
+		if a[idx].Address != b[idx].Address || len(a[idx].StorageKeys) != len(b[idx].StorageKeys) {
+			return false
+		}
+		for keyIdx := range a[idx].StorageKeys {
+			if a[idx].StorageKeys[keyIdx] != b[idx].StorageKeys[keyIdx] {
+				return false
+			}
+		}
+	}
+	return true
+}